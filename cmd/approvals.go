@@ -0,0 +1,225 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"crypto-wallet/internal/addressbook"
+	"crypto-wallet/internal/approvals"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func scanApprovals(w *wallet.Wallet, owners []common.Address) ([]approvals.Approval, error) {
+	cache, err := approvals.LoadCache(w.SidecarPath("approvals.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	to := uint64(*indexToBlock)
+	if *indexToBlock < 0 {
+		if to, err = w.Blockchain.GetBlockNumber(); err != nil {
+			return nil, err
+		}
+	}
+
+	var from uint64
+	if *watchFromBlock > 0 {
+		from = uint64(*watchFromBlock)
+	}
+
+	scanner := &approvals.Scanner{Client: w.Blockchain, Cache: cache}
+	for _, owner := range owners {
+		err := scanner.Scan(owner, from, to, func(block uint64) {
+			fmt.Printf("\rScanning approvals of %s: block %d of %d", owner.Hex(), block, to)
+		})
+		if saveErr := cache.Save(); saveErr != nil {
+			fmt.Printf("\nWarning: %v\n", saveErr)
+		}
+		if err != nil {
+			fmt.Println()
+			return nil, err
+		}
+	}
+	fmt.Print("\r\033[K")
+
+	return scanner.Check(cache.For(owners))
+}
+
+type approvalFormatter struct {
+	w      *wallet.Wallet
+	book   *addressbook.Book
+	ens    *ensNames
+	tokens map[common.Address]*tokenInfo
+}
+
+func newApprovalFormatter(w *wallet.Wallet) (*approvalFormatter, error) {
+	book, err := loadAddressBook(w)
+	if err != nil {
+		return nil, err
+	}
+
+	return &approvalFormatter{w: w, book: book, ens: newENSNames(w), tokens: make(map[common.Address]*tokenInfo)}, nil
+}
+
+func (f *approvalFormatter) spender(address common.Address) string {
+	if entry := f.book.LookupAddress(address); entry != nil {
+		return fmt.Sprintf("%s (%s)", address.Hex(), entry.Name)
+	}
+	if label, ok := approvals.KnownSpenders[address]; ok {
+		return fmt.Sprintf("%s (%s)", address.Hex(), label)
+	}
+	return f.ens.format(address)
+}
+
+func (f *approvalFormatter) amount(approval approvals.Approval) string {
+	if approval.Unknown() {
+		return "unknown on " + approval.Token.Hex()
+	}
+	if approval.Kind == approvals.KindOperator {
+		return "all NFTs of " + approval.Token.Hex()
+	}
+
+	info := lookupToken(f.w, f.tokens, approval.Token)
+	if approval.Unlimited() {
+		return "unlimited " + info.symbol
+	}
+	return info.format(approval.Allowance)
+}
+
+func (f *approvalFormatter) print(list []approvals.Approval, showOwner bool) {
+	for i, approval := range list {
+		fmt.Printf("%3d. ", i+1)
+		if showOwner {
+			fmt.Printf("%s  ", approval.Owner.Hex())
+		}
+		fmt.Printf("%-40s -> %s\n", f.amount(approval), f.spender(approval.Spender))
+		if approval.Unknown() {
+			fmt.Printf("     %v\n", approval.Err)
+		}
+	}
+}
+
+func handleApprovals(w *wallet.Wallet) error {
+	owners, err := historyAddresses(w)
+	if err != nil {
+		return err
+	}
+
+	list, err := scanApprovals(w, owners)
+	if err != nil {
+		return err
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No active approvals found")
+		return nil
+	}
+
+	formatter, err := newApprovalFormatter(w)
+	if err != nil {
+		return err
+	}
+
+	unlimited, unknown := 0, 0
+	for _, approval := range list {
+		if approval.Unlimited() {
+			unlimited++
+		}
+		if approval.Unknown() {
+			unknown++
+		}
+	}
+
+	fmt.Printf("Active approvals (%d, %d unlimited):\n", len(list)-unknown, unlimited)
+	if unknown > 0 {
+		fmt.Printf("%d approvals could not be checked and are listed as unknown.\n", unknown)
+	}
+	formatter.print(list, len(owners) > 1)
+	fmt.Println("\nRevoke with: ./crypto-wallet revoke <all|spender|token>...")
+
+	return nil
+}
+
+func handleRevoke(w *wallet.Wallet) error {
+	if flag.NArg() < 1 {
+		return fmt.Errorf("usage: revoke <all|spender|token>...")
+	}
+
+	err := w.LoadWallet()
+	if err != nil {
+		return fmt.Errorf("error loading wallet: %w", err)
+	}
+
+	formatter, err := newApprovalFormatter(w)
+	if err != nil {
+		return err
+	}
+
+	selected := make(map[common.Address]bool)
+	all := false
+	for _, arg := range flag.Args() {
+		if strings.EqualFold(arg, "all") {
+			all = true
+			continue
+		}
+		address, _, err := resolveAddress(w, formatter.book, arg, nil)
+		if err != nil {
+			return err
+		}
+		selected[address] = true
+	}
+
+	list, err := scanApprovals(w, []common.Address{w.KeyPair.Address})
+	if err != nil {
+		return err
+	}
+
+	var revoke []approvals.Approval
+	for _, approval := range list {
+		if !all && !selected[approval.Spender] && !selected[approval.Token] {
+			continue
+		}
+		if approval.Unknown() {
+			fmt.Printf("Skipping %s -> %s: %v\n", approval.Token.Hex(), approval.Spender.Hex(), approval.Err)
+			continue
+		}
+		revoke = append(revoke, approval)
+	}
+
+	if len(revoke) == 0 {
+		fmt.Println("No matching active approvals")
+		return nil
+	}
+
+	fmt.Printf("Revoking %d approvals:\n", len(revoke))
+	formatter.print(revoke, false)
+	if !*assumeYes && !confirm(fmt.Sprintf("Send %d revoke transactions?", len(revoke))) {
+		return fmt.Errorf("revoke cancelled")
+	}
+
+	failed := 0
+	for i, approval := range revoke {
+		data, err := approvals.PackRevoke(approval)
+		if err != nil {
+			return err
+		}
+
+		txHash, err := w.Send(wallet.TxRequest{To: approval.Token, Data: data})
+		if err != nil {
+			failed++
+			fmt.Printf("%3d. failed: %v\n", i+1, err)
+			continue
+		}
+		fmt.Printf("%3d. sent: %s\n", i+1, txHash)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d revoke transactions failed", failed, len(revoke))
+	}
+
+	fmt.Println("All revoke transactions sent. Run approvals again once they are mined.")
+	return nil
+}
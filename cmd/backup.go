@@ -0,0 +1,228 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"crypto-wallet/internal/backup"
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/slip39"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethereumCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	backupThreshold = flag.Int("threshold", 3, "backup split: number of shares needed to restore")
+	backupShares    = flag.Int("shares", 5, "backup split: number of shares to create")
+	backupMnemonic  = flag.Bool("mnemonic", false, "backup split/combine: the shares hold a BIP-39 mnemonic instead of the wallet key")
+	backupWords     = flag.Bool("words", false, "backup split/combine: use SLIP-39 word shares")
+)
+
+func handleBackup(w *wallet.Wallet) error {
+	if flag.NArg() < 1 {
+		return fmt.Errorf("usage: backup split|combine")
+	}
+
+	switch flag.Arg(0) {
+	case "split":
+		return handleBackupSplit(w)
+	case "combine":
+		return handleBackupCombine(w)
+	default:
+		return fmt.Errorf("unknown backup command: %s", flag.Arg(0))
+	}
+}
+
+func handleBackupSplit(w *wallet.Wallet) error {
+	var (
+		kind    string
+		secret  []byte
+		address common.Address
+	)
+
+	if *backupMnemonic {
+		mnemonic, err := readSecret("Mnemonic: ")
+		if err != nil {
+			return err
+		}
+
+		keyPair, err := crypto.KeyPairFromMnemonic(mnemonic, "", crypto.DefaultDerivationPath)
+		if err != nil {
+			return err
+		}
+
+		if secret, err = crypto.MnemonicToEntropy(mnemonic); err != nil {
+			return err
+		}
+		kind, address = backup.KindMnemonic, keyPair.Address
+		keyPair.Destroy()
+	} else {
+		err := w.LoadWallet()
+		if err != nil {
+			return fmt.Errorf("error loading wallet: %w", err)
+		}
+
+		kind, address = backup.KindKey, w.KeyPair.Address
+		if secret, err = w.KeyPair.PrivateKeyBytes(); err != nil {
+			return err
+		}
+	}
+
+	var lines []string
+	if *backupWords {
+		shares, err := slip39.Split(secret, nil, *backupThreshold, *backupShares, slip39.DefaultIterationExponent)
+		crypto.Wipe(secret)
+		if err != nil {
+			return fmt.Errorf("error splitting %s: %w", kind, err)
+		}
+		for _, share := range shares {
+			lines = append(lines, share.Mnemonic())
+		}
+	} else {
+		shares, err := backup.Split(kind, secret, address, *backupThreshold, *backupShares)
+		crypto.Wipe(secret)
+		if err != nil {
+			return fmt.Errorf("error splitting %s: %w", kind, err)
+		}
+		for _, share := range shares {
+			lines = append(lines, share.String())
+		}
+	}
+
+	fmt.Printf("Split the %s of %s into %d shares, any %d of which restore it:\n\n",
+		kind, address.Hex(), len(lines), *backupThreshold)
+	for i, line := range lines {
+		fmt.Printf("Share %d: %s\n", i+1, line)
+	}
+	if *backupWords {
+		fmt.Printf("\nSLIP-39 shares do not record the address. Keep %s with them to check a restore.\n", address.Hex())
+		if kind == backup.KindMnemonic {
+			fmt.Println("Restore with: ./crypto-wallet backup combine -words -mnemonic")
+		}
+	}
+	fmt.Println("\nStore every share in a different place. Fewer than the threshold reveal nothing about the key.")
+
+	return nil
+}
+
+func handleBackupCombine(w *wallet.Wallet) error {
+	var texts []string
+	if flag.NArg() > 1 {
+		texts = flag.Args()[1:]
+	} else {
+		fmt.Println("Enter shares, one per line, empty line to finish:")
+		for {
+			line, err := readSecret("Share: ")
+			if err != nil || line == "" {
+				break
+			}
+			texts = append(texts, line)
+		}
+	}
+
+	if *backupWords {
+		return combineWordShares(w, texts)
+	}
+
+	shares := make([]backup.Share, 0, len(texts))
+	for i, text := range texts {
+		share, err := backup.ParseShare(text)
+		if err != nil {
+			return fmt.Errorf("share %d: %w", i+1, err)
+		}
+		shares = append(shares, share)
+	}
+
+	secret, err := backup.Combine(shares)
+	if err != nil {
+		return fmt.Errorf("error combining shares: %w", err)
+	}
+
+	expected := shares[0].Address
+
+	var keyPair *crypto.KeyPair
+	switch shares[0].Kind {
+	case backup.KindMnemonic:
+		mnemonic, err := crypto.EntropyToMnemonic(secret)
+		if err != nil {
+			return err
+		}
+		if keyPair, err = crypto.KeyPairFromMnemonic(mnemonic, "", crypto.DefaultDerivationPath); err != nil {
+			return err
+		}
+		if keyPair.Address == expected {
+			fmt.Printf("Recovered mnemonic: %s\n", mnemonic)
+		}
+	default:
+		privateKey, err := ethereumCrypto.ToECDSA(secret)
+		if err != nil {
+			return fmt.Errorf("recovered key is invalid: %w", err)
+		}
+		keyPair = crypto.KeyPairFromPrivateKey(privateKey)
+	}
+
+	if keyPair.Address != expected {
+		return fmt.Errorf("recovered key belongs to %s, expected %s; some shares are wrong", keyPair.Address.Hex(), expected.Hex())
+	}
+	fmt.Printf("Recovered key verified for %s\n", expected.Hex())
+
+	written, err := installKey(w, keyPair)
+	if err != nil || !written {
+		return err
+	}
+
+	fmt.Printf("Wallet restored to %s\n", w.WalletFile)
+	return nil
+}
+
+func combineWordShares(w *wallet.Wallet, texts []string) error {
+	shares := make([]slip39.Share, 0, len(texts))
+	for i, text := range texts {
+		share, err := slip39.ParseMnemonic(text)
+		if err != nil {
+			return fmt.Errorf("share %d: %w", i+1, err)
+		}
+		shares = append(shares, share)
+	}
+
+	secret, err := slip39.Combine(shares, nil)
+	if err != nil {
+		return fmt.Errorf("error combining shares: %w", err)
+	}
+	defer crypto.Wipe(secret)
+
+	var keyPair *crypto.KeyPair
+	if *backupMnemonic {
+		mnemonic, err := crypto.EntropyToMnemonic(secret)
+		if err != nil {
+			return err
+		}
+		if keyPair, err = crypto.KeyPairFromMnemonic(mnemonic, "", crypto.DefaultDerivationPath); err != nil {
+			return err
+		}
+		fmt.Printf("Recovered mnemonic: %s\n", mnemonic)
+	} else {
+		privateKey, err := ethereumCrypto.ToECDSA(secret)
+		if err != nil {
+			return fmt.Errorf("recovered key is invalid (pass -mnemonic for mnemonic shares): %w", err)
+		}
+		keyPair = crypto.KeyPairFromPrivateKey(privateKey)
+	}
+
+	fmt.Printf("Recovered key for %s\n", keyPair.Address.Hex())
+	fmt.Println("SLIP-39 shares do not record the address, compare it with the one noted at split time.")
+	if !*assumeYes && !confirm("Is this the right address?") {
+		keyPair.Destroy()
+		return fmt.Errorf("restore cancelled")
+	}
+
+	written, err := installKey(w, keyPair)
+	if err != nil || !written {
+		return err
+	}
+
+	fmt.Printf("Wallet restored to %s\n", w.WalletFile)
+	return nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-wallet/internal/addressbook"
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	bookNotes  = flag.String("notes", "", "book add: notes for the entry")
+	bookChains = flag.String("chains", "", "book add: comma-separated chain IDs the entry may be used on")
+)
+
+func loadAddressBook(w *wallet.Wallet) (*addressbook.Book, error) {
+	return addressbook.Load(w.SidecarPath("addressbook.json"))
+}
+
+func handleBook(w *wallet.Wallet) error {
+	if flag.NArg() < 1 {
+		return fmt.Errorf("usage: book <list|add|remove> ...")
+	}
+
+	book, err := loadAddressBook(w)
+	if err != nil {
+		return err
+	}
+
+	switch flag.Arg(0) {
+	case "list":
+		if len(book.Entries) == 0 {
+			fmt.Println("Address book is empty")
+			return nil
+		}
+		for _, entry := range book.Entries {
+			fmt.Printf("%-16s %s", entry.Name, entry.Address.Hex())
+			if len(entry.ChainIDs) > 0 {
+				fmt.Printf("  chains: %s", formatChainIDs(entry.ChainIDs))
+			}
+			if entry.Notes != "" {
+				fmt.Printf("  %s", entry.Notes)
+			}
+			fmt.Println()
+		}
+		return nil
+
+	case "add":
+		if flag.NArg() < 3 {
+			return fmt.Errorf("usage: book add [-notes <text>] [-chains <id,...>] <nickname> <address>")
+		}
+
+		address, err := crypto.HexToAddress(flag.Arg(2))
+		if err != nil {
+			return err
+		}
+
+		chainIDs, err := parseChainIDs(*bookChains)
+		if err != nil {
+			return err
+		}
+
+		entry := addressbook.Entry{Name: flag.Arg(1), Address: address, Notes: *bookNotes, ChainIDs: chainIDs}
+		if err := book.Add(entry); err != nil {
+			return err
+		}
+		if err := book.Save(); err != nil {
+			return err
+		}
+
+		fmt.Printf("Added %s: %s\n", entry.Name, address.Hex())
+		return nil
+
+	case "remove":
+		if flag.NArg() < 2 {
+			return fmt.Errorf("usage: book remove <nickname>")
+		}
+
+		if err := book.Remove(flag.Arg(1)); err != nil {
+			return err
+		}
+		if err := book.Save(); err != nil {
+			return err
+		}
+
+		fmt.Printf("Removed %s\n", flag.Arg(1))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown book command: %s", flag.Arg(0))
+	}
+}
+
+func parseChainIDs(value string) ([]uint64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var ids []uint64
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain ID: %s", part)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func formatChainIDs(ids []uint64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatUint(id, 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+func confirmRecipient(book *addressbook.Book, address common.Address) error {
+	if book.Known(address) {
+		return nil
+	}
+
+	fmt.Printf("Warning: you have never sent to %s before\n", address.Hex())
+	if !*assumeYes && !confirm("Send to this new address?") {
+		return fmt.Errorf("send cancelled")
+	}
+	return nil
+}
+
+func recordRecipient(book *addressbook.Book, address common.Address) {
+	book.MarkUsed(address, time.Now())
+	if err := book.Save(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
+func resolveAddress(w *wallet.Wallet, book *addressbook.Book, input string, chainID *big.Int) (common.Address, string, error) {
+	if book.Lookup(input) == nil && blockchain.IsENSName(input) {
+		address, err := w.Blockchain.ResolveENS(input)
+		if err != nil {
+			return common.Address{}, "", err
+		}
+		return address, fmt.Sprintf("%s (%s)", blockchain.NormalizeENSName(input), address.Hex()), nil
+	}
+
+	address, entry, err := book.Resolve(input, chainID)
+	if err != nil {
+		return common.Address{}, "", err
+	}
+
+	if entry != nil {
+		return address, fmt.Sprintf("%s (%s)", entry.Name, address.Hex()), nil
+	}
+	return address, address.Hex(), nil
+}
+
+func resolveBookAddress(w *wallet.Wallet, input string, chainID *big.Int) (common.Address, error) {
+	book, err := loadAddressBook(w)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	address, _, err := resolveAddress(w, book, input, chainID)
+	return address, err
+}
+
+type ensNames struct {
+	client   *blockchain.Client
+	names    map[common.Address]string
+	disabled bool
+}
+
+func newENSNames(w *wallet.Wallet) *ensNames {
+	return &ensNames{client: w.Blockchain, names: make(map[common.Address]string)}
+}
+
+func (n *ensNames) format(address common.Address) string {
+	name, ok := n.names[address]
+	if !ok && !n.disabled {
+		var err error
+		name, err = n.client.LookupENS(address)
+		if err != nil {
+			n.disabled = true
+		}
+		n.names[address] = name
+	}
+
+	if name == "" {
+		return address.Hex()
+	}
+	return fmt.Sprintf("%s (%s)", address.Hex(), name)
+}
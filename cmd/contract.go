@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	contractValue  = flag.String("value", "0", "contract send: ETH to send with the call")
+	accessListMode = flag.String("access-list", "auto", "contract send: EIP-2930 access list: auto, on or off")
+)
+
+func handleContract(w *wallet.Wallet) error {
+	if flag.NArg() < 3 || flag.Arg(0) != "send" {
+		return fmt.Errorf("usage: contract send [-abi <file>] [-value <eth>] [-access-list auto|on|off] <contract> <method|0xdata> [args...]")
+	}
+
+	switch *accessListMode {
+	case "auto", "on", "off":
+	default:
+		return fmt.Errorf("invalid -access-list %q: use auto, on or off", *accessListMode)
+	}
+
+	err := w.LoadWallet()
+	if err != nil {
+		return fmt.Errorf("error loading wallet: %w", err)
+	}
+
+	contract, err := resolveBookAddress(w, flag.Arg(1), nil)
+	if err != nil {
+		return fmt.Errorf("invalid contract: %w", err)
+	}
+
+	contractABI, err := loadABIFlag()
+	if err != nil {
+		return err
+	}
+
+	method := flag.Arg(2)
+	args := flag.Args()[3:]
+
+	var data []byte
+	if strings.HasPrefix(method, "0x") {
+		if len(args) > 0 {
+			return fmt.Errorf("arguments are only accepted with a method name and -abi")
+		}
+		if data, err = hexutil.Decode(method); err != nil {
+			return fmt.Errorf("invalid call data: %w", err)
+		}
+	} else {
+		if contractABI == nil {
+			return fmt.Errorf("-abi <file> is required to call %s by name", method)
+		}
+		if data, err = blockchain.PackMethodCall(contractABI, method, args); err != nil {
+			return err
+		}
+	}
+
+	value, err := crypto.ParseUnits(*contractValue, 18)
+	if err != nil {
+		return fmt.Errorf("invalid -value: %w", err)
+	}
+
+	req := wallet.TxRequest{
+		To:    contract,
+		Value: value,
+		Data:  data,
+		ABI:   contractABI,
+		Force: *forceSend,
+	}
+
+	if *forceSend {
+		if err := w.Simulate(req); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+
+	if err := chooseAccessList(w, &req); err != nil {
+		return err
+	}
+
+	fmt.Printf("Calling %s on %s", method, contract.Hex())
+	if value.Sign() > 0 {
+		fmt.Printf(" with %s ETH", crypto.FormatUnits(value, 18))
+	}
+	fmt.Println()
+
+	if !*assumeYes && !confirm("Send?") {
+		return fmt.Errorf("transaction cancelled")
+	}
+
+	txHash, err := w.Send(req)
+	if err != nil {
+		return fmt.Errorf("error sending transaction: %w", err)
+	}
+
+	fmt.Printf("Transaction sent!\n")
+	fmt.Printf("Transaction hash: %s\n", txHash)
+	if *waitConfirmations > 0 {
+		return waitForStatus(w, txHash)
+	}
+	fmt.Printf("Check status: ./crypto-wallet status %s\n", txHash)
+
+	return nil
+}
+
+func chooseAccessList(w *wallet.Wallet, req *wallet.TxRequest) error {
+	if *accessListMode == "off" {
+		return nil
+	}
+
+	estimate, err := w.EstimateAccessList(*req)
+	if err != nil {
+		if *accessListMode == "on" {
+			return err
+		}
+		fmt.Printf("Access list unavailable, sending without one: %v\n", err)
+		return nil
+	}
+
+	keys := 0
+	for _, tuple := range estimate.AccessList {
+		keys += len(tuple.StorageKeys)
+	}
+
+	saved := new(big.Int).Sub(new(big.Int).SetUint64(estimate.GasWithout), new(big.Int).SetUint64(estimate.GasWith))
+	fmt.Printf("Gas without access list: %d\n", estimate.GasWithout)
+	fmt.Printf("Gas with access list:    %d (%d addresses, %d storage keys)\n", estimate.GasWith, len(estimate.AccessList), keys)
+
+	if *accessListMode == "auto" && !estimate.Cheaper() {
+		fmt.Println("Sending a legacy transaction, the access list does not save gas")
+		return nil
+	}
+
+	if estimate.Cheaper() {
+		fmt.Printf("Using the access list, saves %s gas\n", saved)
+	} else {
+		fmt.Println("Using the access list")
+	}
+
+	req.AccessList = estimate.AccessList
+	req.GasLimit = estimate.GasWith
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"crypto-wallet/internal/backup"
+	"crypto-wallet/internal/wallet"
+)
+
+var importOverwrite = flag.Bool("overwrite", false, "import: replace conflicting local files with the imported ones")
+
+func handleExport(w *wallet.Wallet) error {
+	if flag.NArg() < 1 {
+		return fmt.Errorf("usage: export <bundle_file>")
+	}
+
+	file := flag.Arg(0)
+	if _, err := os.Stat(file); err == nil && !*assumeYes && !confirm(fmt.Sprintf("Overwrite %s?", file)) {
+		return fmt.Errorf("export cancelled")
+	}
+
+	bundle, err := backup.Collect(w.WalletFile)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := readSecret("Passphrase: ")
+	if err != nil {
+		return err
+	}
+	repeated, err := readSecret("Repeat passphrase: ")
+	if err != nil {
+		return err
+	}
+	if passphrase != repeated {
+		return fmt.Errorf("passphrases do not match")
+	}
+
+	data, err := backup.Encrypt(bundle, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return fmt.Errorf("error writing bundle: %w", err)
+	}
+
+	fmt.Printf("Exported %s with %d metadata file(s) to %s\n", w.WalletFile, len(bundle.Files), file)
+	return nil
+}
+
+func handleImport(w *wallet.Wallet) error {
+	if flag.NArg() < 1 {
+		return fmt.Errorf("usage: import <bundle_file> | import key [file] | import keystore <file> | import mnemonic")
+	}
+
+	switch flag.Arg(0) {
+	case "key":
+		return handleImportKey(w)
+	case "keystore":
+		return handleImportKeystore(w)
+	case "mnemonic":
+		return handleImportMnemonic(w)
+	}
+
+	data, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error reading bundle: %w", err)
+	}
+
+	passphrase, err := readSecret("Passphrase: ")
+	if err != nil {
+		return err
+	}
+
+	bundle, err := backup.Decrypt(data, passphrase)
+	if err != nil {
+		return err
+	}
+
+	plan, err := backup.PlanImport(bundle, w.WalletFile, *importOverwrite)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Importing wallet %s into %s\n", plan.Address.Hex(), w.WalletFile)
+	printFileList("New", plan.Added)
+	printFileList("Merged", plan.Merged)
+	printFileList("Unchanged", plan.Unchanged)
+
+	if len(plan.Conflicts) > 0 {
+		fmt.Println("Conflicts:")
+		for _, conflict := range plan.Conflicts {
+			fmt.Printf("  %s\n", conflict)
+		}
+		return fmt.Errorf("import aborted, nothing was written; use -overwrite to take the imported versions")
+	}
+
+	if len(plan.Added)+len(plan.Merged) == 0 {
+		fmt.Println("Nothing to import")
+		return nil
+	}
+
+	if !*assumeYes && !confirm("Import?") {
+		return fmt.Errorf("import cancelled")
+	}
+
+	if err := plan.Apply(); err != nil {
+		return err
+	}
+
+	fmt.Println("Import complete")
+	return nil
+}
+
+func printFileList(label string, files []string) {
+	if len(files) > 0 {
+		fmt.Printf("%s: %s\n", label, strings.Join(files, ", "))
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/gasoracle"
+	"crypto-wallet/internal/wallet"
+)
+
+const (
+	transferGas      = 21000
+	tokenTransferGas = 65000
+)
+
+var (
+	feeTier     = flag.String("fee-tier", string(gasoracle.Standard), "Fee tier for sending: slow, standard or fast")
+	maxGasPrice = flag.String("max-gas-price", "", "Refuse to send above this gas price in gwei")
+)
+
+func configureFees(w *wallet.Wallet) error {
+	tier, err := gasoracle.ParseTier(*feeTier)
+	if err != nil {
+		return err
+	}
+
+	oracle := gasoracle.New(w.Blockchain)
+	if *maxGasPrice != "" {
+		if oracle.Ceiling, err = crypto.ParseUnits(*maxGasPrice, 9); err != nil {
+			return fmt.Errorf("invalid -max-gas-price: %w", err)
+		}
+	}
+
+	w.FeeOracle = oracle
+	w.FeeTier = tier
+	return nil
+}
+
+func formatGwei(value *big.Int) string {
+	return crypto.FormatUnits(value, 9) + " gwei"
+}
+
+func gasCost(price *big.Int, gas int64) string {
+	return crypto.FormatUnits(new(big.Int).Mul(price, big.NewInt(gas)), 18) + " ETH"
+}
+
+func handleGas(w *wallet.Wallet) error {
+	estimate, err := w.FeeOracle.Estimate()
+	if errors.Is(err, gasoracle.ErrNoFeeMarket) {
+		price, err := w.Blockchain.GetGasPrice()
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("This chain has no EIP-1559 fee market, using the node's gas price")
+		fmt.Printf("Gas price:      %s\n", formatGwei(price))
+		fmt.Printf("Transfer:       %s\n", gasCost(price, transferGas))
+		fmt.Printf("Token transfer: %s\n", gasCost(price, tokenTransferGas))
+		if err := w.FeeOracle.CheckCeiling(price); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Base fee: %s, next block %s (last %d blocks)\n\n", formatGwei(estimate.BaseFee), formatGwei(estimate.NextBaseFee), estimate.Blocks)
+	fmt.Printf("%-9s %-16s %-16s %-16s %-24s %s\n", "Tier", "Tip", "Max fee", "Gas price", "Transfer", "Token transfer")
+
+	for _, tier := range gasoracle.Tiers {
+		fee := estimate.Tiers[tier]
+
+		marker := ""
+		if tier == w.FeeTier {
+			marker = " *"
+		}
+		if w.FeeOracle.CheckCeiling(fee.GasPrice) != nil {
+			marker += " (above ceiling)"
+		}
+
+		fmt.Printf("%-9s %-16s %-16s %-16s %-24s %s%s\n", tier,
+			formatGwei(fee.Tip), formatGwei(fee.MaxFee), formatGwei(fee.GasPrice),
+			gasCost(fee.GasPrice, transferGas), gasCost(fee.GasPrice, tokenTransferGas), marker)
+	}
+
+	fmt.Printf("\nCosts use the gas price for %d gas (transfer) and %d gas (token transfer)\n", transferGas, tokenTransferGas)
+	if w.FeeOracle.Ceiling != nil {
+		fmt.Printf("Ceiling: %s\n", formatGwei(w.FeeOracle.Ceiling))
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/indexer"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	addressFlag      = flag.String("address", "", "Address(es) to index or show history for (default: wallet address)")
+	indexToBlock     = flag.Int64("to-block", -1, "Last block to index (default: latest)")
+	historyDirection = flag.String("direction", "", "history: filter by direction (in, out, self)")
+	historyAsset     = flag.String("asset", "", "history: filter by asset (ETH or token address)")
+	historyStatus    = flag.String("status", "", "history: filter by status (success, failed)")
+	historyPage      = flag.Int("page", 1, "history: page number")
+	historyLimit     = flag.Int("limit", 20, "history: records per page")
+)
+
+func historyAddresses(w *wallet.Wallet) ([]common.Address, error) {
+	if *addressFlag == "" {
+		err := w.LoadWallet()
+		if err != nil {
+			return nil, fmt.Errorf("error loading wallet: %w", err)
+		}
+		return []common.Address{w.KeyPair.Address}, nil
+	}
+
+	book, err := loadAddressBook(w)
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []common.Address
+	for _, part := range strings.Split(*addressFlag, ",") {
+		address, _, err := resolveAddress(w, book, strings.TrimSpace(part), nil)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+
+	return addresses, nil
+}
+
+func handleIndex(w *wallet.Wallet) error {
+	addresses, err := historyAddresses(w)
+	if err != nil {
+		return err
+	}
+
+	store, err := indexer.Open(w.SidecarPath("history.db"))
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	from, err := indexStartBlock(store, addresses)
+	if err != nil {
+		return err
+	}
+
+	to := uint64(*indexToBlock)
+	if *indexToBlock < 0 {
+		to, err = w.Blockchain.GetBlockNumber()
+		if err != nil {
+			return err
+		}
+	}
+
+	if from > to {
+		fmt.Printf("Already indexed up to block %d\n", to)
+		return nil
+	}
+
+	fmt.Printf("Indexing blocks %d-%d for %d address(es)...\n", from, to, len(addresses))
+
+	ix := &indexer.Indexer{Client: w.Blockchain, Store: store, Addresses: addresses}
+	total, err := ix.IndexRange(from, to, func(block uint64, found int) {
+		if found > 0 || block%100 == 0 || block == to {
+			fmt.Printf("Block %d: %d record(s)\n", block, found)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Indexed %d record(s)\n", total)
+	return nil
+}
+
+func indexStartBlock(store *indexer.Store, addresses []common.Address) (uint64, error) {
+	if *watchFromBlock >= 0 {
+		return uint64(*watchFromBlock), nil
+	}
+
+	var from uint64
+	for i, address := range addresses {
+		lastBlock, found, err := store.LastBlock(address)
+		if err != nil {
+			return 0, err
+		}
+		if !found {
+			return 0, fmt.Errorf("address %s has not been indexed yet, use -from-block", address.Hex())
+		}
+		if i == 0 || lastBlock+1 < from {
+			from = lastBlock + 1
+		}
+	}
+
+	return from, nil
+}
+
+func handleHistory(w *wallet.Wallet) error {
+	addresses, err := historyAddresses(w)
+	if err != nil {
+		return err
+	}
+
+	if len(addresses) != 1 {
+		return fmt.Errorf("history shows one address at a time")
+	}
+
+	if *historyPage < 1 || *historyLimit < 1 {
+		return fmt.Errorf("page and limit must be positive")
+	}
+
+	store, err := indexer.Open(w.SidecarPath("history.db"))
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	records, err := store.Query(indexer.Query{
+		Address:   addresses[0],
+		Direction: *historyDirection,
+		Asset:     *historyAsset,
+		Status:    *historyStatus,
+		Offset:    (*historyPage - 1) * *historyLimit,
+		Limit:     *historyLimit,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 {
+		fmt.Println("No transactions found")
+		return nil
+	}
+
+	tokens := make(map[common.Address]*tokenInfo)
+	names := newENSNames(w)
+
+	fmt.Printf("History of %s (page %d):\n", addresses[0].Hex(), *historyPage)
+	for _, record := range records {
+		value, _ := new(big.Int).SetString(record.Value, 10)
+
+		amount := crypto.FormatUnits(value, 18) + " ETH"
+		if record.Asset != indexer.NativeAsset {
+			amount = lookupToken(w, tokens, common.HexToAddress(record.Asset)).format(value)
+		}
+
+		fmt.Printf("%s  block %-9d %-4s %s  %s  %s  %s\n",
+			time.Unix(int64(record.Timestamp), 0).UTC().Format("2006-01-02 15:04"),
+			record.BlockNumber, record.Direction, amount, names.format(record.Counterparty),
+			record.TxHash.Hex(), record.Status)
+	}
+
+	return nil
+}
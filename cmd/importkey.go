@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	ethereumCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+var derivationPath = flag.String("path", crypto.DefaultDerivationPath, "import mnemonic: BIP-32 derivation path")
+
+func handleImportKey(w *wallet.Wallet) error {
+	var (
+		text string
+		err  error
+	)
+	if flag.NArg() > 1 {
+		data, err := os.ReadFile(flag.Arg(1))
+		if err != nil {
+			return fmt.Errorf("error reading key file: %w", err)
+		}
+		text = string(data)
+	} else if text, err = readSecret("Private key (hex): "); err != nil {
+		return err
+	}
+
+	text = strings.TrimPrefix(strings.TrimSpace(text), "0x")
+	privateKey, err := ethereumCrypto.HexToECDSA(text)
+	if err != nil {
+		return fmt.Errorf("invalid private key: %w", err)
+	}
+
+	return importKeyPair(w, crypto.KeyPairFromPrivateKey(privateKey), nil)
+}
+
+func handleImportKeystore(w *wallet.Wallet) error {
+	if flag.NArg() < 2 {
+		return fmt.Errorf("usage: import keystore <keystore_file>")
+	}
+
+	data, err := os.ReadFile(flag.Arg(1))
+	if err != nil {
+		return fmt.Errorf("error reading keystore file: %w", err)
+	}
+
+	var header struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return fmt.Errorf("error parsing keystore file: %w", err)
+	}
+
+	passphrase, err := readSecret("Keystore passphrase: ")
+	if err != nil {
+		return err
+	}
+
+	key, err := keystore.DecryptKey(data, passphrase)
+	if err != nil {
+		return fmt.Errorf("error decrypting keystore: %w", err)
+	}
+
+	var expected *common.Address
+	if header.Address != "" {
+		if !common.IsHexAddress(header.Address) {
+			return fmt.Errorf("invalid address in keystore file: %s", header.Address)
+		}
+		address := common.HexToAddress(header.Address)
+		expected = &address
+	}
+
+	return importKeyPair(w, crypto.KeyPairFromPrivateKey(key.PrivateKey), expected)
+}
+
+func handleImportMnemonic(w *wallet.Wallet) error {
+	mnemonic, err := readSecret("Mnemonic: ")
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := readSecret("BIP-39 passphrase (empty for none): ")
+	if err != nil {
+		return err
+	}
+
+	keyPair, err := crypto.KeyPairFromMnemonic(mnemonic, passphrase, *derivationPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Derived %s from path %s\n", keyPair.Address.Hex(), *derivationPath)
+	return importKeyPair(w, keyPair, nil)
+}
+
+func importKeyPair(w *wallet.Wallet, keyPair *crypto.KeyPair, expected *common.Address) error {
+	if *addressFlag != "" {
+		address, err := crypto.HexToAddress(*addressFlag)
+		if err != nil {
+			return err
+		}
+		if expected != nil && *expected != address {
+			return fmt.Errorf("keystore is for %s, expected %s", expected.Hex(), address.Hex())
+		}
+		expected = &address
+	}
+
+	if expected != nil && keyPair.Address != *expected {
+		return fmt.Errorf("key belongs to %s, expected %s", keyPair.Address.Hex(), expected.Hex())
+	}
+
+	if expected != nil {
+		fmt.Printf("Key verified for %s\n", keyPair.Address.Hex())
+	} else if !*assumeYes && !confirm(fmt.Sprintf("Import key for address %s?", keyPair.Address.Hex())) {
+		return fmt.Errorf("import cancelled")
+	}
+
+	written, err := installKey(w, keyPair)
+	if err != nil || !written {
+		return err
+	}
+
+	fmt.Printf("Key imported to %s\n", w.WalletFile)
+	return nil
+}
+
+func installKey(w *wallet.Wallet, keyPair *crypto.KeyPair) (bool, error) {
+	if _, err := os.Stat(w.WalletFile); err == nil {
+		existing := &wallet.Wallet{WalletFile: w.WalletFile}
+		defer existing.Close()
+		if err := existing.LoadWallet(); err == nil && existing.KeyPair.Address == keyPair.Address {
+			fmt.Printf("%s already holds this key\n", w.WalletFile)
+			return false, nil
+		}
+		if !*assumeYes && !confirm(fmt.Sprintf("Overwrite existing %s?", w.WalletFile)) {
+			return false, fmt.Errorf("%s was not changed", w.WalletFile)
+		}
+	}
+
+	w.KeyPair = keyPair
+	if err := w.SaveWallet(); err != nil {
+		return false, fmt.Errorf("error saving wallet: %w", err)
+	}
+
+	return true, nil
+}
@@ -1,12 +1,33 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
+	"strings"
+	"time"
 
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/crypto"
 	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/term"
+)
+
+var (
+	forceSend         = flag.Bool("force", false, "Send even if the transaction simulation reverts")
+	abiFile           = flag.String("abi", "", "Contract ABI JSON file used to decode custom errors")
+	waitConfirmations = flag.Uint64("confirmations", 0, "Wait for this many confirmations")
+	waitTimeout       = flag.Duration("timeout", 10*time.Minute, "Maximum time to wait for confirmations")
+	policyFile        = flag.String("policy", "", "Spending policy file (default: <wallet>.policy.json if present)")
+	sessionTimeout    = flag.Duration("session", 0, "Wipe the unlocked key from memory after this long (default: on exit)")
 )
 
 const (
@@ -25,7 +46,7 @@ func main() {
 	var blockchainURL, walletFile string
 	flag.StringVar(&blockchainURL, "url", defaultBlockchainURL, "Blockchain URL")
 	flag.StringVar(&walletFile, "wallet", defaultWalletFile, "Wallet file")
-	flag.Parse()
+	flag.CommandLine.Parse(os.Args[2:])
 
 	w, err := wallet.NewWallet(blockchainURL, walletFile)
 	if err != nil {
@@ -33,10 +54,18 @@ func main() {
 		os.Exit(1)
 	}
 	defer w.Close()
+	w.PolicyFile = *policyFile
+	w.SessionTimeout = *sessionTimeout
+	if err := configureFees(w); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	switch command {
 	case "generate":
 		err = handleGenerate(w)
+	case "vanity":
+		err = handleVanity(w)
 	case "address":
 		err = handleAddress(w)
 	case "balance":
@@ -45,6 +74,48 @@ func main() {
 		err = handleSend(w)
 	case "status":
 		err = handleStatus(w)
+	case "nft":
+		err = handleNFT(w)
+	case "approvals":
+		err = handleApprovals(w)
+	case "revoke":
+		err = handleRevoke(w)
+	case "permit":
+		err = handlePermit(w)
+	case "permit2":
+		err = handlePermit2(w)
+	case "safe":
+		err = handleSafe(w)
+	case "verify":
+		err = handleVerify(w)
+	case "userop":
+		err = handleUserOp(w)
+	case "contract":
+		err = handleContract(w)
+	case "tx":
+		err = handleTx(w)
+	case "gas":
+		err = handleGas(w)
+	case "book":
+		err = handleBook(w)
+	case "request":
+		err = handleRequest(w)
+	case "pay":
+		err = handlePay(w)
+	case "export":
+		err = handleExport(w)
+	case "import":
+		err = handleImport(w)
+	case "backup":
+		err = handleBackup(w)
+	case "payout":
+		err = handlePayout(w)
+	case "watch":
+		err = handleWatch(w)
+	case "index":
+		err = handleIndex(w)
+	case "history":
+		err = handleHistory(w)
 	case "help":
 		printUsage()
 	default:
@@ -101,6 +172,26 @@ func handleBalance(w *wallet.Wallet) error {
 		return fmt.Errorf("error loading wallet: %w", err)
 	}
 
+	if *balanceProof {
+		return handleVerifiedBalance(w)
+	}
+
+	if *requestToken != "" {
+		token, err := resolveBookAddress(w, *requestToken, nil)
+		if err != nil {
+			return fmt.Errorf("invalid token: %w", err)
+		}
+
+		balance, err := w.Blockchain.GetTokenBalance(token, w.KeyPair.Address)
+		if err != nil {
+			return err
+		}
+
+		info := lookupToken(w, make(map[common.Address]*tokenInfo), token)
+		fmt.Printf("Balance: %s\n", info.format(balance))
+		return nil
+	}
+
 	balance, err := w.GetBalance()
 	if err != nil {
 		return fmt.Errorf("error getting balance: %w", err)
@@ -111,7 +202,7 @@ func handleBalance(w *wallet.Wallet) error {
 }
 
 func handleSend(w *wallet.Wallet) error {
-	if len(os.Args) < 4 {
+	if flag.NArg() < 2 {
 		return fmt.Errorf("usage: send <recipient_address> <amount_in_eth>")
 	}
 
@@ -120,8 +211,22 @@ func handleSend(w *wallet.Wallet) error {
 		return fmt.Errorf("error loading wallet: %w", err)
 	}
 
-	toAddress := os.Args[2]
-	amountStr := os.Args[3]
+	amountStr := flag.Arg(1)
+
+	book, err := loadAddressBook(w)
+	if err != nil {
+		return err
+	}
+
+	chainID, err := w.Blockchain.GetChainID()
+	if err != nil {
+		return err
+	}
+
+	toAddress, recipient, err := resolveAddress(w, book, flag.Arg(0), chainID)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
 
 	amount, ok := new(big.Float).SetString(amountStr)
 	if !ok {
@@ -132,13 +237,37 @@ func handleSend(w *wallet.Wallet) error {
 		return fmt.Errorf("ETH amount must be positive")
 	}
 
-	fmt.Printf("Sending %s ETH to address %s...\n", amountStr, toAddress)
+	contractABI, err := loadABIFlag()
+	if err != nil {
+		return err
+	}
+
+	req := wallet.TxRequest{
+		To:    toAddress,
+		Value: crypto.EtherToWei(amount),
+		ABI:   contractABI,
+		Force: *forceSend,
+	}
+
+	if *forceSend {
+		if err := w.Simulate(req); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
 
-	txHash, err := w.SendTransaction(toAddress, amount)
+	if err := confirmRecipient(book, toAddress); err != nil {
+		return err
+	}
+
+	fmt.Printf("Sending %s ETH to %s...\n", amountStr, recipient)
+
+	txHash, err := w.Send(req)
 	if err != nil {
 		return fmt.Errorf("error sending transaction: %w", err)
 	}
 
+	recordRecipient(book, toAddress)
+
 	fmt.Printf("Transaction sent!\n")
 	fmt.Printf("Transaction hash: %s\n", txHash)
 	fmt.Printf("Check status: ./crypto-wallet status %s\n", txHash)
@@ -146,15 +275,32 @@ func handleSend(w *wallet.Wallet) error {
 	return nil
 }
 
+func loadABIFlag() (*abi.ABI, error) {
+	if *abiFile == "" {
+		return nil, nil
+	}
+
+	contractABI, err := blockchain.LoadABI(*abiFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return contractABI, nil
+}
+
 func handleStatus(w *wallet.Wallet) error {
-	if len(os.Args) < 3 {
+	if flag.NArg() < 1 {
 		return fmt.Errorf("usage: status <transaction_hash>")
 	}
 
-	txHash := os.Args[2]
+	txHash := flag.Arg(0)
 
 	fmt.Printf("Checking transaction status %s...\n", txHash)
 
+	if *waitConfirmations > 0 {
+		return waitForStatus(w, txHash)
+	}
+
 	receipt, err := w.GetTransactionStatus(txHash)
 	if err != nil {
 		return fmt.Errorf("error getting transaction status: %w", err)
@@ -165,42 +311,257 @@ func handleStatus(w *wallet.Wallet) error {
 		return nil
 	}
 
+	confirmations, canonical, err := w.GetConfirmations(receipt)
+	if err != nil {
+		return err
+	}
+
+	if !canonical {
+		fmt.Println("Transaction block was reorged out of the chain")
+		fmt.Printf("Block number: %d (%s)\n", receipt.BlockNumber.Uint64(), receipt.BlockHash.Hex())
+		return nil
+	}
+
+	printParties(w, receipt.TxHash)
+
 	if receipt.Status == 1 {
 		fmt.Println("Transaction confirmed!")
 		fmt.Printf("Block number: %d\n", receipt.BlockNumber.Uint64())
+		fmt.Printf("Confirmations: %d\n", confirmations)
 		fmt.Printf("Gas used: %d\n", receipt.GasUsed)
 	} else {
 		fmt.Println("Transaction failed")
+		fmt.Printf("Block number: %d\n", receipt.BlockNumber.Uint64())
+		fmt.Printf("Confirmations: %d\n", confirmations)
+		printRevertReason(w, txHash)
 	}
 
 	return nil
 }
 
+func printParties(w *wallet.Wallet, hash common.Hash) {
+	tx, _, err := w.Blockchain.GetTransaction(hash)
+	if err != nil || tx == nil {
+		return
+	}
+
+	names := newENSNames(w)
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err == nil {
+		fmt.Printf("From: %s\n", names.format(from))
+	}
+	if tx.To() != nil {
+		fmt.Printf("To: %s\n", names.format(*tx.To()))
+	}
+}
+
+func printRevertReason(w *wallet.Wallet, txHash string) {
+	contractABI, err := loadABIFlag()
+	if err != nil {
+		fmt.Printf("Revert reason unavailable: %v\n", err)
+		return
+	}
+
+	err = w.ReplayTransaction(txHash, contractABI)
+
+	var revert *blockchain.RevertError
+	switch {
+	case errors.As(err, &revert):
+		fmt.Printf("Revert reason: %s\n", revert.Reason)
+	case err != nil:
+		fmt.Printf("Revert reason unavailable: %v\n", err)
+	default:
+		fmt.Println("Revert reason: replay succeeded, the transaction most likely ran out of gas")
+	}
+}
+
+func waitForStatus(w *wallet.Wallet, txHash string) error {
+	fmt.Printf("Waiting for %d confirmations...\n", *waitConfirmations)
+
+	result, err := w.WaitForConfirmations(txHash, *waitConfirmations, *waitTimeout)
+	if err != nil {
+		return err
+	}
+
+	switch result.Status {
+	case blockchain.WaitConfirmed:
+		fmt.Println("Transaction confirmed!")
+	case blockchain.WaitFailed:
+		fmt.Println("Transaction failed")
+		defer printRevertReason(w, txHash)
+	case blockchain.WaitReorged:
+		fmt.Printf("Transaction block %s was reorged out of the chain\n", result.BlockHash.Hex())
+		return nil
+	case blockchain.WaitTimedOut:
+		if result.Receipt == nil {
+			fmt.Println("Transaction not yet confirmed")
+			return nil
+		}
+		fmt.Println("Timed out waiting for confirmations")
+	}
+
+	fmt.Printf("Block number: %d\n", result.Receipt.BlockNumber.Uint64())
+	fmt.Printf("Confirmations: %d\n", result.Confirmations)
+	fmt.Printf("Gas used: %d\n", result.Receipt.GasUsed)
+
+	return nil
+}
+
 func printUsage() {
 	fmt.Println("Simple Crypto Wallet - Ethereum cryptocurrency wallet")
 	fmt.Println()
 	fmt.Println("Usage:")
-	fmt.Println("  ./crypto-wallet <command> [arguments]")
+	fmt.Println("  ./crypto-wallet <command> [flags] [arguments]")
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  generate                    Generate new wallet")
+	fmt.Println("  vanity                      Generate a wallet whose address matches a pattern")
 	fmt.Println("  address                     Show wallet address")
-	fmt.Println("  balance                     Show wallet balance")
-	fmt.Println("  send <address> <amount>     Send ETH")
+	fmt.Println("  balance                     Show wallet balance (ETH, or a token with -token)")
+	fmt.Println("  send <address|name> <amount> Send ETH (name: address book nickname or ENS name)")
 	fmt.Println("  status <hash>               Check transaction status")
+	fmt.Println("  request [amount]            Show an EIP-681 payment request URI and QR code")
+	fmt.Println("  pay <ethereum:uri>          Pay an EIP-681 payment request")
+	fmt.Println("  book list|add|remove        Manage the address book (nickname -> address)")
+	fmt.Println("  approvals                   List active ERC-20 allowances and NFT operator approvals")
+	fmt.Println("  revoke <all|spender|token>... Revoke matching approvals (approve 0 / setApprovalForAll false)")
+	fmt.Println("  permit <token> <spender> <amount|max> Sign an EIP-2612 permit")
+	fmt.Println("  permit2 <token> <spender> <amount|max> Sign a Uniswap Permit2 PermitSingle (or -transfer)")
+	fmt.Println("  safe info <safe>            Show a Safe's version, nonce, threshold and owners")
+	fmt.Println("  safe propose <safe> <to> <amount> Create and sign a Safe transaction proposal")
+	fmt.Println("  safe sign <proposal.json>   Co-sign a Safe transaction proposal")
+	fmt.Println("  safe exec <proposal.json> [signed.json...] Merge signatures and execute the Safe transaction")
+	fmt.Println("  verify <address> <message> <signature> Verify an EOA, ERC-1271 or ERC-6492 signature")
+	fmt.Println("  userop send <account> <to> <amount> Send an ERC-4337 UserOperation from a smart account")
+	fmt.Println("  userop receipt <hash>       Show the receipt of a UserOperation")
+	fmt.Println("  contract send <contract> <method|0xdata> [args...] Call a contract method, with an access list when cheaper")
+	fmt.Println("  gas                         Show slow/standard/fast fees and transfer costs")
+	fmt.Println("  tx decode <hex|file>        Decode a signed raw transaction and its call data")
+	fmt.Println("  nft owner <contract> <id>   Show the owner of an ERC-721 token")
+	fmt.Println("  nft balance <contract> [id...] Show ERC-721 count or ERC-1155 balances of the wallet")
+	fmt.Println("  nft info <contract> <id>    Show tokenURI/uri and decoded metadata")
+	fmt.Println("  nft send <contract> <to> <id> [amount] Transfer an NFT with safeTransferFrom")
+	fmt.Println("  export <file>               Write an encrypted bundle of the wallet and its metadata")
+	fmt.Println("  import <file>               Import an encrypted bundle into the wallet")
+	fmt.Println("  import key [file]           Import a raw hex private key (from file or prompt)")
+	fmt.Println("  import keystore <file>      Import a geth/MetaMask keystore v3 file")
+	fmt.Println("  import mnemonic             Import a key derived from a BIP-39 mnemonic")
+	fmt.Println("  backup split|combine        Split the key into Shamir shares or restore it from shares")
+	fmt.Println("  payout <file.csv>           Send batch payouts from CSV (address,amount[,token])")
+	fmt.Println("  watch                       Report incoming ETH and token payments")
+	fmt.Println("  index                       Index transactions of the wallet into the local history database")
+	fmt.Println("  history                     Show indexed transaction history")
 	fmt.Println("  help                        Show this help")
 	fmt.Println()
 	fmt.Println("Flags:")
 	fmt.Println("  -url <url>                  Blockchain URL (default: Sepolia)")
 	fmt.Println("  -wallet <file>              Wallet file (default: wallet.json)")
+	fmt.Println("  -results <file>             Payout results CSV (default: <file>.results.csv)")
+	fmt.Println("  -yes                        Skip confirmation prompts")
+	fmt.Println("  -policy <file>              Spending policy (default: <wallet>.policy.json if present)")
+	fmt.Println("  -session <duration>         Keep the key unlocked only this long, e.g. 5m (default: until exit)")
+	fmt.Println("  -force                      send: send even if the simulated transaction reverts")
+	fmt.Println("  -abi <file>                 send/status/contract send: contract ABI for method calls and custom errors")
+	fmt.Println("                              tx decode: comma-separated ABI files for call data")
+	fmt.Println("  -confirmations <n>          status: wait until the transaction has n confirmations")
+	fmt.Println("  -timeout <duration>         status: maximum wait time (default: 10m)")
+	fmt.Println("  -prefix <hex>, -suffix <hex> vanity: required address prefix / suffix")
+	fmt.Println("  -regex <expr>               vanity: regular expression the address must match")
+	fmt.Println("  -case-sensitive             vanity: match the EIP-55 checksum casing")
+	fmt.Println("  -workers <n>                vanity: parallel workers (default: number of CPUs)")
+	fmt.Println("  -token <address>            request/safe propose/balance: use an ERC-20 token instead of ETH")
+	fmt.Println("  -png <file>                 request: save the QR code as PNG instead of printing it")
+	fmt.Println("  -path <path>                import mnemonic: derivation path (default: m/44'/60'/0'/0/0)")
+	fmt.Println("  -overwrite                  import: take the imported version of conflicting files")
+	fmt.Println("  -threshold <n>, -shares <n> backup split: shares needed / shares created (default: 3 of 5)")
+	fmt.Println("  -mnemonic                   backup split/combine: the shares hold a BIP-39 mnemonic instead of the wallet key")
+	fmt.Println("  -words                      backup split/combine: use SLIP-39 word shares")
+	fmt.Println("  -notes <text>               book add: notes for the entry")
+	fmt.Println("  -chains <id,...>            book add: chain IDs the entry may be used on")
+	fmt.Println("  -deadline <duration>        permit/permit2: signature validity (default: 1h)")
+	fmt.Println("  -expiration <duration>      permit2: allowance lifetime (default: 720h)")
+	fmt.Println("  -transfer                   permit2: sign a one-time PermitTransferFrom")
+	fmt.Println("  -json                       permit/permit2: print typed data and signature as JSON")
+	fmt.Println("  -data <hex>                 safe propose/userop send: call data for the target")
+	fmt.Println("  -out <file>                 safe propose/sign: where to write the proposal")
+	fmt.Println("  -hash                       verify: the message is a 32-byte hex hash")
+	fmt.Println("  -bundler <url>              userop: bundler RPC URL (default: -url)")
+	fmt.Println("  -entrypoint <0.6|0.7>       userop: EntryPoint version or address (default: 0.7)")
+	fmt.Println("  -factory <address>          userop send: factory that deploys the account on first use")
+	fmt.Println("  -factory-data <hex>         userop send: call data for the factory")
+	fmt.Println("  -value <eth>                contract send: ETH to send with the call")
+	fmt.Println("  -access-list <mode>         contract send: auto, on or off (default: auto)")
+	fmt.Println("  -proof                      balance: verify the balance with a Merkle proof against the block header")
+	fmt.Println("  -block <hash>               balance -proof: block to verify against (default: latest)")
+	fmt.Println("  -slot <n>                   balance -proof -token: slot of the token's balances mapping (default: search 0-19)")
+	fmt.Println("  -selectors <file>           tx decode: 4-byte selector database (default: <wallet>.selectors.json)")
+	fmt.Println("  -fee-tier <tier>            Fee tier for sending: slow, standard or fast (default: standard)")
+	fmt.Println("  -max-gas-price <gwei>       Refuse to send above this gas price")
+	fmt.Println("  -http                       nft info: fetch off-chain metadata over HTTP(S)/IPFS")
+	fmt.Println("  -ipfs-gateway <url>         nft info: gateway for ipfs:// URIs (default: https://ipfs.io/ipfs/)")
+	fmt.Println("  -from-block <n>             watch/index/approvals: first block to scan")
+	fmt.Println("  -to-block <n>               index/approvals: last block to scan (default: latest)")
+	fmt.Println("  -address <addr>[,<addr>]    index/history/approvals: addresses (default: wallet address)")
+	fmt.Println("                              import key/keystore/mnemonic: expected address of the key")
+	fmt.Println("  -direction <in|out|self>    history: filter by direction")
+	fmt.Println("  -asset <ETH|token>          history: filter by asset")
+	fmt.Println("  -status <success|failed>    history: filter by status")
+	fmt.Println("  -page <n>, -limit <n>       history: paging (default: page 1, 20 per page)")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  ./crypto-wallet generate")
 	fmt.Println("  ./crypto-wallet address")
 	fmt.Println("  ./crypto-wallet balance")
-	fmt.Println("  ./crypto-wallet send 0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6 0.001")
+	fmt.Println("  ./crypto-wallet send 0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6 0.001")
 	fmt.Println("  ./crypto-wallet status 0x123...")
+	fmt.Println("  ./crypto-wallet payout payouts.csv")
 	fmt.Println()
 	fmt.Println("IMPORTANT: This wallet is intended for testing only!")
 	fmt.Println("  Do not use it for storing real funds.")
 }
+
+var stdin = bufio.NewReader(os.Stdin)
+
+func confirm(prompt string) bool {
+	return confirmOn(os.Stdout, prompt)
+}
+
+func confirmOn(out io.Writer, prompt string) bool {
+	fmt.Fprintf(out, "%s [y/N]: ", prompt)
+
+	answer, err := stdin.ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func readLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("error reading input: %w", err)
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return readLine(prompt)
+	}
+
+	fmt.Print(prompt)
+	secret, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("error reading input: %w", err)
+	}
+
+	return strings.TrimSpace(string(secret)), nil
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/nft"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	nftHTTP    = flag.Bool("http", false, "nft info: fetch metadata over HTTP(S) and IPFS gateways")
+	nftGateway = flag.String("ipfs-gateway", nft.DefaultIPFSGateway, "nft info: IPFS gateway used for ipfs:// URIs")
+)
+
+func handleNFT(w *wallet.Wallet) error {
+	if flag.NArg() < 2 {
+		return fmt.Errorf("usage: nft <owner|balance|info|send> <contract> ...")
+	}
+
+	err := w.LoadWallet()
+	if err != nil {
+		return fmt.Errorf("error loading wallet: %w", err)
+	}
+
+	contract, err := resolveBookAddress(w, flag.Arg(1), nil)
+	if err != nil {
+		return fmt.Errorf("invalid contract: %w", err)
+	}
+
+	standard, err := w.Blockchain.DetectNFTStandard(contract)
+	if err != nil {
+		return err
+	}
+
+	switch flag.Arg(0) {
+	case "owner":
+		return handleNFTOwner(w, contract, standard)
+	case "balance":
+		return handleNFTBalance(w, contract, standard)
+	case "info":
+		return handleNFTInfo(w, contract, standard)
+	case "send":
+		return handleNFTSend(w, contract, standard)
+	default:
+		return fmt.Errorf("unknown nft command: %s", flag.Arg(0))
+	}
+}
+
+func handleNFTOwner(w *wallet.Wallet, contract common.Address, standard blockchain.NFTStandard) error {
+	if flag.NArg() < 3 {
+		return fmt.Errorf("usage: nft owner <contract> <token_id>")
+	}
+	if standard != blockchain.ERC721 {
+		return fmt.Errorf("%s tokens have no single owner, use: nft balance <contract> <token_id>", standard)
+	}
+
+	tokenID, err := parseTokenID(flag.Arg(2))
+	if err != nil {
+		return err
+	}
+
+	owner, err := w.Blockchain.GetNFTOwner(contract, tokenID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Owner of #%s: %s\n", tokenID, newENSNames(w).format(owner))
+	if owner == w.KeyPair.Address {
+		fmt.Println("The token is held by this wallet")
+	}
+	return nil
+}
+
+func handleNFTBalance(w *wallet.Wallet, contract common.Address, standard blockchain.NFTStandard) error {
+	owner := w.KeyPair.Address
+
+	if standard == blockchain.ERC721 {
+		count, err := w.Blockchain.GetNFTCount(contract, owner)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %s holds %s tokens\n", standard, owner.Hex(), count)
+		return nil
+	}
+
+	if flag.NArg() < 3 {
+		return fmt.Errorf("usage: nft balance <contract> <token_id> [<token_id>...]")
+	}
+
+	ids := make([]*big.Int, 0, flag.NArg()-2)
+	for _, arg := range flag.Args()[2:] {
+		tokenID, err := parseTokenID(arg)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, tokenID)
+	}
+
+	balances, err := w.Blockchain.GetNFTBalances(contract, owner, ids)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s balances of %s:\n", standard, owner.Hex())
+	for i, tokenID := range ids {
+		fmt.Printf("  #%-20s %s\n", tokenID, balances[i])
+	}
+	return nil
+}
+
+func handleNFTInfo(w *wallet.Wallet, contract common.Address, standard blockchain.NFTStandard) error {
+	if flag.NArg() < 3 {
+		return fmt.Errorf("usage: nft info [-http] <contract> <token_id>")
+	}
+
+	tokenID, err := parseTokenID(flag.Arg(2))
+	if err != nil {
+		return err
+	}
+
+	uri, err := w.Blockchain.GetNFTURI(contract, standard, tokenID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Standard: %s\n", standard)
+	fmt.Printf("Token:    #%s\n", tokenID)
+	if len(uri) > 120 {
+		fmt.Printf("URI:      %s...\n", uri[:120])
+	} else {
+		fmt.Printf("URI:      %s\n", uri)
+	}
+
+	metadata, err := nft.Decode(uri)
+	if errors.Is(err, nft.ErrRemoteURI) {
+		if !*nftHTTP {
+			fmt.Println("Metadata is stored off-chain, use -http to fetch it")
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		fetcher := &nft.Fetcher{Client: &http.Client{}, IPFSGateway: *nftGateway}
+		metadata, err = fetcher.Fetch(ctx, uri)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Name:     %s\n", metadata.Name)
+	if metadata.Description != "" {
+		fmt.Printf("About:    %s\n", metadata.Description)
+	}
+	if metadata.Image != "" {
+		fmt.Printf("Image:    %s\n", metadata.Image)
+	}
+	for _, attribute := range metadata.Attributes {
+		fmt.Printf("  %s: %v\n", attribute.TraitType, attribute.Value)
+	}
+	return nil
+}
+
+func handleNFTSend(w *wallet.Wallet, contract common.Address, standard blockchain.NFTStandard) error {
+	if flag.NArg() < 4 {
+		return fmt.Errorf("usage: nft send <contract> <recipient> <token_id> [amount]")
+	}
+
+	tokenID, err := parseTokenID(flag.Arg(3))
+	if err != nil {
+		return err
+	}
+
+	amount := big.NewInt(1)
+	if flag.NArg() > 4 {
+		if standard != blockchain.ERC721 {
+			if amount, err = parseTokenID(flag.Arg(4)); err != nil || amount.Sign() <= 0 {
+				return fmt.Errorf("invalid amount: %s", flag.Arg(4))
+			}
+		} else if flag.Arg(4) != "1" {
+			return fmt.Errorf("%s tokens are unique, the amount must be 1", standard)
+		}
+	}
+
+	book, err := loadAddressBook(w)
+	if err != nil {
+		return err
+	}
+
+	chainID, err := w.Blockchain.GetChainID()
+	if err != nil {
+		return err
+	}
+
+	to, recipient, err := resolveAddress(w, book, flag.Arg(2), chainID)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	from := w.KeyPair.Address
+	if standard == blockchain.ERC721 {
+		owner, err := w.Blockchain.GetNFTOwner(contract, tokenID)
+		if err != nil {
+			return err
+		}
+		if owner != from {
+			return fmt.Errorf("token #%s is owned by %s, not by this wallet", tokenID, owner.Hex())
+		}
+	} else {
+		balances, err := w.Blockchain.GetNFTBalances(contract, from, []*big.Int{tokenID})
+		if err != nil {
+			return err
+		}
+		if balances[0].Cmp(amount) < 0 {
+			return fmt.Errorf("wallet holds %s of token #%s, cannot send %s", balances[0], tokenID, amount)
+		}
+	}
+
+	data, err := blockchain.PackNFTTransfer(standard, from, to, tokenID, amount)
+	if err != nil {
+		return err
+	}
+
+	if err := confirmRecipient(book, to); err != nil {
+		return err
+	}
+
+	fmt.Printf("Sending %s x %s #%s from %s to %s\n", amount, standard, tokenID, contract.Hex(), recipient)
+	if !*assumeYes && !confirm("Send?") {
+		return fmt.Errorf("transfer cancelled")
+	}
+
+	txHash, err := w.Send(wallet.TxRequest{To: contract, Data: data, Force: *forceSend})
+	if err != nil {
+		return fmt.Errorf("error sending transaction: %w", err)
+	}
+	recordRecipient(book, to)
+
+	fmt.Printf("Transaction sent!\n")
+	fmt.Printf("Transaction hash: %s\n", txHash)
+	fmt.Printf("Check status: ./crypto-wallet status %s\n", txHash)
+
+	return nil
+}
+
+func parseTokenID(s string) (*big.Int, error) {
+	tokenID, ok := new(big.Int).SetString(s, 0)
+	if !ok || tokenID.Sign() < 0 {
+		return nil, fmt.Errorf("invalid token ID: %s", s)
+	}
+	return tokenID, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/payout"
+	"crypto-wallet/internal/wallet"
+)
+
+var (
+	payoutResults = flag.String("results", "", "Payout results CSV file")
+	assumeYes     = flag.Bool("yes", false, "Skip confirmation prompts")
+)
+
+func handlePayout(w *wallet.Wallet) error {
+	if flag.NArg() < 1 {
+		return fmt.Errorf("usage: payout <file.csv>")
+	}
+
+	err := w.LoadWallet()
+	if err != nil {
+		return fmt.Errorf("error loading wallet: %w", err)
+	}
+
+	file := flag.Arg(0)
+	base := strings.TrimSuffix(file, ".csv")
+
+	rows, err := payout.ParseFile(file)
+	if err != nil {
+		return fmt.Errorf("error validating payout file: %w", err)
+	}
+
+	decimals, err := payout.Resolve(w.Blockchain, rows)
+	if err != nil {
+		return fmt.Errorf("error validating payout amounts: %w", err)
+	}
+
+	gasPrice, err := w.GasPrice()
+	if err != nil {
+		return err
+	}
+
+	summary, err := payout.Estimate(w.Blockchain, w.KeyPair.Address, rows, decimals, gasPrice)
+	if err != nil {
+		return fmt.Errorf("error estimating payout: %w", err)
+	}
+
+	fmt.Printf("Payout of %d transfers from %s\n", len(rows), w.KeyPair.GetAddressHex())
+
+	assets := make([]string, 0, len(summary.Totals))
+	for asset := range summary.Totals {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	for _, asset := range assets {
+		fmt.Printf("Total %s: %s\n", asset, crypto.FormatUnits(summary.Totals[asset], summary.Decimals[asset]))
+	}
+	fmt.Printf("Estimated fee: %s ETH (%d gas at %s gwei)\n",
+		crypto.FormatUnits(summary.Fee, 18), summary.GasLimit, crypto.FormatUnits(summary.GasPrice, 9))
+
+	if !*assumeYes && !confirm("Send payout?") {
+		return fmt.Errorf("payout cancelled")
+	}
+
+	resultsPath := *payoutResults
+	if resultsPath == "" {
+		resultsPath = base + ".results.csv"
+	}
+
+	runner := &payout.Runner{
+		Wallet:    w,
+		StatePath: base + ".state.json",
+		Progress: func(result payout.Result) {
+			fmt.Printf("Line %d: %s %s -> %s [%s] %s\n",
+				result.Line, result.Amount, result.Asset, result.Recipient, result.Status, result.TxHash)
+		},
+	}
+
+	results, runErr := runner.Run(rows)
+
+	if len(results) > 0 {
+		if err := payout.WriteResults(resultsPath, results); err != nil {
+			return err
+		}
+		fmt.Printf("Results written to %s\n", resultsPath)
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("payout interrupted, run the same command again to resume: %w", runErr)
+	}
+
+	return nil
+}
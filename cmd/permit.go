@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/permit"
+	"crypto-wallet/internal/policy"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var (
+	permitDeadline   = flag.Duration("deadline", time.Hour, "permit/permit2: how long the signature stays valid")
+	permitExpiration = flag.Duration("expiration", 30*24*time.Hour, "permit2: how long the Permit2 allowance lasts")
+	permitTransfer   = flag.Bool("transfer", false, "permit2: sign a one-time PermitTransferFrom instead of a PermitSingle allowance")
+	permitJSON       = flag.Bool("json", false, "permit/permit2: print the typed data and signature as JSON")
+)
+
+type permitArgs struct {
+	token    common.Address
+	spender  common.Address
+	label    string
+	amount   *big.Int
+	display  string
+	chainID  *big.Int
+	deadline *big.Int
+}
+
+func parsePermitArgs(w *wallet.Wallet, command string, max *big.Int) (*permitArgs, error) {
+	if flag.NArg() < 3 {
+		return nil, fmt.Errorf("usage: %s <token> <spender> <amount|max>", command)
+	}
+
+	err := w.LoadWallet()
+	if err != nil {
+		return nil, fmt.Errorf("error loading wallet: %w", err)
+	}
+
+	args := &permitArgs{}
+	if args.chainID, err = w.Blockchain.GetChainID(); err != nil {
+		return nil, err
+	}
+
+	if args.token, err = resolveBookAddress(w, flag.Arg(0), args.chainID); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	info := lookupToken(w, make(map[common.Address]*tokenInfo), args.token)
+	if !info.known {
+		return nil, fmt.Errorf("%s is not an ERC-20 token on chain %s", args.token.Hex(), args.chainID)
+	}
+
+	book, err := loadAddressBook(w)
+	if err != nil {
+		return nil, err
+	}
+	if args.spender, args.label, err = resolveAddress(w, book, flag.Arg(1), args.chainID); err != nil {
+		return nil, fmt.Errorf("invalid spender: %w", err)
+	}
+
+	if strings.EqualFold(flag.Arg(2), "max") {
+		args.amount = max
+		args.display = "unlimited " + info.symbol
+	} else {
+		if args.amount, err = crypto.ParseUnits(flag.Arg(2), info.decimals); err != nil {
+			return nil, fmt.Errorf("invalid amount: %w", err)
+		}
+		args.display = info.format(args.amount)
+	}
+
+	args.deadline = big.NewInt(time.Now().Add(*permitDeadline).Unix())
+	return args, nil
+}
+
+func handlePermit(w *wallet.Wallet) error {
+	args, err := parsePermitArgs(w, "permit", math.MaxBig256)
+	if err != nil {
+		return err
+	}
+
+	domain, err := permit.TokenDomain(w.Blockchain, args.token, args.chainID)
+	if err != nil {
+		return err
+	}
+
+	owner := w.KeyPair.Address
+	nonce, err := w.Blockchain.GetPermitNonce(args.token, owner)
+	if err != nil {
+		return fmt.Errorf("error reading permit nonce: %w", err)
+	}
+
+	out := permitSummary()
+	fmt.Fprintf(out, "EIP-2612 permit: %s from %s to %s\n", args.display, owner.Hex(), args.label)
+	fmt.Fprintf(out, "Domain:   %s v%s, chain %s\n", domain.Name, domain.Version, args.chainID)
+	fmt.Fprintf(out, "Nonce:    %s\n", nonce)
+
+	return signPermit(w, args, permit.NewPermit(domain, owner, args.spender, args.amount, nonce, args.deadline))
+}
+
+func handlePermit2(w *wallet.Wallet) error {
+	max := permit.MaxUint160
+	if *permitTransfer {
+		max = math.MaxBig256
+	}
+
+	args, err := parsePermitArgs(w, "permit2", max)
+	if err != nil {
+		return err
+	}
+
+	owner := w.KeyPair.Address
+
+	if *permitTransfer {
+		nonce, err := permit.NextUnorderedNonce(w.Blockchain, owner, 16)
+		if err != nil {
+			return err
+		}
+
+		out := permitSummary()
+		fmt.Fprintf(out, "Permit2 PermitTransferFrom: %s from %s to %s\n", args.display, owner.Hex(), args.label)
+		fmt.Fprintf(out, "Nonce:    %s\n", nonce)
+
+		return signPermit(w, args, permit.NewPermitTransferFrom(args.chainID, args.token, args.amount, args.spender, nonce, args.deadline))
+	}
+
+	_, _, nonce, err := w.Blockchain.GetPermit2Allowance(owner, args.token, args.spender)
+	if err != nil {
+		return err
+	}
+
+	expiration := uint64(time.Now().Add(*permitExpiration).Unix())
+	typedData, err := permit.NewPermitSingle(args.chainID, permit.PermitDetails{
+		Token:      args.token,
+		Amount:     args.amount,
+		Expiration: expiration,
+		Nonce:      nonce,
+	}, args.spender, args.deadline)
+	if err != nil {
+		return err
+	}
+
+	out := permitSummary()
+	fmt.Fprintf(out, "Permit2 PermitSingle: %s from %s to %s\n", args.display, owner.Hex(), args.label)
+	fmt.Fprintf(out, "Expires:  %s\n", time.Unix(int64(expiration), 0).Format(time.RFC3339))
+	fmt.Fprintf(out, "Nonce:    %d\n", nonce)
+
+	return signPermit(w, args, typedData)
+}
+
+func permitSummary() io.Writer {
+	if *permitJSON {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func signPermit(w *wallet.Wallet, args *permitArgs, typedData apitypes.TypedData) error {
+	out := permitSummary()
+	fmt.Fprintf(out, "Deadline: %s\n", time.Unix(args.deadline.Int64(), 0).Format(time.RFC3339))
+
+	data, err := blockchain.PackTokenApprove(args.spender, args.amount)
+	if err != nil {
+		return err
+	}
+	if err := w.CheckPolicy(policy.Transaction{To: args.token, Data: data}); err != nil {
+		return err
+	}
+
+	if !*assumeYes && !confirmOn(out, fmt.Sprintf("Sign a permit for %s to %s?", args.display, args.label)) {
+		return fmt.Errorf("permit cancelled")
+	}
+
+	raw, hash, err := w.SignTypedData(typedData)
+	if err != nil {
+		return err
+	}
+
+	signature, err := permit.ParseSignature(raw)
+	if err != nil {
+		return err
+	}
+
+	if *permitJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"typedData": typedData,
+			"hash":      hash,
+			"v":         signature.V,
+			"r":         signature.R,
+			"s":         signature.S,
+			"signature": hexutil.Bytes(signature.Packed()),
+		})
+	}
+
+	fmt.Printf("Hash:     %s\n\n", hash.Hex())
+	fmt.Printf("v: %d\n", signature.V)
+	fmt.Printf("r: %s\n", signature.R.Hex())
+	fmt.Printf("s: %s\n", signature.S.Hex())
+	fmt.Printf("Signature: %s\n", hexutil.Encode(signature.Packed()))
+
+	return nil
+}
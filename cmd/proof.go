@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	balanceProof = flag.Bool("proof", false, "balance: verify the balance with eth_getProof against the block's state root")
+	proofBlock   = flag.String("block", "", "balance -proof: block hash to verify against (default: latest block)")
+	balanceSlot  = flag.Int("slot", -1, "balance -proof -token: storage slot of the token's balances mapping (default: search 0-19)")
+)
+
+func handleVerifiedBalance(w *wallet.Wallet) error {
+	var blockHash *common.Hash
+	if *proofBlock != "" {
+		raw, err := hexutil.Decode(*proofBlock)
+		if err != nil || len(raw) != common.HashLength {
+			return fmt.Errorf("invalid -block: expected a 32-byte block hash")
+		}
+		hash := common.BytesToHash(raw)
+		blockHash = &hash
+	}
+
+	address := w.KeyPair.Address
+
+	header, err := w.Blockchain.GetVerifiedHeader(blockHash)
+	if err != nil {
+		return reportProofFailure(w, err)
+	}
+
+	if *requestToken == "" {
+		account, err := w.Blockchain.GetVerifiedAccount(address, header, nil)
+		if err != nil {
+			return reportProofFailure(w, err)
+		}
+
+		fmt.Printf("Balance: %s ETH\n", crypto.FormatUnits(account.Balance, 18))
+		fmt.Printf("%s account proof against block #%d (%s), state root %s\n", proofLabel(blockHash), header.Number, header.Hash.Hex(), header.StateRoot.Hex())
+		printHeaderTrust(blockHash)
+		return nil
+	}
+
+	token, err := resolveBookAddress(w, *requestToken, nil)
+	if err != nil {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+
+	var mapping *uint64
+	if *balanceSlot >= 0 {
+		slot := uint64(*balanceSlot)
+		mapping = &slot
+	}
+
+	balance, err := w.Blockchain.GetVerifiedTokenBalance(token, address, header, mapping)
+	if errors.Is(err, blockchain.ErrBalanceSlotNotFound) {
+		fmt.Println("WARNING: the token balance could NOT be verified.")
+		fmt.Printf("The node's balanceOf does not match any proven balance slot of %s.\n", token.Hex())
+		fmt.Println("The token may keep balances in a non-standard layout (pass -slot), or the node is lying.")
+		return err
+	}
+	if errors.Is(err, blockchain.ErrBalanceSlotRequired) {
+		fmt.Println("WARNING: the token balance could NOT be verified.")
+		fmt.Printf("The node reports a zero balance, which matches every empty slot of %s.\n", token.Hex())
+		fmt.Println("Pass -slot with the token's balances mapping slot to verify a zero balance.")
+		return err
+	}
+	if err != nil {
+		return reportProofFailure(w, err)
+	}
+
+	info := lookupToken(w, make(map[common.Address]*tokenInfo), token)
+	fmt.Printf("Balance: %s\n", info.format(balance.Balance))
+	fmt.Printf("%s storage slot %s (%s mapping at slot %d) of %s\n", proofLabel(blockHash), balance.Slot.Key.Hex(), balance.Slot.Layout, balance.Slot.Mapping, token.Hex())
+	fmt.Printf("%*s against block #%d (%s), state root %s\n", len(proofLabel(blockHash)), "", header.Number, header.Hash.Hex(), header.StateRoot.Hex())
+	printHeaderTrust(blockHash)
+	return nil
+}
+
+func proofLabel(blockHash *common.Hash) string {
+	if blockHash == nil {
+		return "Consistent:"
+	}
+	return "Verified:"
+}
+
+func printHeaderTrust(blockHash *common.Hash) {
+	if blockHash != nil {
+		return
+	}
+	fmt.Println("The header is only self-consistent: the node chose both the header and its hash.")
+	fmt.Println("Pass -block with a block hash from a source you trust to verify the balance.")
+}
+
+func reportProofFailure(w *wallet.Wallet, err error) error {
+	var proofErr *blockchain.ProofError
+	if !errors.As(err, &proofErr) {
+		return err
+	}
+
+	fmt.Println("!!! BALANCE VERIFICATION FAILED !!!")
+	fmt.Printf("%s\n", proofErr.Reason)
+	fmt.Printf("The node at %s returned data that does not match the block it committed to.\n", w.Blockchain.URL())
+	fmt.Println("Do not trust balances or send transactions through this node.")
+	return err
+}
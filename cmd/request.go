@@ -0,0 +1,175 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/eip681"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/skip2/go-qrcode"
+)
+
+var (
+	requestToken = flag.String("token", "", "request: ERC-20 token address to request instead of ETH")
+	requestPNG   = flag.String("png", "", "request: write the QR code to this PNG file")
+)
+
+func handleRequest(w *wallet.Wallet) error {
+	err := w.LoadWallet()
+	if err != nil {
+		return fmt.Errorf("error loading wallet: %w", err)
+	}
+
+	chainID, err := w.Blockchain.GetChainID()
+	if err != nil {
+		return err
+	}
+
+	var req *eip681.Request
+	if *requestToken == "" {
+		var value *big.Int
+		if flag.NArg() > 0 {
+			if value, err = crypto.ParseUnits(flag.Arg(0), 18); err != nil {
+				return fmt.Errorf("invalid ETH amount: %w", err)
+			}
+		}
+		req = eip681.NewPayment(w.KeyPair.Address, chainID, value)
+	} else {
+		token, err := resolveBookAddress(w, *requestToken, chainID)
+		if err != nil {
+			return fmt.Errorf("invalid token: %w", err)
+		}
+
+		var amount *big.Int
+		if flag.NArg() > 0 {
+			info := lookupToken(w, make(map[common.Address]*tokenInfo), token)
+			if !info.known {
+				return fmt.Errorf("%s is not an ERC-20 token on chain %s", token.Hex(), chainID)
+			}
+			if amount, err = crypto.ParseUnits(flag.Arg(0), info.decimals); err != nil {
+				return fmt.Errorf("invalid token amount: %w", err)
+			}
+		}
+		req = eip681.NewTokenPayment(token, chainID, w.KeyPair.Address, amount)
+	}
+
+	uri := req.String()
+	fmt.Println(uri)
+
+	if *requestPNG != "" {
+		if err := qrcode.WriteFile(uri, qrcode.Medium, 512, *requestPNG); err != nil {
+			return fmt.Errorf("error writing QR code: %w", err)
+		}
+		fmt.Printf("QR code saved to %s\n", *requestPNG)
+		return nil
+	}
+
+	code, err := qrcode.New(uri, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("error generating QR code: %w", err)
+	}
+	fmt.Print(code.ToSmallString(false))
+
+	return nil
+}
+
+func handlePay(w *wallet.Wallet) error {
+	if flag.NArg() < 1 {
+		return fmt.Errorf("usage: pay <ethereum:uri>")
+	}
+
+	req, err := eip681.Parse(flag.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid payment request: %w", err)
+	}
+
+	err = w.LoadWallet()
+	if err != nil {
+		return fmt.Errorf("error loading wallet: %w", err)
+	}
+
+	chainID, err := w.Blockchain.GetChainID()
+	if err != nil {
+		return err
+	}
+	if req.ChainID != nil && req.ChainID.Cmp(chainID) != 0 {
+		return fmt.Errorf("payment request is for chain %s, but the wallet is connected to chain %s", req.ChainID, chainID)
+	}
+
+	if req.Value == nil || req.Value.Sign() <= 0 {
+		return fmt.Errorf("payment request has no amount")
+	}
+
+	target, err := w.ResolveAddress(req.Target)
+	if err != nil {
+		return err
+	}
+
+	txReq := wallet.TxRequest{
+		To:       target,
+		Value:    req.Value,
+		GasLimit: req.GasLimit,
+		GasPrice: req.GasPrice,
+	}
+	recipient := target
+	amount := crypto.FormatUnits(req.Value, 18) + " ETH"
+
+	if req.IsToken() {
+		if recipient, err = w.ResolveAddress(req.Recipient); err != nil {
+			return err
+		}
+
+		info := lookupToken(w, make(map[common.Address]*tokenInfo), target)
+		if !info.known {
+			return fmt.Errorf("%s is not an ERC-20 token on chain %s", target.Hex(), chainID)
+		}
+
+		data, err := blockchain.PackTokenTransfer(recipient, req.Value)
+		if err != nil {
+			return err
+		}
+
+		txReq.Value = nil
+		txReq.Data = data
+		amount = info.format(req.Value)
+	}
+
+	book, err := loadAddressBook(w)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Payment request: %s to %s on chain %s\n", amount, recipient.Hex(), chainID)
+	if req.GasPrice != nil {
+		fmt.Printf("Gas price set by the request: %s\n", formatGwei(req.GasPrice))
+	}
+	if req.GasLimit != 0 {
+		fmt.Printf("Gas limit set by the request: %d\n", req.GasLimit)
+	}
+	if req.GasPrice != nil && req.GasLimit != 0 {
+		fee := new(big.Int).Mul(req.GasPrice, new(big.Int).SetUint64(req.GasLimit))
+		fmt.Printf("Max network fee: %s ETH\n", crypto.FormatUnits(fee, 18))
+	}
+	if err := confirmRecipient(book, recipient); err != nil {
+		return err
+	}
+	if !*assumeYes && !confirm("Pay?") {
+		return fmt.Errorf("payment cancelled")
+	}
+
+	txHash, err := w.Send(txReq)
+	if err != nil {
+		return fmt.Errorf("error sending transaction: %w", err)
+	}
+	recordRecipient(book, recipient)
+
+	fmt.Printf("Transaction sent!\n")
+	fmt.Printf("Transaction hash: %s\n", txHash)
+
+	return nil
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/safe"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	callDataHex = flag.String("data", "", "safe propose/userop send: hex call data for the target")
+	safeOut     = flag.String("out", "", "safe propose/sign: file to write the proposal to")
+)
+
+type callSpec struct {
+	to        common.Address
+	value     *big.Int
+	data      []byte
+	amount    string
+	recipient string
+}
+
+func parseCallArgs(w *wallet.Wallet, chainID *big.Int, toArg, amountArg string) (*callSpec, error) {
+	book, err := loadAddressBook(w)
+	if err != nil {
+		return nil, err
+	}
+
+	call := &callSpec{}
+	if call.to, call.recipient, err = resolveAddress(w, book, toArg, chainID); err != nil {
+		return nil, fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	if *callDataHex != "" {
+		if call.data, err = hexutil.Decode(*callDataHex); err != nil {
+			return nil, fmt.Errorf("invalid -data: %w", err)
+		}
+	}
+
+	if *requestToken == "" {
+		if call.value, err = crypto.ParseUnits(amountArg, 18); err != nil {
+			return nil, fmt.Errorf("invalid ETH amount: %w", err)
+		}
+		call.amount = crypto.FormatUnits(call.value, 18) + " ETH"
+		return call, nil
+	}
+
+	if call.data != nil {
+		return nil, fmt.Errorf("-token and -data cannot be used together")
+	}
+
+	token, _, err := resolveAddress(w, book, *requestToken, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	info := lookupToken(w, make(map[common.Address]*tokenInfo), token)
+	if !info.known {
+		return nil, fmt.Errorf("%s is not an ERC-20 token on chain %s", token.Hex(), chainID)
+	}
+
+	units, err := crypto.ParseUnits(amountArg, info.decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+	if call.data, err = blockchain.PackTokenTransfer(call.to, units); err != nil {
+		return nil, err
+	}
+	call.amount = info.format(units)
+	call.to = token
+	return call, nil
+}
+
+func handleSafe(w *wallet.Wallet) error {
+	if flag.NArg() < 2 {
+		return fmt.Errorf("usage: safe <info|propose|sign|exec> ...")
+	}
+
+	switch flag.Arg(0) {
+	case "info":
+		return handleSafeInfo(w)
+	case "propose":
+		return handleSafePropose(w)
+	case "sign":
+		return handleSafeSign(w)
+	case "exec":
+		return handleSafeExec(w)
+	default:
+		return fmt.Errorf("unknown safe command: %s", flag.Arg(0))
+	}
+}
+
+func handleSafeInfo(w *wallet.Wallet) error {
+	address, err := resolveBookAddress(w, flag.Arg(1), nil)
+	if err != nil {
+		return fmt.Errorf("invalid Safe address: %w", err)
+	}
+
+	info, err := w.Blockchain.GetSafeInfo(address)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Safe:      %s (v%s)\n", info.Address.Hex(), info.Version)
+	fmt.Printf("Nonce:     %s\n", info.Nonce)
+	fmt.Printf("Threshold: %d of %d\n", info.Threshold, len(info.Owners))
+	names := newENSNames(w)
+	for _, owner := range info.Owners {
+		fmt.Printf("  %s\n", names.format(owner))
+	}
+
+	if w.LoadWallet() == nil && info.IsOwner(w.KeyPair.Address) {
+		fmt.Println("This wallet is an owner")
+	}
+	return nil
+}
+
+func handleSafePropose(w *wallet.Wallet) error {
+	if flag.NArg() < 4 {
+		return fmt.Errorf("usage: safe propose [-token <address>] [-data <hex>] [-out <file>] <safe> <to> <amount>")
+	}
+
+	err := w.LoadWallet()
+	if err != nil {
+		return fmt.Errorf("error loading wallet: %w", err)
+	}
+
+	address, err := resolveBookAddress(w, flag.Arg(1), nil)
+	if err != nil {
+		return fmt.Errorf("invalid Safe address: %w", err)
+	}
+
+	info, err := w.Blockchain.GetSafeInfo(address)
+	if err != nil {
+		return err
+	}
+
+	chainID, err := w.Blockchain.GetChainID()
+	if err != nil {
+		return err
+	}
+
+	call, err := parseCallArgs(w, chainID, flag.Arg(2), flag.Arg(3))
+	if err != nil {
+		return err
+	}
+
+	proposal, err := safe.NewProposal(safe.NewTransaction(info, chainID, call.to, call.value, call.data))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Safe transaction #%s from %s: %s to %s\n", proposal.Nonce, info.Address.Hex(), call.amount, call.recipient)
+	if len(call.data) > 0 {
+		fmt.Printf("Call data: %d bytes to %s\n", len(call.data), proposal.To.Hex())
+	}
+	fmt.Printf("SafeTxHash: %s\n", proposal.Hash.Hex())
+
+	if info.IsOwner(w.KeyPair.Address) {
+		if err := signSafeProposal(w, proposal); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println("This wallet is not an owner, the proposal is saved unsigned")
+	}
+
+	path := *safeOut
+	if path == "" {
+		path = fmt.Sprintf("safe-%s-%s.json", proposal.Nonce, proposal.Hash.Hex()[2:10])
+	}
+	if err := proposal.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Proposal saved to %s (%d of %d signatures)\n", path, len(proposal.Signatures), info.Threshold)
+	return nil
+}
+
+func signSafeProposal(w *wallet.Wallet, proposal *safe.Proposal) error {
+	if !*assumeYes && !confirm(fmt.Sprintf("Sign as owner %s?", w.KeyPair.Address.Hex())) {
+		return fmt.Errorf("signing cancelled")
+	}
+
+	signature, err := w.SignHash(proposal.Hash)
+	if err != nil {
+		return fmt.Errorf("error signing Safe transaction: %w", err)
+	}
+
+	added, err := proposal.AddSignature(safe.Signature{Signer: w.KeyPair.Address, Signature: signature})
+	if err != nil {
+		return err
+	}
+	if added {
+		fmt.Printf("Signed by %s\n", w.KeyPair.Address.Hex())
+	} else {
+		fmt.Printf("Already signed by %s\n", w.KeyPair.Address.Hex())
+	}
+	return nil
+}
+
+func printSafeProposal(proposal *safe.Proposal) {
+	fmt.Printf("Safe transaction #%s: %s wei to %s, %d bytes of data\n", proposal.Nonce, proposal.Value, proposal.To.Hex(), len(proposal.Data))
+	fmt.Printf("SafeTxHash: %s\n", proposal.Hash.Hex())
+	if proposal.Operation == safe.OperationDelegateCall {
+		fmt.Println("Warning: this is a DELEGATECALL, the target runs with the Safe's storage")
+	}
+}
+
+func loadSafeProposal(w *wallet.Wallet, path string) (*safe.Proposal, *blockchain.SafeInfo, error) {
+	proposal, err := safe.LoadProposal(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chainID, err := w.Blockchain.GetChainID()
+	if err != nil {
+		return nil, nil, err
+	}
+	if proposal.ChainID.Cmp(chainID) != 0 {
+		return nil, nil, fmt.Errorf("proposal is for chain %s, but the wallet is connected to chain %s", proposal.ChainID, chainID)
+	}
+
+	info, err := w.Blockchain.GetSafeInfo(proposal.Safe)
+	if err != nil {
+		return nil, nil, err
+	}
+	if proposal.Nonce.Cmp(info.Nonce) < 0 {
+		return nil, nil, fmt.Errorf("Safe nonce %s has already been used (current nonce %s)", proposal.Nonce, info.Nonce)
+	}
+
+	return proposal, info, nil
+}
+
+func handleSafeSign(w *wallet.Wallet) error {
+	err := w.LoadWallet()
+	if err != nil {
+		return fmt.Errorf("error loading wallet: %w", err)
+	}
+
+	proposal, info, err := loadSafeProposal(w, flag.Arg(1))
+	if err != nil {
+		return err
+	}
+	if !info.IsOwner(w.KeyPair.Address) {
+		return fmt.Errorf("%s is not an owner of Safe %s", w.KeyPair.Address.Hex(), info.Address.Hex())
+	}
+
+	printSafeProposal(proposal)
+	if err := signSafeProposal(w, proposal); err != nil {
+		return err
+	}
+
+	path := *safeOut
+	if path == "" {
+		path = flag.Arg(1)
+	}
+	if err := proposal.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Proposal saved to %s (%d of %d signatures)\n", path, len(proposal.Signatures), info.Threshold)
+	return nil
+}
+
+func handleSafeExec(w *wallet.Wallet) error {
+	err := w.LoadWallet()
+	if err != nil {
+		return fmt.Errorf("error loading wallet: %w", err)
+	}
+
+	proposal, info, err := loadSafeProposal(w, flag.Arg(1))
+	if err != nil {
+		return err
+	}
+	if proposal.Nonce.Cmp(info.Nonce) != 0 {
+		return fmt.Errorf("Safe is at nonce %s, transaction #%s has to wait", info.Nonce, proposal.Nonce)
+	}
+
+	for _, path := range flag.Args()[2:] {
+		other, err := safe.LoadProposal(path)
+		if err != nil {
+			return err
+		}
+		added, err := proposal.Merge(other)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		fmt.Printf("%s: %d new signatures\n", path, added)
+	}
+
+	signatures, err := proposal.EncodeSignatures(info)
+	if err != nil && info.IsOwner(w.KeyPair.Address) {
+		printSafeProposal(proposal)
+		if err := signSafeProposal(w, proposal); err != nil {
+			return err
+		}
+		signatures, err = proposal.EncodeSignatures(info)
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := blockchain.PackSafeExec(proposal.Exec(signatures))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Executing Safe transaction #%s (%s) with %d signatures\n", proposal.Nonce, proposal.Hash.Hex(), len(signatures)/65)
+	if !*assumeYes && !confirm("Execute?") {
+		return fmt.Errorf("execution cancelled")
+	}
+
+	txHash, err := w.Send(wallet.TxRequest{To: proposal.Safe, Data: data, Force: *forceSend})
+	if err != nil {
+		return fmt.Errorf("error sending transaction: %w", err)
+	}
+
+	fmt.Printf("Transaction sent!\n")
+	fmt.Printf("Transaction hash: %s\n", txHash)
+	fmt.Printf("Check status: ./crypto-wallet status %s\n", txHash)
+
+	return nil
+}
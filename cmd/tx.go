@@ -0,0 +1,166 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var selectorsFile = flag.String("selectors", "", "tx decode: 4-byte selector database (default: <wallet>.selectors.json)")
+
+func handleTx(w *wallet.Wallet) error {
+	if flag.NArg() < 2 || flag.Arg(0) != "decode" {
+		return fmt.Errorf("usage: tx decode [-abi <file>[,<file>...]] [-selectors <file>] <hex|file>")
+	}
+
+	raw, err := readRawTransaction(flag.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	decoded, err := blockchain.DecodeRawTransaction(raw)
+	if err != nil {
+		return err
+	}
+
+	decoder, err := loadCallDecoder(w)
+	if err != nil {
+		return err
+	}
+
+	printRawTransaction(decoded)
+
+	tx := decoded.Tx
+	if len(tx.Data()) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\nCall data (%d bytes): %s\n", len(tx.Data()), hexutil.Encode(tx.Data()))
+	if tx.To() == nil {
+		fmt.Println("Contract creation, the call data is init code")
+		return nil
+	}
+
+	call, ok := decoder.Decode(tx.Data())
+	if !ok {
+		if len(tx.Data()) >= 4 {
+			fmt.Printf("Unknown method %s\n", hexutil.Encode(tx.Data()[:4]))
+		}
+		return nil
+	}
+
+	fmt.Printf("Method: %s (%s, from %s)\n", call.Signature, hexutil.Encode(call.Selector[:]), call.Source)
+	for i, arg := range call.Args {
+		name := arg.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		fmt.Printf("  %s %s = %s\n", arg.Type, name, arg.Value)
+	}
+
+	return nil
+}
+
+func readRawTransaction(input string) ([]byte, error) {
+	if _, err := os.Stat(input); err == nil {
+		content, err := os.ReadFile(input)
+		if err != nil {
+			return nil, fmt.Errorf("error reading transaction file: %w", err)
+		}
+
+		text := strings.TrimSpace(string(content))
+		if raw, err := hexutil.Decode(ensureHexPrefix(text)); err == nil {
+			return raw, nil
+		}
+		return content, nil
+	}
+
+	raw, err := hexutil.Decode(ensureHexPrefix(strings.TrimSpace(input)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid raw transaction: not a file or hex string")
+	}
+	return raw, nil
+}
+
+func ensureHexPrefix(input string) string {
+	if strings.HasPrefix(input, "0x") || strings.HasPrefix(input, "0X") {
+		return input
+	}
+	return "0x" + input
+}
+
+func loadCallDecoder(w *wallet.Wallet) (*blockchain.CallDecoder, error) {
+	decoder := &blockchain.CallDecoder{}
+
+	if *abiFile != "" {
+		for _, path := range strings.Split(*abiFile, ",") {
+			contractABI, err := blockchain.LoadABI(strings.TrimSpace(path))
+			if err != nil {
+				return nil, err
+			}
+			decoder.ABIs = append(decoder.ABIs, contractABI)
+		}
+	}
+
+	path := *selectorsFile
+	if path == "" {
+		path = w.SidecarPath("selectors.json")
+	}
+
+	selectors, err := blockchain.LoadSelectorDB(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder.Selectors = selectors
+
+	return decoder, nil
+}
+
+func printRawTransaction(decoded *blockchain.RawTransaction) {
+	tx := decoded.Tx
+
+	fmt.Printf("Hash:      %s\n", tx.Hash().Hex())
+	fmt.Printf("Type:      %s\n", decoded.TypeName())
+	if tx.Type() == types.LegacyTxType && !tx.Protected() {
+		fmt.Printf("Chain ID:  none, the transaction can be replayed on any chain\n")
+	} else {
+		fmt.Printf("Chain ID:  %s\n", tx.ChainId())
+	}
+	fmt.Printf("From:      %s\n", decoded.From.Hex())
+	if tx.To() != nil {
+		fmt.Printf("To:        %s\n", tx.To().Hex())
+	} else {
+		fmt.Printf("To:        contract creation\n")
+	}
+	fmt.Printf("Nonce:     %d\n", tx.Nonce())
+	fmt.Printf("Value:     %s ETH\n", crypto.FormatUnits(tx.Value(), 18))
+	fmt.Printf("Gas limit: %d\n", tx.Gas())
+
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		fmt.Printf("Gas price: %s\n", formatGwei(tx.GasPrice()))
+	default:
+		fmt.Printf("Max fee:   %s\n", formatGwei(tx.GasFeeCap()))
+		fmt.Printf("Tip:       %s\n", formatGwei(tx.GasTipCap()))
+	}
+	if tx.Type() == types.BlobTxType {
+		fmt.Printf("Blob fee:  %s, %d blobs\n", formatGwei(tx.BlobGasFeeCap()), len(tx.BlobHashes()))
+	}
+	fmt.Printf("Max cost:  %s ETH\n", crypto.FormatUnits(tx.Cost(), 18))
+
+	if list := tx.AccessList(); len(list) > 0 {
+		keys := 0
+		for _, tuple := range list {
+			keys += len(tuple.StorageKeys)
+		}
+		fmt.Printf("Access list: %d addresses, %d storage keys\n", len(list), keys)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/gasoracle"
+	"crypto-wallet/internal/policy"
+	"crypto-wallet/internal/userop"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	bundlerURL      = flag.String("bundler", "", "userop: bundler JSON-RPC URL (default: the -url node)")
+	entryPointFlag  = flag.String("entrypoint", userop.Version07, "userop: EntryPoint version (0.6 or 0.7) or address")
+	factoryFlag     = flag.String("factory", "", "userop send: account factory for an account that is not deployed yet")
+	factoryDataFlag = flag.String("factory-data", "", "userop send: hex call data for the account factory")
+)
+
+func handleUserOp(w *wallet.Wallet) error {
+	if flag.NArg() < 2 {
+		return fmt.Errorf("usage: userop <send|receipt> ...")
+	}
+
+	entryPoint, err := userop.ParseEntryPoint(*entryPointFlag)
+	if err != nil {
+		return err
+	}
+
+	url := *bundlerURL
+	if url == "" {
+		url = w.Blockchain.URL()
+	}
+	bundler, err := userop.NewBundler(url)
+	if err != nil {
+		return err
+	}
+	defer bundler.Close()
+
+	switch flag.Arg(0) {
+	case "send":
+		return handleUserOpSend(w, bundler, entryPoint)
+	case "receipt":
+		hash, err := parseUserOpHash(flag.Arg(1))
+		if err != nil {
+			return err
+		}
+		receipt, err := bundler.GetReceipt(hash)
+		if err != nil {
+			return err
+		}
+		if receipt == nil {
+			fmt.Printf("UserOperation %s is not included yet\n", hash.Hex())
+			return nil
+		}
+		printUserOpReceipt(receipt)
+		return nil
+	default:
+		return fmt.Errorf("unknown userop command: %s", flag.Arg(0))
+	}
+}
+
+func parseUserOpHash(input string) (common.Hash, error) {
+	raw, err := hexutil.Decode(input)
+	if err != nil || len(raw) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid UserOperation hash: %s", input)
+	}
+	return common.BytesToHash(raw), nil
+}
+
+func handleUserOpSend(w *wallet.Wallet, bundler *userop.Bundler, entryPoint userop.EntryPoint) error {
+	if flag.NArg() < 4 {
+		return fmt.Errorf("usage: userop send [-entrypoint 0.6|0.7] [-bundler <url>] [-token <address>] [-data <hex>] <account> <to> <amount>")
+	}
+
+	err := w.LoadWallet()
+	if err != nil {
+		return fmt.Errorf("error loading wallet: %w", err)
+	}
+
+	if err := bundler.CheckEntryPoint(entryPoint); err != nil {
+		return err
+	}
+
+	account, err := resolveBookAddress(w, flag.Arg(1), nil)
+	if err != nil {
+		return fmt.Errorf("invalid account: %w", err)
+	}
+
+	chainID, err := w.Blockchain.GetChainID()
+	if err != nil {
+		return err
+	}
+
+	call, err := parseCallArgs(w, chainID, flag.Arg(2), flag.Arg(3))
+	if err != nil {
+		return err
+	}
+
+	op := &userop.UserOperation{Sender: account}
+	if op.CallData, err = blockchain.PackAccountExecute(call.to, call.value, call.data); err != nil {
+		return err
+	}
+
+	if err := setUserOpFactory(w, op); err != nil {
+		return err
+	}
+
+	if op.Nonce, err = w.Blockchain.GetEntryPointNonce(entryPoint.Address, account, nil); err != nil {
+		return err
+	}
+
+	if err := setUserOpFees(w, op); err != nil {
+		return err
+	}
+
+	op.Signature = userop.DummySignature
+	estimate, err := bundler.EstimateGas(op, entryPoint)
+	if err != nil {
+		return fmt.Errorf("error estimating UserOperation gas: %w", err)
+	}
+	estimate.Apply(op)
+
+	gas := new(big.Int).Add(op.CallGasLimit, op.VerificationGasLimit)
+	gas.Add(gas, op.PreVerificationGas)
+	maxCost := new(big.Int).Mul(gas, op.MaxFeePerGas)
+
+	fmt.Printf("UserOperation from %s (EntryPoint v%s): %s to %s\n", account.Hex(), entryPoint.Version, call.amount, call.recipient)
+	if len(call.data) > 0 {
+		fmt.Printf("Call data: %d bytes to %s\n", len(call.data), call.to.Hex())
+	}
+	if op.Factory != nil {
+		fmt.Printf("Account is deployed by factory %s\n", op.Factory.Hex())
+	}
+	fmt.Printf("Nonce:     %s\n", op.Nonce)
+	fmt.Printf("Gas:       call %s, verification %s, pre-verification %s\n", op.CallGasLimit, op.VerificationGasLimit, op.PreVerificationGas)
+	fmt.Printf("Max cost:  %s ETH\n", crypto.FormatUnits(maxCost, 18))
+
+	spend := policy.Transaction{To: call.to, Value: call.value, Data: call.data, GasPrice: op.MaxFeePerGas}
+	if err := w.CheckPolicy(spend); err != nil {
+		return err
+	}
+
+	if !*assumeYes && !confirm("Send?") {
+		return fmt.Errorf("UserOperation cancelled")
+	}
+
+	if w.Locked() {
+		return wallet.ErrWalletLocked
+	}
+	if _, err := op.Sign(w.KeyPair, entryPoint, chainID); err != nil {
+		return err
+	}
+
+	hash, err := bundler.Send(op, entryPoint)
+	if err != nil {
+		return err
+	}
+
+	spend.Hash = hash
+	if err := w.RecordPolicy(spend); err != nil {
+		return err
+	}
+
+	fmt.Printf("UserOperation sent!\n")
+	fmt.Printf("UserOperation hash: %s\n", hash.Hex())
+	fmt.Printf("Waiting for inclusion (up to %s)...\n", *waitTimeout)
+
+	receipt, err := bundler.WaitForReceipt(hash, *waitTimeout)
+	if err != nil {
+		return err
+	}
+	printUserOpReceipt(receipt)
+
+	if !receipt.Success {
+		return fmt.Errorf("UserOperation reverted")
+	}
+
+	return nil
+}
+
+func setUserOpFactory(w *wallet.Wallet, op *userop.UserOperation) error {
+	code, err := w.Blockchain.GetCode(op.Sender)
+	if err != nil {
+		return err
+	}
+
+	if len(code) > 0 {
+		if *factoryFlag != "" {
+			return fmt.Errorf("account %s is already deployed, -factory is not needed", op.Sender.Hex())
+		}
+		return nil
+	}
+
+	if *factoryFlag == "" {
+		return fmt.Errorf("account %s is not deployed: pass -factory and -factory-data to deploy it", op.Sender.Hex())
+	}
+
+	factory, err := resolveBookAddress(w, *factoryFlag, nil)
+	if err != nil {
+		return fmt.Errorf("invalid factory: %w", err)
+	}
+	if op.FactoryData, err = hexutil.Decode(*factoryDataFlag); err != nil {
+		return fmt.Errorf("invalid -factory-data: %w", err)
+	}
+	op.Factory = &factory
+	return nil
+}
+
+func setUserOpFees(w *wallet.Wallet, op *userop.UserOperation) error {
+	fee, err := w.FeeOracle.Fee(w.FeeTier)
+	if err == nil {
+		op.MaxPriorityFeePerGas = fee.Tip
+		op.MaxFeePerGas = fee.MaxFee
+		return nil
+	}
+	if !errors.Is(err, gasoracle.ErrNoFeeMarket) {
+		return err
+	}
+
+	gasPrice, err := w.Blockchain.GetGasPrice()
+	if err != nil {
+		return err
+	}
+	if err := w.FeeOracle.CheckCeiling(gasPrice); err != nil {
+		return err
+	}
+
+	op.MaxPriorityFeePerGas = gasPrice
+	op.MaxFeePerGas = gasPrice
+	return nil
+}
+
+func printUserOpReceipt(receipt *userop.Receipt) {
+	status := "success"
+	if !receipt.Success {
+		status = "reverted"
+		if receipt.Reason != "" {
+			status += ": " + receipt.Reason
+		}
+	}
+
+	fmt.Printf("Status:           %s\n", status)
+	fmt.Printf("Transaction hash: %s\n", receipt.Receipt.TransactionHash.Hex())
+	if receipt.ActualGasCost != nil {
+		fmt.Printf("Gas cost:         %s ETH\n", crypto.FormatUnits(receipt.ActualGasCost.ToInt(), 18))
+	}
+}
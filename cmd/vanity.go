@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"time"
+
+	"crypto-wallet/internal/vanity"
+	"crypto-wallet/internal/wallet"
+)
+
+var (
+	vanityPrefix        = flag.String("prefix", "", "vanity: address prefix (hex)")
+	vanitySuffix        = flag.String("suffix", "", "vanity: address suffix (hex)")
+	vanityRegex         = flag.String("regex", "", "vanity: regular expression the address must match")
+	vanityCaseSensitive = flag.Bool("case-sensitive", false, "vanity: match the EIP-55 checksum casing")
+	vanityWorkers       = flag.Int("workers", runtime.NumCPU(), "vanity: number of parallel workers")
+)
+
+func handleVanity(w *wallet.Wallet) error {
+	pattern, err := vanity.NewPattern(*vanityPrefix, *vanitySuffix, *vanityRegex, *vanityCaseSensitive)
+	if err != nil {
+		return err
+	}
+
+	if difficulty := pattern.Difficulty(); difficulty > 0 {
+		fmt.Printf("Difficulty: 1 in %.0f, 50%% chance after %.0f attempts\n", difficulty, pattern.ExpectedAttempts(0.5))
+	} else {
+		fmt.Println("Difficulty of a regular expression cannot be estimated")
+	}
+	fmt.Printf("Searching with %d workers, press Ctrl+C to stop...\n", *vanityWorkers)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	keyPair, attempts, err := vanity.Search(ctx, pattern, *vanityWorkers, 5*time.Second, func(p vanity.Progress) {
+		fmt.Printf("%d attempts, %.0f/s, %s elapsed", p.Attempts, p.Rate, p.Elapsed.Truncate(time.Second))
+		if p.Expected > 0 {
+			fmt.Printf(", 50%% chance after %s", p.Expected.Truncate(time.Second))
+		}
+		fmt.Println()
+	})
+	if err != nil {
+		return fmt.Errorf("search stopped after %d attempts: %w", attempts, err)
+	}
+
+	fmt.Printf("Found %s after %d attempts\n", keyPair.Address.Hex(), attempts)
+
+	written, err := installKey(w, keyPair)
+	if err != nil || !written {
+		return err
+	}
+
+	fmt.Printf("Data saved to file: %s\n", w.WalletFile)
+	fmt.Println("\nIMPORTANT: Save private key in a secure location!")
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethereumCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+var verifyHash = flag.Bool("hash", false, "verify: treat the message as a 32-byte hex hash")
+
+func handleVerify(w *wallet.Wallet) error {
+	if flag.NArg() < 3 {
+		return fmt.Errorf("usage: verify [-hash] <address> <message> <signature>")
+	}
+
+	signer, err := resolveBookAddress(w, flag.Arg(0), nil)
+	if err != nil {
+		return fmt.Errorf("invalid signer: %w", err)
+	}
+
+	hash := ethereumCrypto.Keccak256Hash([]byte(flag.Arg(1)))
+	if *verifyHash {
+		raw, err := hexutil.Decode(flag.Arg(1))
+		if err != nil || len(raw) != common.HashLength {
+			return fmt.Errorf("invalid hash: expected 32 bytes of hex")
+		}
+		hash = common.BytesToHash(raw)
+	}
+
+	signature, err := hexutil.Decode(flag.Arg(2))
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	method, err := w.VerifyHash(hash, signature, signer)
+	if err != nil {
+		return fmt.Errorf("error verifying signature: %w", err)
+	}
+
+	fmt.Printf("Signer: %s\n", newENSNames(w).format(signer))
+	fmt.Printf("Hash:   %s\n", hash.Hex())
+	if method == wallet.SignatureInvalid {
+		return fmt.Errorf("signature is not valid for %s", signer.Hex())
+	}
+
+	fmt.Printf("Signature is valid (%s)\n", method)
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+
+	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/wallet"
+	"crypto-wallet/internal/watcher"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var watchFromBlock = flag.Int64("from-block", -1, "First block to scan when no checkpoint exists")
+
+func handleWatch(w *wallet.Wallet) error {
+	err := w.LoadWallet()
+	if err != nil {
+		return fmt.Errorf("error loading wallet: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	watch := &watcher.Watcher{
+		Client:         w.Blockchain,
+		Addresses:      []common.Address{w.KeyPair.Address},
+		CheckpointPath: w.SidecarPath("watch.json"),
+	}
+
+	if *watchFromBlock >= 0 {
+		start := uint64(*watchFromBlock)
+		watch.StartBlock = &start
+	}
+
+	mode := "polling"
+	if w.Blockchain.IsWebSocket() {
+		mode = "subscription"
+	}
+	fmt.Printf("Watching %s for incoming payments (%s, Ctrl+C to stop)...\n", w.KeyPair.GetAddressHex(), mode)
+
+	tokens := make(map[common.Address]*tokenInfo)
+
+	return watch.Run(ctx, func(payment watcher.Payment) {
+		amount := crypto.FormatUnits(payment.Value, 18) + " ETH"
+		if payment.Token != nil {
+			amount = lookupToken(w, tokens, *payment.Token).format(payment.Value)
+		}
+
+		fmt.Printf("Block %d: received %s from %s (tx %s)\n",
+			payment.BlockNumber, amount, payment.From.Hex(), payment.TxHash.Hex())
+	})
+}
+
+type tokenInfo struct {
+	address  common.Address
+	symbol   string
+	decimals int
+	known    bool
+}
+
+func lookupToken(w *wallet.Wallet, cache map[common.Address]*tokenInfo, token common.Address) *tokenInfo {
+	if info, ok := cache[token]; ok {
+		return info
+	}
+
+	info := &tokenInfo{address: token, symbol: token.Hex()}
+
+	decimals, err := w.Blockchain.GetTokenDecimals(token)
+	if err == nil {
+		info.decimals = int(decimals)
+		info.known = true
+	}
+
+	if symbol, err := w.Blockchain.GetTokenSymbol(token); err == nil && symbol != "" {
+		info.symbol = symbol
+	}
+
+	cache[token] = info
+	return info
+}
+
+func (t *tokenInfo) format(value *big.Int) string {
+	if !t.known {
+		return fmt.Sprintf("%s units of token %s", value, t.address.Hex())
+	}
+	return crypto.FormatUnits(value, t.decimals) + " " + t.symbol
+}
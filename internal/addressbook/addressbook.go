@@ -0,0 +1,216 @@
+package addressbook
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-wallet/internal/crypto"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type Entry struct {
+	Name     string         `json:"name"`
+	Address  common.Address `json:"address"`
+	Notes    string         `json:"notes,omitempty"`
+	ChainIDs []uint64       `json:"chain_ids,omitempty"`
+}
+
+type Book struct {
+	path       string
+	Entries    []Entry                      `json:"entries"`
+	Recipients map[common.Address]time.Time `json:"recipients,omitempty"`
+}
+
+func Load(path string) (*Book, error) {
+	book := &Book{path: path, Recipients: make(map[common.Address]time.Time)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return book, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading address book: %w", err)
+	}
+
+	book, err = Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	book.path = path
+
+	return book, nil
+}
+
+func Parse(data []byte) (*Book, error) {
+	book := &Book{}
+	if err := json.Unmarshal(data, book); err != nil {
+		return nil, fmt.Errorf("error parsing address book: %w", err)
+	}
+	if book.Recipients == nil {
+		book.Recipients = make(map[common.Address]time.Time)
+	}
+
+	return book, nil
+}
+
+func (b *Book) Save() error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing address book: %w", err)
+	}
+
+	tmp := b.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("error writing address book: %w", err)
+	}
+
+	if err := os.Rename(tmp, b.path); err != nil {
+		return fmt.Errorf("error writing address book: %w", err)
+	}
+
+	return nil
+}
+
+func (b *Book) Merge(other *Book) []string {
+	var conflicts []string
+
+	for _, entry := range other.Entries {
+		existing := b.Lookup(entry.Name)
+		switch {
+		case existing == nil:
+			b.Entries = append(b.Entries, entry)
+		case existing.Address != entry.Address:
+			conflicts = append(conflicts, fmt.Sprintf("nickname %s is %s here but %s in the import",
+				entry.Name, existing.Address.Hex(), entry.Address.Hex()))
+		}
+	}
+
+	b.sortEntries()
+
+	for address, usedAt := range other.Recipients {
+		if usedAt.After(b.Recipients[address]) {
+			b.Recipients[address] = usedAt
+		}
+	}
+
+	return conflicts
+}
+
+func (b *Book) Add(entry Entry) error {
+	if err := validateName(entry.Name); err != nil {
+		return err
+	}
+
+	if existing := b.Lookup(entry.Name); existing != nil {
+		return fmt.Errorf("nickname %q is already used for %s", existing.Name, existing.Address.Hex())
+	}
+
+	b.Entries = append(b.Entries, entry)
+	b.sortEntries()
+
+	return nil
+}
+
+func (b *Book) sortEntries() {
+	sort.Slice(b.Entries, func(i, j int) bool {
+		return strings.ToLower(b.Entries[i].Name) < strings.ToLower(b.Entries[j].Name)
+	})
+}
+
+func (b *Book) Remove(name string) error {
+	for i, entry := range b.Entries {
+		if strings.EqualFold(entry.Name, name) {
+			b.Entries = append(b.Entries[:i], b.Entries[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no address book entry named %q", name)
+}
+
+func (b *Book) Lookup(name string) *Entry {
+	for i := range b.Entries {
+		if strings.EqualFold(b.Entries[i].Name, name) {
+			return &b.Entries[i]
+		}
+	}
+	return nil
+}
+
+func (b *Book) LookupAddress(address common.Address) *Entry {
+	for i := range b.Entries {
+		if b.Entries[i].Address == address {
+			return &b.Entries[i]
+		}
+	}
+	return nil
+}
+
+func (b *Book) Resolve(input string, chainID *big.Int) (common.Address, *Entry, error) {
+	if common.IsHexAddress(input) {
+		address, err := crypto.HexToAddress(input)
+		if err != nil {
+			return common.Address{}, nil, err
+		}
+
+		entry := b.LookupAddress(address)
+		if entry != nil && !entry.AllowsChain(chainID) {
+			return common.Address{}, nil, fmt.Errorf("%s (%s) is not allowed on chain %s", entry.Name, address.Hex(), chainID)
+		}
+		return address, entry, nil
+	}
+
+	entry := b.Lookup(input)
+	if entry == nil {
+		return common.Address{}, nil, fmt.Errorf("%q is neither an address nor an address book entry", input)
+	}
+
+	if !entry.AllowsChain(chainID) {
+		return common.Address{}, nil, fmt.Errorf("%s is not allowed on chain %s", entry.Name, chainID)
+	}
+
+	return entry.Address, entry, nil
+}
+
+func (b *Book) Known(address common.Address) bool {
+	if _, ok := b.Recipients[address]; ok {
+		return true
+	}
+	return b.LookupAddress(address) != nil
+}
+
+func (b *Book) MarkUsed(address common.Address, at time.Time) {
+	b.Recipients[address] = at
+}
+
+func (e *Entry) AllowsChain(chainID *big.Int) bool {
+	if len(e.ChainIDs) == 0 || chainID == nil {
+		return true
+	}
+
+	for _, id := range e.ChainIDs {
+		if chainID.IsUint64() && chainID.Uint64() == id {
+			return true
+		}
+	}
+	return false
+}
+
+func validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("nickname must not be empty")
+	}
+	if common.IsHexAddress(name) || strings.HasPrefix(strings.ToLower(name), "0x") {
+		return fmt.Errorf("nickname %q looks like an address", name)
+	}
+	if strings.ContainsAny(name, " ,\t") {
+		return fmt.Errorf("nickname %q must not contain spaces or commas", name)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package addressbook
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	alice = common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+	bob   = common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+)
+
+func TestAddSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "addressbook.json")
+
+	book, err := Load(path)
+	if err != nil {
+		t.Fatalf("Ошибка загрузки адресной книги: %v", err)
+	}
+
+	if err := book.Add(Entry{Name: "bob", Address: bob}); err != nil {
+		t.Fatalf("Ошибка добавления записи: %v", err)
+	}
+	if err := book.Add(Entry{Name: "Alice", Address: alice, Notes: "cold wallet", ChainIDs: []uint64{1}}); err != nil {
+		t.Fatalf("Ошибка добавления записи: %v", err)
+	}
+	if err := book.Add(Entry{Name: "ALICE", Address: bob}); err == nil {
+		t.Fatal("Должна быть ошибка для повторного имени")
+	}
+	for _, name := range []string{"", "0xabc", "two words"} {
+		if err := book.Add(Entry{Name: name, Address: bob}); err == nil {
+			t.Errorf("Должна быть ошибка для имени %q", name)
+		}
+	}
+
+	book.MarkUsed(bob, time.Now())
+	if err := book.Save(); err != nil {
+		t.Fatalf("Ошибка сохранения адресной книги: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Ошибка загрузки адресной книги: %v", err)
+	}
+
+	if len(loaded.Entries) != 2 || loaded.Entries[0].Name != "Alice" {
+		t.Fatalf("Неверные записи после загрузки: %+v", loaded.Entries)
+	}
+	if loaded.Entries[0].Notes != "cold wallet" || len(loaded.Entries[0].ChainIDs) != 1 {
+		t.Errorf("Заметки или сети не сохранены: %+v", loaded.Entries[0])
+	}
+	if _, ok := loaded.Recipients[bob]; !ok {
+		t.Error("Использованный адрес не сохранен")
+	}
+
+	if err := loaded.Remove("alice"); err != nil {
+		t.Fatalf("Ошибка удаления записи: %v", err)
+	}
+	if loaded.Lookup("Alice") != nil {
+		t.Error("Запись должна быть удалена")
+	}
+	if err := loaded.Remove("carol"); err == nil {
+		t.Error("Должна быть ошибка для несуществующей записи")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	book, err := Load(filepath.Join(t.TempDir(), "addressbook.json"))
+	if err != nil {
+		t.Fatalf("Ошибка загрузки адресной книги: %v", err)
+	}
+	if err := book.Add(Entry{Name: "alice", Address: alice, ChainIDs: []uint64{11155111}}); err != nil {
+		t.Fatalf("Ошибка добавления записи: %v", err)
+	}
+
+	sepolia := big.NewInt(11155111)
+	mainnet := big.NewInt(1)
+
+	address, entry, err := book.Resolve("Alice", sepolia)
+	if err != nil || address != alice || entry == nil {
+		t.Fatalf("Ошибка разрешения имени: %v", err)
+	}
+
+	if _, _, err := book.Resolve("alice", mainnet); err == nil {
+		t.Error("Должна быть ошибка для запрещенной сети")
+	}
+	if _, _, err := book.Resolve(alice.Hex(), mainnet); err == nil {
+		t.Error("Должна быть ошибка для адреса из книги в запрещенной сети")
+	}
+
+	address, entry, err = book.Resolve("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", mainnet)
+	if err != nil || address != bob || entry != nil {
+		t.Fatalf("Ошибка разрешения адреса в нижнем регистре: %v", err)
+	}
+
+	if _, _, err := book.Resolve("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", mainnet); err == nil {
+		t.Error("Должна быть ошибка для неверной контрольной суммы")
+	}
+	if _, _, err := book.Resolve("carol", mainnet); err == nil {
+		t.Error("Должна быть ошибка для неизвестного имени")
+	}
+}
+
+func TestKnown(t *testing.T) {
+	book, err := Load(filepath.Join(t.TempDir(), "addressbook.json"))
+	if err != nil {
+		t.Fatalf("Ошибка загрузки адресной книги: %v", err)
+	}
+
+	if book.Known(bob) {
+		t.Error("Новый адрес не должен быть известен")
+	}
+
+	book.MarkUsed(bob, time.Now())
+	if !book.Known(bob) {
+		t.Error("Использованный адрес должен быть известен")
+	}
+
+	if err := book.Add(Entry{Name: "alice", Address: alice}); err != nil {
+		t.Fatalf("Ошибка добавления записи: %v", err)
+	}
+	if !book.Known(alice) {
+		t.Error("Адрес из книги должен быть известен")
+	}
+}
@@ -0,0 +1,291 @@
+package approvals
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"crypto-wallet/internal/blockchain"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type Kind string
+
+const (
+	KindAllowance Kind = "allowance"
+	KindOperator  Kind = "operator"
+)
+
+const DefaultBlockRange = 10000
+
+var UnlimitedThreshold = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 96), big.NewInt(1))
+
+var KnownSpenders = map[common.Address]string{
+	common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3"): "Uniswap Permit2",
+	common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D"): "Uniswap V2 Router",
+	common.HexToAddress("0xE592427A0AEce92De3Edee1F18E0157C05861564"): "Uniswap V3 Router",
+	common.HexToAddress("0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD"): "Uniswap Universal Router",
+	common.HexToAddress("0x1111111254EEB25477B68fb85Ed929f73A960582"): "1inch Router v5",
+	common.HexToAddress("0xDef1C0ded9bec7F1a1670819833240f027b25EfF"): "0x Exchange Proxy",
+	common.HexToAddress("0x00000000000000ADc04C56Bf30aC9d3c0aAF14dC"): "OpenSea Seaport 1.5",
+}
+
+type Candidate struct {
+	Kind    Kind           `json:"kind"`
+	Owner   common.Address `json:"owner"`
+	Token   common.Address `json:"token"`
+	Spender common.Address `json:"spender"`
+}
+
+type Approval struct {
+	Candidate
+	Allowance *big.Int
+	Err       error
+}
+
+func (a Approval) Unknown() bool {
+	return a.Err != nil
+}
+
+func (a Approval) Unlimited() bool {
+	if a.Unknown() {
+		return false
+	}
+	return a.Kind == KindOperator || a.Allowance.Cmp(UnlimitedThreshold) >= 0
+}
+
+type Range struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+type Cache struct {
+	path       string
+	Ranges     map[common.Address]Range `json:"ranges"`
+	Candidates []Candidate              `json:"candidates"`
+}
+
+func LoadCache(path string) (*Cache, error) {
+	cache := &Cache{path: path, Ranges: make(map[common.Address]Range)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading approvals cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("error parsing approvals cache: %w", err)
+	}
+	if cache.Ranges == nil {
+		cache.Ranges = make(map[common.Address]Range)
+	}
+
+	return cache, nil
+}
+
+func (c *Cache) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing approvals cache: %w", err)
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return fmt.Errorf("error creating approvals cache directory: %w", err)
+	}
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("error writing approvals cache: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("error saving approvals cache: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Cache) add(candidate Candidate) {
+	for _, existing := range c.Candidates {
+		if existing == candidate {
+			return
+		}
+	}
+	c.Candidates = append(c.Candidates, candidate)
+}
+
+func (c *Cache) For(owners []common.Address) []Candidate {
+	wanted := make(map[common.Address]bool, len(owners))
+	for _, owner := range owners {
+		wanted[owner] = true
+	}
+
+	var candidates []Candidate
+	for _, candidate := range c.Candidates {
+		if wanted[candidate.Owner] {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+type Scanner struct {
+	Client     *blockchain.Client
+	Cache      *Cache
+	BlockRange uint64
+}
+
+func (s *Scanner) Scan(owner common.Address, from uint64, to uint64, progress func(block uint64)) error {
+	scanned, ok := s.Cache.Ranges[owner]
+	if !ok {
+		return s.scan(owner, from, to, progress, func(end uint64) {
+			s.Cache.Ranges[owner] = Range{From: from, To: end}
+		})
+	}
+
+	if from < scanned.From {
+		err := s.scan(owner, from, scanned.From-1, progress, func(end uint64) {
+			if end == scanned.From-1 {
+				scanned.From = from
+				s.Cache.Ranges[owner] = scanned
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.scan(owner, scanned.To+1, to, progress, func(end uint64) {
+		scanned.To = end
+		s.Cache.Ranges[owner] = scanned
+	})
+}
+
+func (s *Scanner) scan(owner common.Address, from uint64, to uint64, progress func(block uint64), done func(end uint64)) error {
+	size := s.BlockRange
+	if size == 0 {
+		size = DefaultBlockRange
+	}
+
+	for start := from; start <= to; {
+		end := start + size - 1
+		if end > to || end < start {
+			end = to
+		}
+
+		logs, err := s.Client.FilterLogs(ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Topics: [][]common.Hash{
+				{blockchain.ApprovalEventTopic, blockchain.ApprovalForAllEventTopic},
+				{common.BytesToHash(owner.Bytes())},
+			},
+		})
+		if err != nil {
+			if size > 1 {
+				size /= 2
+				continue
+			}
+			return fmt.Errorf("error scanning approvals in blocks %d-%d: %w", start, end, err)
+		}
+
+		for _, log := range logs {
+			if candidate, ok := DecodeLog(log); ok {
+				s.Cache.add(candidate)
+			}
+		}
+
+		done(end)
+		if progress != nil {
+			progress(end)
+		}
+		start = end + 1
+	}
+
+	return nil
+}
+
+func DecodeLog(log types.Log) (Candidate, bool) {
+	if len(log.Topics) != 3 {
+		return Candidate{}, false
+	}
+
+	candidate := Candidate{
+		Owner:   common.BytesToAddress(log.Topics[1].Bytes()),
+		Token:   log.Address,
+		Spender: common.BytesToAddress(log.Topics[2].Bytes()),
+	}
+
+	switch log.Topics[0] {
+	case blockchain.ApprovalEventTopic:
+		candidate.Kind = KindAllowance
+	case blockchain.ApprovalForAllEventTopic:
+		candidate.Kind = KindOperator
+	default:
+		return Candidate{}, false
+	}
+
+	return candidate, true
+}
+
+func (s *Scanner) Check(candidates []Candidate) ([]Approval, error) {
+	var active []Approval
+
+	for _, candidate := range candidates {
+		approval := Approval{Candidate: candidate, Allowance: new(big.Int)}
+
+		switch candidate.Kind {
+		case KindAllowance:
+			allowance, err := s.Client.GetTokenAllowance(candidate.Token, candidate.Owner, candidate.Spender)
+			if err != nil {
+				approval.Err = fmt.Errorf("error checking allowance of %s on %s: %w", candidate.Spender.Hex(), candidate.Token.Hex(), err)
+				break
+			}
+			approval.Allowance = allowance
+		case KindOperator:
+			approved, err := s.Client.IsApprovedForAll(candidate.Token, candidate.Owner, candidate.Spender)
+			if err != nil {
+				approval.Err = fmt.Errorf("error checking operator %s on %s: %w", candidate.Spender.Hex(), candidate.Token.Hex(), err)
+				break
+			}
+			if approved {
+				approval.Allowance.SetInt64(1)
+			}
+		}
+
+		if approval.Unknown() || approval.Allowance.Sign() > 0 {
+			active = append(active, approval)
+		}
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		if active[i].Owner != active[j].Owner {
+			return active[i].Owner.Hex() < active[j].Owner.Hex()
+		}
+		if active[i].Token != active[j].Token {
+			return active[i].Token.Hex() < active[j].Token.Hex()
+		}
+		return active[i].Spender.Hex() < active[j].Spender.Hex()
+	})
+
+	return active, nil
+}
+
+func PackRevoke(approval Approval) ([]byte, error) {
+	switch approval.Kind {
+	case KindAllowance:
+		return blockchain.PackTokenApprove(approval.Spender, new(big.Int))
+	case KindOperator:
+		return blockchain.PackSetApprovalForAll(approval.Spender, false)
+	default:
+		return nil, fmt.Errorf("unknown approval kind %q", approval.Kind)
+	}
+}
@@ -0,0 +1,277 @@
+package approvals
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	owner      = common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+	token      = common.HexToAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359")
+	collection = common.HexToAddress("0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D")
+	router     = common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	revoked    = common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	market     = common.HexToAddress("0x00000000000000ADc04C56Bf30aC9d3c0aAF14dC")
+
+	allowanceSelector        = crypto.Keccak256([]byte("allowance(address,address)"))[:4]
+	isApprovedForAllSelector = crypto.Keccak256([]byte("isApprovedForAll(address,address)"))[:4]
+)
+
+func approvalLog(topic common.Hash, contract, spender common.Address, block uint64, data []byte) types.Log {
+	return types.Log{
+		Address:     contract,
+		Topics:      []common.Hash{topic, common.BytesToHash(owner.Bytes()), common.BytesToHash(spender.Bytes())},
+		Data:        data,
+		BlockNumber: block,
+	}
+}
+
+type fakeChain struct {
+	logs      []types.Log
+	maxRange  uint64
+	queries   int
+	allowance map[common.Address]*big.Int
+	operators map[common.Address]bool
+	failing   map[common.Address]bool
+}
+
+func (f *fakeChain) getLogs(params []json.RawMessage) (interface{}, error) {
+	var query struct {
+		FromBlock hexutil.Uint64 `json:"fromBlock"`
+		ToBlock   hexutil.Uint64 `json:"toBlock"`
+	}
+	json.Unmarshal(params[0], &query)
+
+	f.queries++
+	if uint64(query.ToBlock-query.FromBlock) >= f.maxRange {
+		return nil, fmt.Errorf("query returned more than 10000 results")
+	}
+
+	logs := []types.Log{}
+	for _, log := range f.logs {
+		if log.BlockNumber >= uint64(query.FromBlock) && log.BlockNumber <= uint64(query.ToBlock) {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func (f *fakeChain) call(params []json.RawMessage) (interface{}, error) {
+	var args struct {
+		To    common.Address `json:"to"`
+		Data  hexutil.Bytes  `json:"data"`
+		Input hexutil.Bytes  `json:"input"`
+	}
+	json.Unmarshal(params[0], &args)
+	if f.failing[args.To] {
+		return nil, fmt.Errorf("execution reverted")
+	}
+	if len(args.Data) == 0 {
+		args.Data = args.Input
+	}
+
+	spender := common.BytesToAddress(args.Data[36:68])
+	switch {
+	case bytes.Equal(args.Data[:4], allowanceSelector):
+		value := f.allowance[spender]
+		if value == nil {
+			value = new(big.Int)
+		}
+		return hexutil.Bytes(common.LeftPadBytes(value.Bytes(), 32)), nil
+	case bytes.Equal(args.Data[:4], isApprovedForAllSelector):
+		result := make([]byte, 32)
+		if f.operators[spender] {
+			result[31] = 1
+		}
+		return hexutil.Bytes(result), nil
+	}
+	return "0x", nil
+}
+
+func newTestScanner(t *testing.T, chain *fakeChain) *Scanner {
+	server := rpctest.NewServer()
+	t.Cleanup(server.Close)
+	server.Handle("eth_getLogs", chain.getLogs)
+	server.Handle("eth_call", chain.call)
+
+	client, err := blockchain.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Ошибка создания клиента: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	cache, err := LoadCache(filepath.Join(t.TempDir(), "approvals.json"))
+	if err != nil {
+		t.Fatalf("Ошибка загрузки кэша: %v", err)
+	}
+
+	return &Scanner{Client: client, Cache: cache, BlockRange: 1000}
+}
+
+func TestScanAndCheck(t *testing.T) {
+	unlimited := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	chain := &fakeChain{
+		logs: []types.Log{
+			approvalLog(blockchain.ApprovalEventTopic, token, router, 120, common.LeftPadBytes(unlimited.Bytes(), 32)),
+			approvalLog(blockchain.ApprovalEventTopic, token, revoked, 340, common.LeftPadBytes([]byte{5}, 32)),
+			approvalLog(blockchain.ApprovalEventTopic, token, router, 350, common.LeftPadBytes(unlimited.Bytes(), 32)),
+			approvalLog(blockchain.ApprovalForAllEventTopic, collection, market, 410, common.LeftPadBytes([]byte{1}, 32)),
+		},
+		maxRange:  100,
+		allowance: map[common.Address]*big.Int{router: unlimited},
+		operators: map[common.Address]bool{market: true},
+	}
+	scanner := newTestScanner(t, chain)
+
+	if err := scanner.Scan(owner, 0, 450, nil); err != nil {
+		t.Fatalf("Ошибка сканирования: %v", err)
+	}
+
+	if len(scanner.Cache.Candidates) != 3 {
+		t.Fatalf("Ожидалось 3 уникальных разрешения, получено %d", len(scanner.Cache.Candidates))
+	}
+	if scanner.Cache.Ranges[owner] != (Range{From: 0, To: 450}) {
+		t.Errorf("Неверный просканированный диапазон: %+v", scanner.Cache.Ranges[owner])
+	}
+
+	active, err := scanner.Check(scanner.Cache.For([]common.Address{owner}))
+	if err != nil {
+		t.Fatalf("Ошибка проверки разрешений: %v", err)
+	}
+	if len(active) != 2 {
+		t.Fatalf("Ожидалось 2 активных разрешения, получено %d: %+v", len(active), active)
+	}
+	for _, approval := range active {
+		if approval.Spender == revoked {
+			t.Error("Отозванное разрешение не должно попадать в список")
+		}
+		if !approval.Unlimited() {
+			t.Errorf("Разрешение %s должно быть неограниченным", approval.Spender.Hex())
+		}
+	}
+
+	// Повторное сканирование продолжает с сохранённого блока
+	if err := scanner.Cache.Save(); err != nil {
+		t.Fatalf("Ошибка сохранения кэша: %v", err)
+	}
+	cache, err := LoadCache(scanner.Cache.path)
+	if err != nil {
+		t.Fatalf("Ошибка загрузки кэша: %v", err)
+	}
+	scanner.Cache = cache
+
+	queries := chain.queries
+	if err := scanner.Scan(owner, 0, 450, nil); err != nil {
+		t.Fatalf("Ошибка повторного сканирования: %v", err)
+	}
+	if chain.queries != queries || len(cache.Candidates) != 3 {
+		t.Error("Повторное сканирование не должно запрашивать уже просмотренные блоки")
+	}
+}
+
+func TestScanBackfillsEarlierBlocks(t *testing.T) {
+	chain := &fakeChain{
+		logs: []types.Log{
+			approvalLog(blockchain.ApprovalEventTopic, token, router, 120, nil),
+			approvalLog(blockchain.ApprovalEventTopic, token, revoked, 340, nil),
+		},
+		maxRange: 1000,
+	}
+	scanner := newTestScanner(t, chain)
+
+	if err := scanner.Scan(owner, 300, 450, nil); err != nil {
+		t.Fatalf("Ошибка сканирования: %v", err)
+	}
+	if len(scanner.Cache.Candidates) != 1 {
+		t.Fatalf("Ожидалось 1 разрешение, получено %d", len(scanner.Cache.Candidates))
+	}
+
+	// Более ранний начальный блок должен досканировать пропущенные блоки
+	if err := scanner.Scan(owner, 0, 500, nil); err != nil {
+		t.Fatalf("Ошибка досканирования: %v", err)
+	}
+	if len(scanner.Cache.Candidates) != 2 {
+		t.Fatalf("Разрешение из блока 120 не найдено: %+v", scanner.Cache.Candidates)
+	}
+	if scanner.Cache.Ranges[owner] != (Range{From: 0, To: 500}) {
+		t.Errorf("Неверный просканированный диапазон: %+v", scanner.Cache.Ranges[owner])
+	}
+}
+
+func TestCheckReportsUnknown(t *testing.T) {
+	broken := common.HexToAddress("0x00000000000000000000000000000000000000b0")
+	chain := &fakeChain{
+		allowance: map[common.Address]*big.Int{router: big.NewInt(5)},
+		failing:   map[common.Address]bool{broken: true},
+	}
+	scanner := newTestScanner(t, chain)
+
+	active, err := scanner.Check([]Candidate{
+		{Kind: KindAllowance, Owner: owner, Token: broken, Spender: router},
+		{Kind: KindAllowance, Owner: owner, Token: token, Spender: router},
+	})
+	if err != nil {
+		t.Fatalf("Ошибка одного токена не должна прерывать проверку: %v", err)
+	}
+	if len(active) != 2 {
+		t.Fatalf("Ожидалось 2 разрешения, получено %d: %+v", len(active), active)
+	}
+	for _, approval := range active {
+		if approval.Token == broken && !approval.Unknown() {
+			t.Error("Разрешение сломанного токена должно быть неизвестным")
+		}
+		if approval.Token == token && (approval.Unknown() || approval.Allowance.Int64() != 5) {
+			t.Errorf("Неверное разрешение рабочего токена: %+v", approval)
+		}
+	}
+}
+
+func TestDecodeLog(t *testing.T) {
+	log := approvalLog(blockchain.ApprovalEventTopic, token, router, 1, nil)
+	candidate, ok := DecodeLog(log)
+	if !ok || candidate.Kind != KindAllowance || candidate.Owner != owner || candidate.Spender != router || candidate.Token != token {
+		t.Errorf("Неверный разбор события Approval: %+v", candidate)
+	}
+
+	// Approval стандарта ERC-721 содержит tokenId в четвёртом топике и не является allowance
+	log.Topics = append(log.Topics, common.BigToHash(big.NewInt(7)))
+	if _, ok := DecodeLog(log); ok {
+		t.Error("Событие Approval ERC-721 не должно разбираться как allowance")
+	}
+
+	log = approvalLog(blockchain.TransferEventTopic, token, router, 1, nil)
+	if _, ok := DecodeLog(log); ok {
+		t.Error("Событие Transfer не должно разбираться как разрешение")
+	}
+}
+
+func TestPackRevoke(t *testing.T) {
+	data, err := PackRevoke(Approval{Candidate: Candidate{Kind: KindAllowance, Spender: router}})
+	if err != nil {
+		t.Fatalf("Ошибка упаковки approve: %v", err)
+	}
+	if hexutil.Encode(data[:4]) != "0x095ea7b3" || new(big.Int).SetBytes(data[36:68]).Sign() != 0 {
+		t.Errorf("Неверный вызов approve(spender, 0): %x", data)
+	}
+
+	data, err = PackRevoke(Approval{Candidate: Candidate{Kind: KindOperator, Spender: market}})
+	if err != nil {
+		t.Fatalf("Ошибка упаковки setApprovalForAll: %v", err)
+	}
+	if hexutil.Encode(data[:4]) != "0xa22cb465" || new(big.Int).SetBytes(data[36:68]).Sign() != 0 {
+		t.Errorf("Неверный вызов setApprovalForAll(operator, false): %x", data)
+	}
+}
@@ -0,0 +1,375 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"crypto-wallet/internal/addressbook"
+	"crypto-wallet/internal/policy"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethereumCrypto "github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	bundleVersion = 1
+
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+var scryptN = 1 << 18
+
+type Bundle struct {
+	Wallet json.RawMessage   `json:"wallet"`
+	Files  map[string][]byte `json:"files,omitempty"`
+}
+
+type encryptedBundle struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Cipher     string `json:"cipher"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type walletKey struct {
+	PrivateKey string `json:"private_key"`
+	Address    string `json:"address"`
+}
+
+func Collect(walletFile string) (*Bundle, error) {
+	data, err := os.ReadFile(walletFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading wallet file: %w", err)
+	}
+
+	bundle := &Bundle{Wallet: data, Files: make(map[string][]byte)}
+
+	sidecars, err := sidecarFiles(walletFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, path := range sidecars {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", path, err)
+		}
+		bundle.Files[name] = contents
+	}
+
+	return bundle, nil
+}
+
+func sidecarFiles(walletFile string) (map[string]string, error) {
+	dir := filepath.Dir(walletFile)
+	prefix := strings.TrimSuffix(filepath.Base(walletFile), filepath.Ext(walletFile)) + "."
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error listing wallet directory: %w", err)
+	}
+
+	files := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == filepath.Base(walletFile) || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		sidecar := strings.TrimPrefix(name, prefix)
+		if strings.HasSuffix(sidecar, ".tmp") || strings.HasSuffix(sidecar, ".db") {
+			continue
+		}
+		files[sidecar] = filepath.Join(dir, name)
+	}
+
+	return files, nil
+}
+
+func (b *Bundle) Address() (common.Address, error) {
+	var key walletKey
+	if err := json.Unmarshal(b.Wallet, &key); err != nil {
+		return common.Address{}, fmt.Errorf("error parsing wallet data: %w", err)
+	}
+
+	privateKey, err := ethereumCrypto.HexToECDSA(key.PrivateKey)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid private key in bundle: %w", err)
+	}
+
+	address := ethereumCrypto.PubkeyToAddress(privateKey.PublicKey)
+	if !strings.EqualFold(address.Hex(), key.Address) {
+		return common.Address{}, fmt.Errorf("bundle address mismatch: expected %s, got %s", key.Address, address.Hex())
+	}
+
+	return address, nil
+}
+
+func Encrypt(bundle *Bundle, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	plaintext, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing bundle: %w", err)
+	}
+
+	enc := encryptedBundle{
+		Version: bundleVersion,
+		KDF:     "scrypt",
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Salt:    make([]byte, 32),
+		Cipher:  "aes-256-gcm",
+	}
+	if _, err := rand.Read(enc.Salt); err != nil {
+		return nil, fmt.Errorf("error generating salt: %w", err)
+	}
+
+	aead, err := enc.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	enc.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(enc.Nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	enc.Ciphertext = aead.Seal(nil, enc.Nonce, plaintext, enc.additionalData())
+
+	return json.MarshalIndent(enc, "", "  ")
+}
+
+func Decrypt(data []byte, passphrase string) (*Bundle, error) {
+	var enc encryptedBundle
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return nil, fmt.Errorf("error parsing bundle: %w", err)
+	}
+
+	if enc.Version != bundleVersion || enc.KDF != "scrypt" || enc.Cipher != "aes-256-gcm" {
+		return nil, fmt.Errorf("unsupported bundle format (version %d, %s, %s)", enc.Version, enc.KDF, enc.Cipher)
+	}
+	if enc.N != scryptN || enc.R != scryptR || enc.P != scryptP {
+		return nil, fmt.Errorf("unsupported scrypt parameters (n=%d, r=%d, p=%d)", enc.N, enc.R, enc.P)
+	}
+
+	aead, err := enc.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(enc.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid bundle nonce")
+	}
+
+	plaintext, err := aead.Open(nil, enc.Nonce, enc.Ciphertext, enc.additionalData())
+	if err != nil {
+		return nil, fmt.Errorf("wrong passphrase or corrupted bundle")
+	}
+
+	var bundle Bundle
+	if err := json.Unmarshal(plaintext, &bundle); err != nil {
+		return nil, fmt.Errorf("error parsing bundle contents: %w", err)
+	}
+
+	return &bundle, nil
+}
+
+func (e *encryptedBundle) aead(passphrase string) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), e.Salt, e.N, e.R, e.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func (e *encryptedBundle) additionalData() []byte {
+	return []byte(fmt.Sprintf("crypto-wallet bundle v%d %s n=%d r=%d p=%d", e.Version, e.KDF, e.N, e.R, e.P))
+}
+
+type ImportPlan struct {
+	Address   common.Address
+	Added     []string
+	Merged    []string
+	Unchanged []string
+	Conflicts []string
+
+	writes map[string][]byte
+}
+
+func PlanImport(bundle *Bundle, walletFile string, overwrite bool) (*ImportPlan, error) {
+	address, err := bundle.Address()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ImportPlan{Address: address, writes: make(map[string][]byte)}
+
+	if existing, err := os.ReadFile(walletFile); err == nil {
+		local, err := (&Bundle{Wallet: existing}).Address()
+		switch {
+		case err != nil:
+			return nil, fmt.Errorf("existing wallet file is invalid: %w", err)
+		case local == address:
+			plan.Unchanged = append(plan.Unchanged, filepath.Base(walletFile))
+		case overwrite:
+			plan.add(walletFile, bundle.Wallet, &plan.Merged)
+		default:
+			plan.Conflicts = append(plan.Conflicts, fmt.Sprintf("%s holds a different key (%s)", walletFile, local.Hex()))
+		}
+	} else if os.IsNotExist(err) {
+		plan.add(walletFile, bundle.Wallet, &plan.Added)
+	} else {
+		return nil, fmt.Errorf("error reading wallet file: %w", err)
+	}
+
+	base := strings.TrimSuffix(walletFile, filepath.Ext(walletFile))
+
+	names := make([]string, 0, len(bundle.Files))
+	for name := range bundle.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if strings.ContainsAny(name, `/\`) || name == ".." {
+			return nil, fmt.Errorf("invalid file name in bundle: %s", name)
+		}
+
+		incoming := bundle.Files[name]
+		path := base + "." + name
+
+		local, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			plan.add(path, incoming, &plan.Added)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", path, err)
+		}
+
+		if bytes.Equal(local, incoming) {
+			plan.Unchanged = append(plan.Unchanged, filepath.Base(path))
+			continue
+		}
+
+		merged, conflicts, err := mergeFile(name, local, incoming)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case len(conflicts) == 0 && merged != nil:
+			plan.add(path, merged, &plan.Merged)
+		case overwrite:
+			plan.add(path, incoming, &plan.Merged)
+		case len(conflicts) == 0:
+			plan.Conflicts = append(plan.Conflicts, fmt.Sprintf("%s differs from the imported copy", filepath.Base(path)))
+		default:
+			for _, conflict := range conflicts {
+				plan.Conflicts = append(plan.Conflicts, fmt.Sprintf("%s: %s", filepath.Base(path), conflict))
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+func (p *ImportPlan) add(path string, data []byte, list *[]string) {
+	p.writes[path] = data
+	*list = append(*list, filepath.Base(path))
+}
+
+func (p *ImportPlan) Apply() error {
+	if len(p.Conflicts) > 0 {
+		return fmt.Errorf("import has %d unresolved conflicts", len(p.Conflicts))
+	}
+
+	for path, data := range p.writes {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("error creating directory: %w", err)
+		}
+
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0600); err != nil {
+			return fmt.Errorf("error writing %s: %w", path, err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return fmt.Errorf("error writing %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func mergeFile(name string, local, incoming []byte) ([]byte, []string, error) {
+	switch name {
+	case "addressbook.json":
+		localBook, err := addressbook.Parse(local)
+		if err != nil {
+			return nil, nil, err
+		}
+		incomingBook, err := addressbook.Parse(incoming)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if conflicts := localBook.Merge(incomingBook); len(conflicts) > 0 {
+			return nil, conflicts, nil
+		}
+
+		merged, err := json.MarshalIndent(localBook, "", "  ")
+		return merged, nil, err
+
+	case "spending.json":
+		var localLedger, incomingLedger []policy.Spend
+		if err := json.Unmarshal(local, &localLedger); err != nil {
+			return nil, nil, fmt.Errorf("error parsing spending ledger: %w", err)
+		}
+		if err := json.Unmarshal(incoming, &incomingLedger); err != nil {
+			return nil, nil, fmt.Errorf("error parsing imported spending ledger: %w", err)
+		}
+
+		seen := make(map[string]bool)
+		for _, spend := range localLedger {
+			seen[spend.TxHash.Hex()+spend.Asset] = true
+		}
+		for _, spend := range incomingLedger {
+			if !seen[spend.TxHash.Hex()+spend.Asset] {
+				localLedger = append(localLedger, spend)
+			}
+		}
+		sort.SliceStable(localLedger, func(i, j int) bool {
+			return localLedger[i].Time.Before(localLedger[j].Time)
+		})
+
+		merged, err := json.MarshalIndent(localLedger, "", "  ")
+		return merged, nil, err
+	}
+
+	return nil, nil, nil
+}
@@ -0,0 +1,212 @@
+package backup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"crypto-wallet/internal/addressbook"
+	"crypto-wallet/internal/crypto"
+)
+
+func init() {
+	scryptN = 1 << 10
+}
+
+func writeTestWallet(t *testing.T, dir string) string {
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключей: %v", err)
+	}
+
+	data, _ := json.Marshal(map[string]string{
+		"private_key": keyPair.GetPrivateKeyHex(),
+		"public_key":  keyPair.GetPublicKeyHex(),
+		"address":     keyPair.GetAddressHex(),
+	})
+
+	walletFile := filepath.Join(dir, "wallet.json")
+	if err := os.WriteFile(walletFile, data, 0600); err != nil {
+		t.Fatalf("Ошибка записи кошелька: %v", err)
+	}
+	return walletFile
+}
+
+func writeBook(t *testing.T, path string, entries ...addressbook.Entry) {
+	book, err := addressbook.Load(path)
+	if err != nil {
+		t.Fatalf("Ошибка загрузки адресной книги: %v", err)
+	}
+	for _, entry := range entries {
+		if err := book.Add(entry); err != nil {
+			t.Fatalf("Ошибка добавления записи: %v", err)
+		}
+	}
+	if err := book.Save(); err != nil {
+		t.Fatalf("Ошибка сохранения адресной книги: %v", err)
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	dir := t.TempDir()
+	walletFile := writeTestWallet(t, dir)
+	writeBook(t, filepath.Join(dir, "wallet.addressbook.json"), addressbook.Entry{Name: "alice", Address: testAddress})
+	os.WriteFile(filepath.Join(dir, "wallet.history.db"), []byte("index"), 0600)
+	os.WriteFile(filepath.Join(dir, "other.json"), []byte("{}"), 0600)
+
+	bundle, err := Collect(walletFile)
+	if err != nil {
+		t.Fatalf("Ошибка сбора файлов: %v", err)
+	}
+	if len(bundle.Files) != 1 || bundle.Files["addressbook.json"] == nil {
+		t.Fatalf("Неверный набор файлов: %v", bundle.Files)
+	}
+
+	data, err := Encrypt(bundle, "correct horse")
+	if err != nil {
+		t.Fatalf("Ошибка шифрования: %v", err)
+	}
+	if strings.Contains(string(data), "private_key") {
+		t.Fatal("Ключ не должен храниться в открытом виде")
+	}
+
+	decrypted, err := Decrypt(data, "correct horse")
+	if err != nil {
+		t.Fatalf("Ошибка расшифровки: %v", err)
+	}
+	if string(decrypted.Wallet) != string(bundle.Wallet) {
+		t.Error("Данные кошелька не совпадают")
+	}
+
+	if _, err := Decrypt(data, "wrong"); err == nil {
+		t.Error("Должна быть ошибка для неверного пароля")
+	}
+
+	var enc encryptedBundle
+	json.Unmarshal(data, &enc)
+	enc.Ciphertext[0] ^= 1
+	tampered, _ := json.Marshal(enc)
+	if _, err := Decrypt(tampered, "correct horse"); err == nil {
+		t.Error("Должна быть ошибка для измененного файла")
+	}
+}
+
+func TestDecryptRejectsScryptParameters(t *testing.T) {
+	data, err := Encrypt(&Bundle{Wallet: json.RawMessage(`{}`)}, "correct horse")
+	if err != nil {
+		t.Fatalf("Ошибка шифрования: %v", err)
+	}
+
+	// Параметры из файла не должны заставлять выделять гигабайты памяти до проверки пароля
+	for _, change := range []func(*encryptedBundle){
+		func(enc *encryptedBundle) { enc.N = 1 << 30 },
+		func(enc *encryptedBundle) { enc.R = 1 << 20 },
+		func(enc *encryptedBundle) { enc.P = 1 << 20 },
+	} {
+		var enc encryptedBundle
+		json.Unmarshal(data, &enc)
+		change(&enc)
+		modified, _ := json.Marshal(enc)
+
+		_, err := Decrypt(modified, "correct horse")
+		if err == nil || !strings.Contains(err.Error(), "unsupported scrypt parameters") {
+			t.Errorf("Ожидалась ошибка о параметрах scrypt, получено %v", err)
+		}
+	}
+}
+
+func TestImportIntoEmptyDirectory(t *testing.T) {
+	source := t.TempDir()
+	walletFile := writeTestWallet(t, source)
+	writeBook(t, filepath.Join(source, "wallet.addressbook.json"), addressbook.Entry{Name: "alice", Address: testAddress})
+
+	bundle, err := Collect(walletFile)
+	if err != nil {
+		t.Fatalf("Ошибка сбора файлов: %v", err)
+	}
+
+	target := filepath.Join(t.TempDir(), "keys", "main.json")
+	plan, err := PlanImport(bundle, target, false)
+	if err != nil {
+		t.Fatalf("Ошибка планирования импорта: %v", err)
+	}
+	if len(plan.Added) != 2 || len(plan.Conflicts) != 0 {
+		t.Fatalf("Неверный план импорта: %+v", plan)
+	}
+	if err := plan.Apply(); err != nil {
+		t.Fatalf("Ошибка импорта: %v", err)
+	}
+
+	imported, err := os.ReadFile(target)
+	if err != nil || string(imported) != string(bundle.Wallet) {
+		t.Fatalf("Кошелек не импортирован: %v", err)
+	}
+
+	book, err := addressbook.Load(filepath.Join(filepath.Dir(target), "main.addressbook.json"))
+	if err != nil || book.Lookup("alice") == nil {
+		t.Fatalf("Адресная книга не импортирована: %v", err)
+	}
+}
+
+func TestImportMergesAndDetectsConflicts(t *testing.T) {
+	source := t.TempDir()
+	walletFile := writeTestWallet(t, source)
+	writeBook(t, filepath.Join(source, "wallet.addressbook.json"),
+		addressbook.Entry{Name: "alice", Address: testAddress},
+		addressbook.Entry{Name: "bob", Address: testAddress})
+	os.WriteFile(filepath.Join(source, "wallet.policy.json"), []byte(`{"max_gas_price_gwei": "50"}`), 0600)
+
+	bundle, err := Collect(walletFile)
+	if err != nil {
+		t.Fatalf("Ошибка сбора файлов: %v", err)
+	}
+
+	// Тот же кошелек на другой машине со своей адресной книгой
+	target := t.TempDir()
+	targetWallet := filepath.Join(target, "wallet.json")
+	os.WriteFile(targetWallet, bundle.Wallet, 0600)
+	writeBook(t, filepath.Join(target, "wallet.addressbook.json"), addressbook.Entry{Name: "carol", Address: testAddress})
+	os.WriteFile(filepath.Join(target, "wallet.policy.json"), []byte(`{"max_gas_price_gwei": "20"}`), 0600)
+
+	plan, err := PlanImport(bundle, targetWallet, false)
+	if err != nil {
+		t.Fatalf("Ошибка планирования импорта: %v", err)
+	}
+	if len(plan.Conflicts) != 1 || !strings.Contains(plan.Conflicts[0], "policy.json") {
+		t.Fatalf("Ожидался конфликт политики, получено %v", plan.Conflicts)
+	}
+	if err := plan.Apply(); err == nil {
+		t.Fatal("Импорт с конфликтами должен завершиться ошибкой")
+	}
+
+	plan, err = PlanImport(bundle, targetWallet, true)
+	if err != nil {
+		t.Fatalf("Ошибка планирования импорта: %v", err)
+	}
+	if err := plan.Apply(); err != nil {
+		t.Fatalf("Ошибка импорта: %v", err)
+	}
+
+	book, err := addressbook.Load(filepath.Join(target, "wallet.addressbook.json"))
+	if err != nil {
+		t.Fatalf("Ошибка загрузки адресной книги: %v", err)
+	}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		if book.Lookup(name) == nil {
+			t.Errorf("Запись %s потеряна при объединении", name)
+		}
+	}
+
+	// Другой ключ в целевом файле является конфликтом
+	other := t.TempDir()
+	writeTestWallet(t, other)
+	plan, err = PlanImport(bundle, filepath.Join(other, "wallet.json"), false)
+	if err != nil {
+		t.Fatalf("Ошибка планирования импорта: %v", err)
+	}
+	if len(plan.Conflicts) != 1 {
+		t.Errorf("Ожидался конфликт ключей, получено %v", plan.Conflicts)
+	}
+}
@@ -0,0 +1,138 @@
+package backup
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"crypto-wallet/internal/shamir"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	sharePrefix = "cwshare1"
+
+	KindKey      = "key"
+	KindMnemonic = "mnemonic"
+)
+
+type Share struct {
+	Kind      string
+	ID        uint16
+	Threshold int
+	Address   common.Address
+	shamir.Share
+}
+
+func Split(kind string, secret []byte, address common.Address, threshold, count int) ([]Share, error) {
+	if kind != KindKey && kind != KindMnemonic {
+		return nil, fmt.Errorf("unknown secret kind: %s", kind)
+	}
+
+	parts, err := shamir.Split(secret, threshold, count)
+	if err != nil {
+		return nil, err
+	}
+
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, fmt.Errorf("error generating share set ID: %w", err)
+	}
+
+	shares := make([]Share, len(parts))
+	for i, part := range parts {
+		shares[i] = Share{
+			Kind:      kind,
+			ID:        binary.BigEndian.Uint16(id[:]),
+			Threshold: threshold,
+			Address:   address,
+			Share:     part,
+		}
+	}
+
+	return shares, nil
+}
+
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no shares given")
+	}
+
+	first := shares[0]
+	parts := make([]shamir.Share, len(shares))
+	for i, share := range shares {
+		if share.ID != first.ID || share.Kind != first.Kind || share.Threshold != first.Threshold || share.Address != first.Address {
+			return nil, fmt.Errorf("share %d belongs to a different backup", share.Index)
+		}
+		parts[i] = share.Share
+	}
+
+	if len(shares) < first.Threshold {
+		return nil, fmt.Errorf("%d shares given, %d required", len(shares), first.Threshold)
+	}
+
+	return shamir.Combine(parts)
+}
+
+func (s Share) String() string {
+	body := fmt.Sprintf("%s-%s-%04x-%d-%d-%x-%x", sharePrefix, s.Kind, s.ID, s.Threshold, s.Index, s.Address.Bytes(), s.Value)
+	return body + "-" + checksum(body)
+}
+
+func ParseShare(text string) (Share, error) {
+	text = strings.TrimSpace(text)
+
+	fields := strings.Split(text, "-")
+	if len(fields) != 8 || fields[0] != sharePrefix {
+		return Share{}, fmt.Errorf("not a backup share")
+	}
+
+	body := strings.Join(fields[:7], "-")
+	if checksum(body) != strings.ToLower(fields[7]) {
+		return Share{}, fmt.Errorf("share checksum mismatch, check for typos")
+	}
+
+	var share Share
+	share.Kind = fields[1]
+	if share.Kind != KindKey && share.Kind != KindMnemonic {
+		return Share{}, fmt.Errorf("unknown secret kind: %s", share.Kind)
+	}
+
+	id, err := strconv.ParseUint(fields[2], 16, 16)
+	if err != nil {
+		return Share{}, fmt.Errorf("invalid share set ID: %s", fields[2])
+	}
+	share.ID = uint16(id)
+
+	if share.Threshold, err = strconv.Atoi(fields[3]); err != nil || share.Threshold < 2 {
+		return Share{}, fmt.Errorf("invalid threshold: %s", fields[3])
+	}
+
+	index, err := strconv.ParseUint(fields[4], 10, 8)
+	if err != nil || index == 0 {
+		return Share{}, fmt.Errorf("invalid share index: %s", fields[4])
+	}
+	share.Index = byte(index)
+
+	address, err := hex.DecodeString(fields[5])
+	if err != nil || len(address) != common.AddressLength {
+		return Share{}, fmt.Errorf("invalid share address")
+	}
+	share.Address = common.BytesToAddress(address)
+
+	if share.Value, err = hex.DecodeString(fields[6]); err != nil || len(share.Value) == 0 {
+		return Share{}, fmt.Errorf("invalid share data")
+	}
+
+	return share, nil
+}
+
+func checksum(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:4])
+}
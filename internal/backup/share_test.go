@@ -0,0 +1,83 @@
+package backup
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var testAddress = common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+
+func TestShareEncoding(t *testing.T) {
+	secret := bytes.Repeat([]byte{0xab}, 32)
+
+	shares, err := Split(KindKey, secret, testAddress, 3, 5)
+	if err != nil {
+		t.Fatalf("Ошибка разделения ключа: %v", err)
+	}
+
+	var parsed []Share
+	for _, share := range shares[1:4] {
+		text := share.String()
+		if !strings.HasPrefix(text, "cwshare1-key-") {
+			t.Fatalf("Неверный формат доли: %s", text)
+		}
+
+		decoded, err := ParseShare(text)
+		if err != nil {
+			t.Fatalf("Ошибка разбора доли: %v", err)
+		}
+		if decoded.Address != testAddress || decoded.Threshold != 3 || decoded.ID != share.ID {
+			t.Errorf("Неверные данные доли: %+v", decoded)
+		}
+		parsed = append(parsed, decoded)
+	}
+
+	recovered, err := Combine(parsed)
+	if err != nil {
+		t.Fatalf("Ошибка восстановления ключа: %v", err)
+	}
+	if !bytes.Equal(recovered, secret) {
+		t.Error("Восстановленный ключ не совпадает")
+	}
+
+	if _, err := Combine(parsed[:2]); err == nil {
+		t.Error("Должна быть ошибка при нехватке долей")
+	}
+}
+
+func TestParseShareDetectsTypos(t *testing.T) {
+	shares, err := Split(KindMnemonic, []byte{1, 2, 3, 4}, testAddress, 2, 2)
+	if err != nil {
+		t.Fatalf("Ошибка разделения: %v", err)
+	}
+
+	text := shares[0].String()
+	fields := strings.Split(text, "-")
+	fields[6] = "ff" + fields[6][2:]
+
+	if _, err := ParseShare(strings.Join(fields, "-")); err == nil {
+		t.Error("Должна быть ошибка контрольной суммы")
+	}
+	if _, err := ParseShare("not-a-share"); err == nil {
+		t.Error("Должна быть ошибка для неверной строки")
+	}
+}
+
+func TestCombineRejectsMixedSets(t *testing.T) {
+	first, err := Split(KindKey, []byte{1, 2, 3}, testAddress, 2, 3)
+	if err != nil {
+		t.Fatalf("Ошибка разделения: %v", err)
+	}
+	second, err := Split(KindKey, []byte{1, 2, 3}, testAddress, 2, 3)
+	if err != nil {
+		t.Fatalf("Ошибка разделения: %v", err)
+	}
+	second[1].ID = first[0].ID + 1
+
+	if _, err := Combine([]Share{first[0], second[1]}); err == nil {
+		t.Error("Должна быть ошибка для долей из разных наборов")
+	}
+}
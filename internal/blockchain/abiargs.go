@@ -0,0 +1,146 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func PackMethodCall(contractABI *abi.ABI, name string, args []string) ([]byte, error) {
+	method, ok := contractABI.Methods[name]
+	if !ok {
+		return nil, fmt.Errorf("method %s not found in ABI", name)
+	}
+	if len(args) != len(method.Inputs) {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d", method.Sig, len(method.Inputs), len(args))
+	}
+
+	values := make([]interface{}, len(args))
+	for i, input := range method.Inputs {
+		value, err := ParseABIArgument(input.Type, args[i])
+		if err != nil {
+			return nil, fmt.Errorf("argument %d (%s %s): %w", i+1, input.Type, input.Name, err)
+		}
+		values[i] = value
+	}
+
+	data, err := contractABI.Pack(name, values...)
+	if err != nil {
+		return nil, fmt.Errorf("error packing %s call: %w", name, err)
+	}
+	return data, nil
+}
+
+func ParseABIArgument(typ abi.Type, input string) (interface{}, error) {
+	input = strings.TrimSpace(input)
+
+	switch typ.T {
+	case abi.AddressTy:
+		if !common.IsHexAddress(input) {
+			return nil, fmt.Errorf("invalid address %q", input)
+		}
+		return common.HexToAddress(input), nil
+
+	case abi.BoolTy:
+		value, err := strconv.ParseBool(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bool %q", input)
+		}
+		return value, nil
+
+	case abi.StringTy:
+		return input, nil
+
+	case abi.BytesTy:
+		value, err := hexutil.Decode(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bytes %q: %w", input, err)
+		}
+		return value, nil
+
+	case abi.FixedBytesTy:
+		value, err := hexutil.Decode(input)
+		if err != nil || len(value) != typ.Size {
+			return nil, fmt.Errorf("expected %d bytes of hex, got %q", typ.Size, input)
+		}
+		array := reflect.New(typ.GetType()).Elem()
+		reflect.Copy(array, reflect.ValueOf(value))
+		return array.Interface(), nil
+
+	case abi.IntTy, abi.UintTy:
+		return parseABIInteger(typ, input)
+
+	case abi.SliceTy, abi.ArrayTy:
+		return parseABIList(typ, input)
+	}
+
+	return nil, fmt.Errorf("arguments of type %s are not supported", typ)
+}
+
+func parseABIInteger(typ abi.Type, input string) (interface{}, error) {
+	value, ok := new(big.Int).SetString(input, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", input)
+	}
+
+	if typ.T == abi.UintTy {
+		if value.Sign() < 0 || value.BitLen() > typ.Size {
+			return nil, fmt.Errorf("%s out of range for %s", input, typ)
+		}
+	} else {
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(typ.Size-1))
+		if value.Cmp(limit) >= 0 || value.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, fmt.Errorf("%s out of range for %s", input, typ)
+		}
+	}
+
+	goType := typ.GetType()
+	if goType.Kind() == reflect.Ptr {
+		return value, nil
+	}
+
+	result := reflect.New(goType).Elem()
+	if typ.T == abi.UintTy {
+		result.SetUint(value.Uint64())
+	} else {
+		result.SetInt(value.Int64())
+	}
+	return result.Interface(), nil
+}
+
+func parseABIList(typ abi.Type, input string) (interface{}, error) {
+	if !strings.HasPrefix(input, "[") || !strings.HasSuffix(input, "]") {
+		return nil, fmt.Errorf("expected a list like [a,b], got %q", input)
+	}
+
+	var items []string
+	if inner := strings.TrimSpace(input[1 : len(input)-1]); inner != "" {
+		items = strings.Split(inner, ",")
+	}
+
+	if typ.T == abi.ArrayTy && len(items) != typ.Size {
+		return nil, fmt.Errorf("expected %d items, got %d", typ.Size, len(items))
+	}
+
+	var list reflect.Value
+	if typ.T == abi.ArrayTy {
+		list = reflect.New(typ.GetType()).Elem()
+	} else {
+		list = reflect.MakeSlice(typ.GetType(), len(items), len(items))
+	}
+
+	for i, item := range items {
+		value, err := ParseABIArgument(*typ.Elem, item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+		list.Index(i).Set(reflect.ValueOf(value))
+	}
+	return list.Interface(), nil
+}
@@ -0,0 +1,65 @@
+package blockchain
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const argsTestABI = `[
+	{"type":"function","name":"mixed","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"},{"name":"small","type":"uint8"},{"name":"delta","type":"int64"},{"name":"flag","type":"bool"},{"name":"id","type":"bytes32"},{"name":"memo","type":"string"},{"name":"payload","type":"bytes"}],"outputs":[]},
+	{"type":"function","name":"lists","inputs":[{"name":"holders","type":"address[]"},{"name":"pair","type":"uint16[2]"}],"outputs":[]}
+]`
+
+func TestPackMethodCall(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(argsTestABI))
+	if err != nil {
+		t.Fatalf("Ошибка разбора ABI: %v", err)
+	}
+
+	id := "0x" + strings.Repeat("ab", 32)
+	data, err := PackMethodCall(&contractABI, "mixed", []string{
+		testHolder.Hex(), "1000000000000000000000", "0xff", "-5", "true", id, "hello", "0x0102",
+	})
+	if err != nil {
+		t.Fatalf("Ошибка упаковки вызова: %v", err)
+	}
+
+	var idBytes [32]byte
+	copy(idBytes[:], bytes.Repeat([]byte{0xab}, 32))
+	amount, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	expected, _ := contractABI.Pack("mixed", testHolder, amount, uint8(255), int64(-5), true, idBytes, "hello", []byte{1, 2})
+	if !bytes.Equal(data, expected) {
+		t.Error("Аргументы упакованы неверно")
+	}
+
+	data, err = PackMethodCall(&contractABI, "lists", []string{"[" + testHolder.Hex() + ", " + testERC721.Hex() + "]", "[1,2]"})
+	if err != nil {
+		t.Fatalf("Ошибка упаковки списков: %v", err)
+	}
+	expected, _ = contractABI.Pack("lists", []common.Address{testHolder, testERC721}, [2]uint16{1, 2})
+	if !bytes.Equal(data, expected) {
+		t.Error("Списки упакованы неверно")
+	}
+
+	invalid := map[string][]string{
+		"uint8 вне диапазона": {testHolder.Hex(), "1", "256", "0", "true", id, "", "0x"},
+		"отрицательный uint":  {testHolder.Hex(), "-1", "1", "0", "true", id, "", "0x"},
+		"короткий bytes32":    {testHolder.Hex(), "1", "1", "0", "true", "0x01", "", "0x"},
+		"неверный адрес":      {"alice", "1", "1", "0", "true", id, "", "0x"},
+		"мало аргументов":     {testHolder.Hex()},
+	}
+	for name, args := range invalid {
+		if _, err := PackMethodCall(&contractABI, "mixed", args); err == nil {
+			t.Errorf("%s: ожидалась ошибка", name)
+		}
+	}
+
+	if _, err := PackMethodCall(&contractABI, "missing", nil); err == nil {
+		t.Error("Неизвестный метод должен вызывать ошибку")
+	}
+}
@@ -0,0 +1,87 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func (c *Client) CreateAccessList(from common.Address, to common.Address, value *big.Int, data []byte) (types.AccessList, uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	arg := map[string]interface{}{
+		"from":  from,
+		"to":    to,
+		"input": hexutil.Bytes(data),
+	}
+	if value != nil {
+		arg["value"] = (*hexutil.Big)(value)
+	}
+
+	var result struct {
+		AccessList *types.AccessList `json:"accessList"`
+		Error      string            `json:"error"`
+		GasUsed    hexutil.Uint64    `json:"gasUsed"`
+	}
+	if err := c.client.Client().CallContext(ctx, &result, "eth_createAccessList", arg, "latest"); err != nil {
+		return nil, 0, fmt.Errorf("error creating access list: %w", err)
+	}
+	if result.Error != "" {
+		return nil, 0, fmt.Errorf("error creating access list: execution failed: %s", result.Error)
+	}
+	if result.AccessList == nil {
+		return types.AccessList{}, uint64(result.GasUsed), nil
+	}
+
+	return *result.AccessList, uint64(result.GasUsed), nil
+}
+
+func (c *Client) EstimateGasWithAccessList(from common.Address, to common.Address, value *big.Int, data []byte, accessList types.AccessList) (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	arg := map[string]interface{}{
+		"from":       from,
+		"to":         to,
+		"input":      hexutil.Bytes(data),
+		"accessList": accessList,
+	}
+	if value != nil {
+		arg["value"] = (*hexutil.Big)(value)
+	}
+
+	var gasLimit hexutil.Uint64
+	if err := c.client.Client().CallContext(ctx, &gasLimit, "eth_estimateGas", arg); err != nil {
+		return 0, fmt.Errorf("error estimating gas with access list: %w", err)
+	}
+
+	return uint64(gasLimit), nil
+}
+
+func (c *Client) CreateAccessListTransaction(
+	chainID *big.Int,
+	to common.Address,
+	value *big.Int,
+	gasLimit uint64,
+	gasPrice *big.Int,
+	nonce uint64,
+	data []byte,
+	accessList types.AccessList,
+) *types.Transaction {
+	return types.NewTx(&types.AccessListTx{
+		ChainID:    chainID,
+		Nonce:      nonce,
+		GasPrice:   gasPrice,
+		Gas:        gasLimit,
+		To:         &to,
+		Value:      value,
+		Data:       data,
+		AccessList: accessList,
+	})
+}
@@ -0,0 +1,88 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCreateAccessList(t *testing.T) {
+	slot := common.HexToHash("0x01")
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	var withList int
+	server.Handle("eth_createAccessList", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{
+			"accessList": types.AccessList{{Address: testERC721, StorageKeys: []common.Hash{slot}}},
+			"gasUsed":    "0x7530",
+		}, nil
+	})
+	server.Handle("eth_estimateGas", func(params []json.RawMessage) (interface{}, error) {
+		var args struct {
+			AccessList *types.AccessList `json:"accessList"`
+		}
+		json.Unmarshal(params[0], &args)
+		if args.AccessList != nil && len(*args.AccessList) == 1 {
+			withList++
+			return "0x7530", nil
+		}
+		return "0x7918", nil
+	})
+
+	client := newTestClient(t, server)
+
+	accessList, gasUsed, err := client.CreateAccessList(testHolder, testERC721, nil, []byte{1})
+	if err != nil {
+		t.Fatalf("Ошибка создания списка доступа: %v", err)
+	}
+	if gasUsed != 30000 || len(accessList) != 1 || accessList[0].StorageKeys[0] != slot {
+		t.Errorf("Неверный список доступа: %v, газ %d", accessList, gasUsed)
+	}
+
+	gas, err := client.EstimateGasWithAccessList(testHolder, testERC721, nil, []byte{1}, accessList)
+	if err != nil || gas != 30000 || withList != 1 {
+		t.Errorf("Оценка газа должна передавать список доступа: %d, %v", gas, err)
+	}
+}
+
+func TestCreateAccessListVMError(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_createAccessList", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{"accessList": []interface{}{}, "gasUsed": "0x0", "error": "execution reverted"}, nil
+	})
+
+	client := newTestClient(t, server)
+	if _, _, err := client.CreateAccessList(testHolder, testERC721, nil, nil); err == nil {
+		t.Error("Ошибка выполнения должна возвращаться")
+	}
+}
+
+func TestSignAccessListTransaction(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	client := &Client{}
+
+	accessList := types.AccessList{{Address: testERC721, StorageKeys: []common.Hash{{1}}}}
+	tx := client.CreateAccessListTransaction(big.NewInt(11155111), testHolder, big.NewInt(1), 50000, big.NewInt(1e9), 3, nil, accessList)
+
+	signed, err := client.SignTransaction(tx, key)
+	if err != nil {
+		t.Fatalf("Ошибка подписи: %v", err)
+	}
+	if signed.Type() != types.AccessListTxType || len(signed.AccessList()) != 1 {
+		t.Fatalf("Ожидалась транзакция EIP-2930, получен тип %d", signed.Type())
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(11155111)), signed)
+	if err != nil || sender != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("Неверный отправитель: %s, %v", sender.Hex(), err)
+	}
+}
@@ -0,0 +1,140 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const DefaultBatchSize = 100
+
+type BalanceResult struct {
+	Address common.Address
+	Balance *big.Int
+	Err     error
+}
+
+type PartialError struct {
+	Failed int
+	Total  int
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%d of %d queries failed", e.Failed, e.Total)
+}
+
+func (c *Client) SetBatchSize(size int) {
+	c.batchSize = size
+}
+
+func (c *Client) chunkSize(defaultSize int, configured int) int {
+	if configured > 0 {
+		return configured
+	}
+	return defaultSize
+}
+
+func (c *Client) GetBalances(addresses []common.Address) ([]BalanceResult, error) {
+	results := make([]BalanceResult, len(addresses))
+	balances := make([]hexutil.Big, len(addresses))
+	elems := make([]rpc.BatchElem, len(addresses))
+
+	for i, address := range addresses {
+		results[i].Address = address
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getBalance",
+			Args:   []interface{}{address, "latest"},
+			Result: &balances[i],
+		}
+	}
+
+	c.batchCall(elems)
+
+	for i := range results {
+		if elems[i].Error != nil {
+			results[i].Err = fmt.Errorf("error getting balance: %w", elems[i].Error)
+			continue
+		}
+		results[i].Balance = balances[i].ToInt()
+	}
+
+	return results, partialError(results)
+}
+
+func (c *Client) GetTokenBalances(token common.Address, holders []common.Address) ([]BalanceResult, error) {
+	results := make([]BalanceResult, len(holders))
+	outputs := make([]hexutil.Bytes, len(holders))
+	elems := make([]rpc.BatchElem, len(holders))
+
+	for i, holder := range holders {
+		results[i].Address = holder
+
+		data, err := erc20ABI.Pack("balanceOf", holder)
+		if err != nil {
+			return nil, fmt.Errorf("error packing balanceOf call: %w", err)
+		}
+
+		elems[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{
+				map[string]interface{}{"to": token, "data": hexutil.Bytes(data)},
+				"latest",
+			},
+			Result: &outputs[i],
+		}
+	}
+
+	c.batchCall(elems)
+
+	for i := range results {
+		if elems[i].Error != nil {
+			results[i].Err = fmt.Errorf("error getting token balance: %w", elems[i].Error)
+			continue
+		}
+		results[i].Balance, results[i].Err = unpackUint256(erc20ABI, "balanceOf", outputs[i])
+	}
+
+	return results, partialError(results)
+}
+
+func (c *Client) batchCall(elems []rpc.BatchElem) {
+	size := c.chunkSize(DefaultBatchSize, c.batchSize)
+
+	for start := 0; start < len(elems); start += size {
+		end := start + size
+		if end > len(elems) {
+			end = len(elems)
+		}
+		chunk := elems[start:end]
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := c.client.Client().BatchCallContext(ctx, chunk)
+		cancel()
+
+		if err != nil {
+			for i := range chunk {
+				chunk[i].Error = fmt.Errorf("batch request failed: %w", err)
+			}
+		}
+	}
+}
+
+func partialError(results []BalanceResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	if failed == 0 {
+		return nil
+	}
+
+	return &PartialError{Failed: failed, Total: len(results)}
+}
@@ -0,0 +1,236 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type callArgs struct {
+	To    common.Address `json:"to"`
+	Data  hexutil.Bytes  `json:"data"`
+	Input hexutil.Bytes  `json:"input"`
+}
+
+func decodeCallArgs(raw json.RawMessage) callArgs {
+	var args callArgs
+	json.Unmarshal(raw, &args)
+	if len(args.Data) == 0 {
+		args.Data = args.Input
+	}
+	return args
+}
+
+func newTestClient(t *testing.T, server *rpctest.Server) *Client {
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Ошибка создания клиента: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func testAddresses(n int) []common.Address {
+	addresses := make([]common.Address, n)
+	for i := range addresses {
+		addresses[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+	return addresses
+}
+
+func TestGetBalancesBatched(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_getBalance", func(params []json.RawMessage) (interface{}, error) {
+		var address common.Address
+		json.Unmarshal(params[0], &address)
+		if address == common.BigToAddress(big.NewInt(3)) {
+			return nil, fmt.Errorf("missing trie node")
+		}
+		return hexutil.EncodeBig(new(big.Int).Mul(address.Big(), big.NewInt(1000))), nil
+	})
+
+	client := newTestClient(t, server)
+	client.SetBatchSize(4)
+
+	results, err := client.GetBalances(testAddresses(10))
+
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("Ожидалась ошибка частичного выполнения, получено %v", err)
+	}
+	if partial.Failed != 1 || partial.Total != 10 {
+		t.Fatalf("Неверная статистика ошибок: %d из %d", partial.Failed, partial.Total)
+	}
+
+	// 10 адресов при размере пакета 4 — три HTTP-запроса
+	if server.Requests() != 3 {
+		t.Fatalf("Ожидалось 3 запроса, получено %d", server.Requests())
+	}
+
+	for i, result := range results {
+		if i == 2 {
+			if result.Err == nil {
+				t.Fatal("Для третьего адреса должна быть ошибка")
+			}
+			continue
+		}
+		expected := big.NewInt(int64((i + 1) * 1000))
+		if result.Err != nil || result.Balance.Cmp(expected) != 0 {
+			t.Fatalf("Неверный баланс для %s: %v, %v", result.Address.Hex(), result.Balance, result.Err)
+		}
+	}
+}
+
+func TestGetTokenBalancesBatched(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	token := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		args := decodeCallArgs(params[0])
+		if args.To != token {
+			return nil, fmt.Errorf("unexpected target %s", args.To.Hex())
+		}
+		holder := common.BytesToAddress(args.Data[4:36])
+		return hexutil.Bytes(common.LeftPadBytes(holder.Bytes(), 32)), nil
+	})
+
+	client := newTestClient(t, server)
+
+	holders := testAddresses(5)
+	results, err := client.GetTokenBalances(token, holders)
+	if err != nil {
+		t.Fatalf("Ошибка получения балансов токена: %v", err)
+	}
+
+	if server.Requests() != 1 {
+		t.Fatalf("Ожидался 1 запрос, получено %d", server.Requests())
+	}
+
+	for i, result := range results {
+		if result.Balance.Cmp(holders[i].Big()) != 0 {
+			t.Fatalf("Неверный баланс токена для %s: %s", holders[i].Hex(), result.Balance)
+		}
+	}
+}
+
+func TestGetBalancesMulticall(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		args := decodeCallArgs(params[0])
+		if args.To != Multicall3Address {
+			return nil, fmt.Errorf("unexpected target %s", args.To.Hex())
+		}
+
+		values, err := multicall3ABI.Methods["aggregate3"].Inputs.Unpack(args.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+
+		calls := *abi.ConvertType(values[0], new([]Call3)).(*[]Call3)
+
+		results := make([]Call3Result, len(calls))
+		for i, call := range calls {
+			holder := common.BytesToAddress(call.CallData[4:36])
+			if holder == common.BigToAddress(big.NewInt(2)) {
+				continue
+			}
+			results[i] = Call3Result{Success: true, ReturnData: common.LeftPadBytes(big.NewInt(int64(i+1)).Bytes(), 32)}
+		}
+
+		output, err := multicall3ABI.Methods["aggregate3"].Outputs.Pack(results)
+		if err != nil {
+			return nil, err
+		}
+		return hexutil.Bytes(output), nil
+	})
+
+	client := newTestClient(t, server)
+	client.SetMulticallSize(3)
+
+	results, err := client.GetBalancesMulticall(testAddresses(7))
+
+	var partial *PartialError
+	if !errors.As(err, &partial) || partial.Failed != 1 {
+		t.Fatalf("Ожидалась одна неудачная позиция, получено %v", err)
+	}
+
+	if server.Calls("eth_call") != 3 {
+		t.Fatalf("Ожидалось 3 вызова multicall, получено %d", server.Calls("eth_call"))
+	}
+
+	if results[1].Err == nil {
+		t.Fatal("Для второго адреса должна быть ошибка")
+	}
+
+	if results[0].Balance.Int64() != 1 || results[3].Balance.Int64() != 1 || results[6].Balance.Int64() != 1 {
+		t.Fatalf("Неверные балансы: %v %v %v", results[0].Balance, results[3].Balance, results[6].Balance)
+	}
+	if results[5].Balance.Int64() != 3 {
+		t.Fatalf("Неверный баланс шестого адреса: %v", results[5].Balance)
+	}
+}
+
+func TestGetBalancesMulticallChunkFailure(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		args := decodeCallArgs(params[0])
+		values, err := multicall3ABI.Methods["aggregate3"].Inputs.Unpack(args.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+
+		calls := *abi.ConvertType(values[0], new([]Call3)).(*[]Call3)
+
+		results := make([]Call3Result, len(calls))
+		for i, call := range calls {
+			holder := common.BytesToAddress(call.CallData[4:36])
+			if holder == common.BigToAddress(big.NewInt(4)) {
+				return nil, &rpctest.Error{Code: -32000, Message: "execution timeout"}
+			}
+			results[i] = Call3Result{Success: true, ReturnData: common.LeftPadBytes(holder.Big().Bytes(), 32)}
+		}
+
+		output, err := multicall3ABI.Methods["aggregate3"].Outputs.Pack(results)
+		if err != nil {
+			return nil, err
+		}
+		return hexutil.Bytes(output), nil
+	})
+
+	client := newTestClient(t, server)
+	client.SetMulticallSize(3)
+
+	addresses := testAddresses(7)
+	results, err := client.GetBalancesMulticall(addresses)
+
+	// Ошибка одного фрагмента не должна отбрасывать результаты остальных
+	var partial *PartialError
+	if !errors.As(err, &partial) || partial.Failed != 3 || partial.Total != 7 {
+		t.Fatalf("Ожидалось 3 неудачные позиции из 7, получено %v", err)
+	}
+
+	for i, result := range results {
+		failed := i >= 3 && i < 6
+		if failed != (result.Err != nil) {
+			t.Fatalf("Позиция %d: ошибка %v", i, result.Err)
+		}
+		if !failed && result.Balance.Cmp(addresses[i].Big()) != 0 {
+			t.Fatalf("Неверный баланс позиции %d: %v", i, result.Balance)
+		}
+	}
+}
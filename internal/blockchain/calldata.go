@@ -0,0 +1,325 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	CallSourceABI       = "ABI"
+	CallSourceSelectors = "selector database"
+)
+
+var builtinCallABIs = []struct {
+	name string
+	abi  *abi.ABI
+}{
+	{"ERC-20", &erc20ABI},
+	{"ERC-721", &erc721ABI},
+	{"ERC-1155", &erc1155ABI},
+}
+
+type DecodedArg struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+type DecodedCall struct {
+	Selector  [4]byte
+	Signature string
+	Source    string
+	Args      []DecodedArg
+}
+
+type SelectorDB map[[4]byte][]string
+
+func LoadSelectorDB(path string) (SelectorDB, error) {
+	db := make(SelectorDB)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading selector database: %w", err)
+	}
+
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing selector database: %w", err)
+	}
+
+	for key, raw := range entries {
+		selector, err := hexutil.Decode(key)
+		if err != nil || len(selector) != 4 {
+			return nil, fmt.Errorf("invalid selector %q in selector database", key)
+		}
+
+		var signatures []string
+		if err := json.Unmarshal(raw, &signatures); err != nil {
+			var signature string
+			if err := json.Unmarshal(raw, &signature); err != nil {
+				return nil, fmt.Errorf("invalid signatures for selector %s: expected a string or a list", key)
+			}
+			signatures = []string{signature}
+		}
+
+		var id [4]byte
+		copy(id[:], selector)
+		db[id] = append(db[id], signatures...)
+	}
+
+	return db, nil
+}
+
+type CallDecoder struct {
+	ABIs      []*abi.ABI
+	Selectors SelectorDB
+}
+
+func (d *CallDecoder) Decode(data []byte) (*DecodedCall, bool) {
+	if len(data) < 4 {
+		return nil, false
+	}
+
+	for _, contractABI := range d.ABIs {
+		if call, ok := decodeWithABI(contractABI, data, CallSourceABI); ok {
+			return call, true
+		}
+	}
+
+	if call, ok := decodeBuiltin(data); ok {
+		return call, true
+	}
+
+	var selector [4]byte
+	copy(selector[:], data[:4])
+
+	for _, signature := range d.Selectors[selector] {
+		method, err := ParseMethodSignature(signature)
+		if err != nil || !bytes.Equal(method.ID, selector[:]) {
+			continue
+		}
+
+		values, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			continue
+		}
+		if packed, err := method.Inputs.Pack(values...); err != nil || !bytes.Equal(packed, data[4:]) {
+			continue
+		}
+
+		return newDecodedCall(method, values, CallSourceSelectors), true
+	}
+
+	return nil, false
+}
+
+func decodeBuiltin(data []byte) (*DecodedCall, bool) {
+	var (
+		decoded *DecodedCall
+		sources []string
+	)
+	for _, builtin := range builtinCallABIs {
+		call, ok := decodeWithABI(builtin.abi, data, builtin.name)
+		if !ok {
+			continue
+		}
+		if decoded == nil {
+			decoded = call
+		} else if call.Signature == decoded.Signature {
+			for i, arg := range call.Args {
+				if arg.Name != decoded.Args[i].Name {
+					decoded.Args[i].Name += "/" + arg.Name
+				}
+			}
+		} else {
+			continue
+		}
+		sources = append(sources, builtin.name)
+	}
+
+	if decoded == nil {
+		return nil, false
+	}
+	decoded.Source = strings.Join(sources, "/")
+	return decoded, true
+}
+
+func decodeWithABI(contractABI *abi.ABI, data []byte, source string) (*DecodedCall, bool) {
+	method, err := contractABI.MethodById(data[:4])
+	if err != nil {
+		return nil, false
+	}
+
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, false
+	}
+
+	return newDecodedCall(method, values, source), true
+}
+
+func newDecodedCall(method *abi.Method, values []interface{}, source string) *DecodedCall {
+	call := &DecodedCall{Signature: method.Sig, Source: source}
+	copy(call.Selector[:], method.ID)
+
+	for i, input := range method.Inputs {
+		call.Args = append(call.Args, DecodedArg{
+			Name:  input.Name,
+			Type:  input.Type.String(),
+			Value: FormatABIValue(values[i]),
+		})
+	}
+
+	return call
+}
+
+func ParseMethodSignature(signature string) (*abi.Method, error) {
+	signature = strings.ReplaceAll(signature, " ", "")
+
+	open := strings.Index(signature, "(")
+	if open <= 0 || !strings.HasSuffix(signature, ")") {
+		return nil, fmt.Errorf("invalid method signature %q", signature)
+	}
+
+	name := signature[:open]
+	types, err := splitSignatureTypes(signature[open+1 : len(signature)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid method signature %q: %w", signature, err)
+	}
+
+	inputs := make(abi.Arguments, len(types))
+	for i, typ := range types {
+		marshaling, err := signatureArgument(typ, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid method signature %q: %w", signature, err)
+		}
+
+		parsed, err := abi.NewType(marshaling.Type, "", marshaling.Components)
+		if err != nil {
+			return nil, fmt.Errorf("invalid method signature %q: %w", signature, err)
+		}
+		inputs[i] = abi.Argument{Type: parsed}
+	}
+
+	method := abi.NewMethod(name, name, abi.Function, "nonpayable", false, false, inputs, nil)
+	return &method, nil
+}
+
+func splitSignatureTypes(list string) ([]string, error) {
+	if list == "" {
+		return nil, nil
+	}
+
+	var (
+		types []string
+		depth int
+		start int
+	)
+	for i, r := range list {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses")
+			}
+		case ',':
+			if depth == 0 {
+				types = append(types, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses")
+	}
+
+	types = append(types, list[start:])
+	for _, typ := range types {
+		if typ == "" {
+			return nil, fmt.Errorf("empty parameter type")
+		}
+	}
+	return types, nil
+}
+
+func signatureArgument(typ string, name string) (abi.ArgumentMarshaling, error) {
+	if !strings.HasPrefix(typ, "(") {
+		return abi.ArgumentMarshaling{Name: name, Type: typ}, nil
+	}
+
+	closing := strings.LastIndex(typ, ")")
+	members, err := splitSignatureTypes(typ[1:closing])
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+
+	argument := abi.ArgumentMarshaling{Name: name, Type: "tuple" + typ[closing+1:]}
+	for i, member := range members {
+		component, err := signatureArgument(member, fmt.Sprintf("field%d", i))
+		if err != nil {
+			return abi.ArgumentMarshaling{}, err
+		}
+		argument.Components = append(argument.Components, component)
+	}
+	return argument, nil
+}
+
+func FormatABIValue(value interface{}) string {
+	switch v := value.(type) {
+	case common.Address:
+		return v.Hex()
+	case []byte:
+		return hexutil.Encode(v)
+	case *big.Int:
+		return v.String()
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			raw := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(raw), rv)
+			return hexutil.Encode(raw)
+		}
+		return formatABIList(rv, "[", "]")
+	case reflect.Slice:
+		return formatABIList(rv, "[", "]")
+	case reflect.Struct:
+		return formatABIList(rv, "(", ")")
+	}
+
+	return fmt.Sprint(value)
+}
+
+func formatABIList(rv reflect.Value, open, closing string) string {
+	count := rv.Len
+	item := rv.Index
+	if rv.Kind() == reflect.Struct {
+		count = rv.NumField
+		item = rv.Field
+	}
+
+	formatted := make([]string, count())
+	for i := range formatted {
+		formatted[i] = FormatABIValue(item(i).Interface())
+	}
+	return open + strings.Join(formatted, ", ") + closing
+}
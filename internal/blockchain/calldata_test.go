@@ -0,0 +1,152 @@
+package blockchain
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestDecodeBuiltinCall(t *testing.T) {
+	data, err := erc20ABI.Pack("transfer", testHolder, big.NewInt(25))
+	if err != nil {
+		t.Fatalf("Ошибка упаковки: %v", err)
+	}
+
+	decoder := &CallDecoder{}
+	call, ok := decoder.Decode(data)
+	if !ok {
+		t.Fatal("Вызов transfer не распознан")
+	}
+	if call.Source != "ERC-20" || call.Signature != "transfer(address,uint256)" {
+		t.Errorf("Неверный вызов: %s из %s", call.Signature, call.Source)
+	}
+	if len(call.Args) != 2 || call.Args[0].Value != testHolder.Hex() || call.Args[1].Name != "amount" || call.Args[1].Value != "25" {
+		t.Errorf("Неверные аргументы: %+v", call.Args)
+	}
+
+	data, err = erc721ABI.Pack("setApprovalForAll", testHolder, true)
+	if err != nil {
+		t.Fatalf("Ошибка упаковки: %v", err)
+	}
+	if call, ok := decoder.Decode(data); !ok || call.Source != "ERC-721/ERC-1155" {
+		t.Errorf("Вызов setApprovalForAll не распознан как ERC-721/ERC-1155: %+v", call)
+	}
+
+	data, err = erc721ABI.Pack("safeTransferFrom", testHolder, testHolder, big.NewInt(7))
+	if err != nil {
+		t.Fatalf("Ошибка упаковки: %v", err)
+	}
+	if call, ok := decoder.Decode(data); !ok || call.Source != "ERC-721" {
+		t.Errorf("Вызов safeTransferFrom не распознан как ERC-721: %+v", call)
+	}
+}
+
+func TestDecodeSharedSelector(t *testing.T) {
+	// transferFrom ERC-20 и ERC-721 имеют одинаковый селектор
+	data, err := erc721ABI.Pack("transferFrom", testHolder, testHolder, big.NewInt(7))
+	if err != nil {
+		t.Fatalf("Ошибка упаковки: %v", err)
+	}
+
+	call, ok := (&CallDecoder{}).Decode(data)
+	if !ok {
+		t.Fatal("Вызов transferFrom не распознан")
+	}
+	if call.Source != "ERC-20/ERC-721" {
+		t.Errorf("Общий селектор должен помечаться обоими стандартами, получено %s", call.Source)
+	}
+	if call.Args[2].Name != "amount/tokenId" || call.Args[2].Value != "7" {
+		t.Errorf("Неверный аргумент: %+v", call.Args[2])
+	}
+}
+
+func TestDecodeUserABIFirst(t *testing.T) {
+	userABI, err := abi.JSON(strings.NewReader(`[
+		{"type":"function","name":"transfer","inputs":[{"name":"recipient","type":"address"},{"name":"value","type":"uint256"}],"outputs":[]}
+	]`))
+	if err != nil {
+		t.Fatalf("Ошибка разбора ABI: %v", err)
+	}
+
+	data, _ := erc20ABI.Pack("transfer", testHolder, big.NewInt(1))
+	call, ok := (&CallDecoder{ABIs: []*abi.ABI{&userABI}}).Decode(data)
+	if !ok || call.Source != CallSourceABI || call.Args[0].Name != "recipient" {
+		t.Errorf("ABI пользователя должен иметь приоритет: %+v", call)
+	}
+}
+
+func TestDecodeWithSelectorDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "selectors.json")
+	// Некорректные сигнатуры в базе пропускаются при декодировании
+	content := `{
+		"0x12345678": "broken(",
+		"0xa9059cbb": ["transfer(address,uint256)"],
+		"` + selectorHex("swap((address,uint256)[],bytes32,string)") + `": "swap((address,uint256)[],bytes32,string)"
+	}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Ошибка записи базы: %v", err)
+	}
+
+	db, err := LoadSelectorDB(path)
+	if err != nil {
+		t.Fatalf("Ошибка загрузки базы: %v", err)
+	}
+
+	method, err := ParseMethodSignature("swap((address,uint256)[], bytes32, string)")
+	if err != nil {
+		t.Fatalf("Ошибка разбора сигнатуры: %v", err)
+	}
+
+	type leg struct {
+		Field0 [20]byte
+		Field1 *big.Int
+	}
+	var id [32]byte
+	id[31] = 7
+	packed, err := method.Inputs.Pack([]leg{{Field0: testHolder, Field1: big.NewInt(3)}}, id, "memo")
+	if err != nil {
+		t.Fatalf("Ошибка упаковки: %v", err)
+	}
+	data := append(append([]byte{}, method.ID...), packed...)
+
+	call, ok := (&CallDecoder{Selectors: db}).Decode(data)
+	if !ok {
+		t.Fatal("Вызов из базы селекторов не распознан")
+	}
+	if call.Source != CallSourceSelectors || call.Signature != "swap((address,uint256)[],bytes32,string)" {
+		t.Errorf("Неверный вызов: %s из %s", call.Signature, call.Source)
+	}
+
+	expected := []string{
+		"[(" + testHolder.Hex() + ", 3)]",
+		"0x0000000000000000000000000000000000000000000000000000000000000007",
+		`"memo"`,
+	}
+	for i, value := range expected {
+		if call.Args[i].Value != value {
+			t.Errorf("Аргумент %d: %s, ожидалось %s", i, call.Args[i].Value, value)
+		}
+	}
+
+	// Данные, которые не соответствуют сигнатуре, не декодируются
+	if _, ok := (&CallDecoder{Selectors: db}).Decode(append(method.ID, 1, 2, 3)); ok {
+		t.Error("Повреждённые данные не должны декодироваться")
+	}
+}
+
+func TestLoadSelectorDBMissing(t *testing.T) {
+	db, err := LoadSelectorDB(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || len(db) != 0 {
+		t.Errorf("Отсутствующая база должна быть пустой: %v, %v", db, err)
+	}
+}
+
+func selectorHex(signature string) string {
+	return hexutil.Encode(crypto.Keccak256([]byte(signature))[:4])
+}
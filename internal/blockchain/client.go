@@ -2,6 +2,7 @@ package blockchain
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"fmt"
 	"math/big"
 	"time"
@@ -9,13 +10,15 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 type Client struct {
-	client *ethclient.Client
-	url    string
+	client        *ethclient.Client
+	url           string
+	batchSize     int
+	multicallSize int
+	pollInterval  time.Duration
 }
 
 func NewClient(url string) (*Client, error) {
@@ -67,6 +70,18 @@ func (c *Client) GetGasPrice() (*big.Int, error) {
 	return gasPrice, nil
 }
 
+func (c *Client) FeeHistory(blocks uint64, percentiles []float64) (*ethereum.FeeHistory, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	history, err := c.client.FeeHistory(ctx, blocks, nil, percentiles)
+	if err != nil {
+		return nil, fmt.Errorf("error getting fee history: %w", err)
+	}
+
+	return history, nil
+}
+
 func (c *Client) GetNonce(address common.Address) (uint64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -115,6 +130,18 @@ func (c *Client) GetTransactionReceipt(txHash common.Hash) (*types.Receipt, erro
 	return receipt, nil
 }
 
+func (c *Client) GetTransaction(txHash common.Hash) (*types.Transaction, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, isPending, err := c.client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, false, fmt.Errorf("error getting transaction: %w", err)
+	}
+
+	return tx, isPending, nil
+}
+
 func (c *Client) WaitForTransaction(txHash common.Hash, maxAttempts int) (*types.Receipt, error) {
 	for i := 0; i < maxAttempts; i++ {
 		receipt, err := c.GetTransactionReceipt(txHash)
@@ -140,25 +167,17 @@ func (c *Client) CreateTransaction(
 	return types.NewTransaction(nonce, to, value, gasLimit, gasPrice, data)
 }
 
-func (c *Client) SignTransaction(tx *types.Transaction, privateKey *big.Int) (*types.Transaction, error) {
-	networkID, err := c.GetNetworkID()
-	if err != nil {
-		return nil, fmt.Errorf("error getting network ID for signing: %w", err)
-	}
-
-	privateKeyBytes := privateKey.Bytes()
-	if len(privateKeyBytes) < 32 {
-		padded := make([]byte, 32)
-		copy(padded[32-len(privateKeyBytes):], privateKeyBytes)
-		privateKeyBytes = padded
-	}
-
-	ecdsaPrivateKey, err := crypto.ToECDSA(privateKeyBytes)
-	if err != nil {
-		return nil, fmt.Errorf("error converting private key: %w", err)
+func (c *Client) SignTransaction(tx *types.Transaction, privateKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	chainID := tx.ChainId()
+	if tx.Type() == types.LegacyTxType {
+		networkID, err := c.GetNetworkID()
+		if err != nil {
+			return nil, fmt.Errorf("error getting network ID for signing: %w", err)
+		}
+		chainID = networkID
 	}
 
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(networkID), ecdsaPrivateKey)
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), privateKey)
 	if err != nil {
 		return nil, fmt.Errorf("error signing transaction: %w", err)
 	}
@@ -185,6 +204,10 @@ func (c *Client) EstimateGas(from common.Address, to *common.Address, value *big
 	return gasLimit, nil
 }
 
+func (c *Client) URL() string {
+	return c.url
+}
+
 func (c *Client) Close() {
 	if c.client != nil {
 		c.client.Close()
@@ -0,0 +1,146 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const DefaultPollInterval = 5 * time.Second
+
+type WaitStatus int
+
+const (
+	WaitConfirmed WaitStatus = iota
+	WaitFailed
+	WaitReorged
+	WaitTimedOut
+)
+
+func (s WaitStatus) String() string {
+	switch s {
+	case WaitConfirmed:
+		return "confirmed"
+	case WaitFailed:
+		return "failed"
+	case WaitReorged:
+		return "reorged"
+	case WaitTimedOut:
+		return "timed out"
+	default:
+		return fmt.Sprintf("WaitStatus(%d)", int(s))
+	}
+}
+
+type WaitResult struct {
+	Status        WaitStatus
+	Receipt       *types.Receipt
+	BlockHash     common.Hash
+	Confirmations uint64
+}
+
+func (c *Client) SetPollInterval(interval time.Duration) {
+	c.pollInterval = interval
+}
+
+func (c *Client) GetBlockNumber() (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	number, err := c.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error getting block number: %w", err)
+	}
+
+	return number, nil
+}
+
+func (c *Client) GetHeader(number *big.Int) (*types.Header, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	header, err := c.client.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("error getting block header: %w", err)
+	}
+
+	return header, nil
+}
+
+func (c *Client) GetConfirmations(receipt *types.Receipt) (uint64, bool, error) {
+	header, err := c.GetHeader(receipt.BlockNumber)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if header.Hash() != receipt.BlockHash {
+		return 0, false, nil
+	}
+
+	latest, err := c.GetBlockNumber()
+	if err != nil {
+		return 0, false, err
+	}
+
+	mined := receipt.BlockNumber.Uint64()
+	if latest < mined {
+		return 0, true, nil
+	}
+
+	return latest - mined + 1, true, nil
+}
+
+func (c *Client) WaitForConfirmations(txHash common.Hash, confirmations uint64, timeout time.Duration) (*WaitResult, error) {
+	if confirmations == 0 {
+		confirmations = 1
+	}
+
+	interval := c.pollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	deadline := time.Now().Add(timeout)
+	result := &WaitResult{Status: WaitTimedOut}
+
+	for {
+		if result.Receipt == nil {
+			receipt, err := c.GetTransactionReceipt(txHash)
+			if err == nil && receipt != nil {
+				result.Receipt = receipt
+				result.BlockHash = receipt.BlockHash
+			}
+		}
+
+		if result.Receipt != nil {
+			count, canonical, err := c.GetConfirmations(result.Receipt)
+			if err == nil {
+				if !canonical {
+					result.Status = WaitReorged
+					result.Confirmations = 0
+					return result, nil
+				}
+
+				result.Confirmations = count
+				if count >= confirmations {
+					if result.Receipt.Status == types.ReceiptStatusSuccessful {
+						result.Status = WaitConfirmed
+					} else {
+						result.Status = WaitFailed
+					}
+					return result, nil
+				}
+			}
+		}
+
+		if !time.Now().Add(interval).Before(deadline) {
+			return result, nil
+		}
+
+		time.Sleep(interval)
+	}
+}
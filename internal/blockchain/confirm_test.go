@@ -0,0 +1,181 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type testChain struct {
+	mu      sync.Mutex
+	head    uint64
+	headers map[uint64]*types.Header
+	receipt *types.Receipt
+}
+
+func testHeader(number uint64, fork byte) *types.Header {
+	return &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		Difficulty: big.NewInt(0),
+		GasLimit:   30000000,
+		Time:       1700000000 + number*12,
+		Extra:      []byte{fork},
+	}
+}
+
+func newConfirmServer(t *testing.T, chain *testChain) *Client {
+	server := rpctest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.Handle("eth_blockNumber", func(params []json.RawMessage) (interface{}, error) {
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		chain.head++
+		return hexutil.Uint64(chain.head), nil
+	})
+	server.Handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		var number hexutil.Uint64
+		json.Unmarshal(params[0], &number)
+
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		return chain.headers[uint64(number)], nil
+	})
+	server.Handle("eth_getTransactionReceipt", func(params []json.RawMessage) (interface{}, error) {
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		if chain.receipt == nil {
+			return nil, nil
+		}
+		return chain.receipt, nil
+	})
+
+	client := newTestClient(t, server)
+	client.SetPollInterval(time.Millisecond)
+	return client
+}
+
+func minedReceipt(header *types.Header, status uint64) *types.Receipt {
+	return &types.Receipt{
+		Status:      status,
+		TxHash:      common.HexToHash("0x01"),
+		BlockHash:   header.Hash(),
+		BlockNumber: header.Number,
+		GasUsed:     21000,
+		Logs:        []*types.Log{},
+	}
+}
+
+func TestWaitForConfirmationsConfirmed(t *testing.T) {
+	mined := testHeader(10, 0)
+	chain := &testChain{head: 9, headers: map[uint64]*types.Header{10: mined}}
+	chain.receipt = minedReceipt(mined, types.ReceiptStatusSuccessful)
+
+	client := newConfirmServer(t, chain)
+
+	result, err := client.WaitForConfirmations(common.HexToHash("0x01"), 3, time.Second)
+	if err != nil {
+		t.Fatalf("Ошибка ожидания подтверждений: %v", err)
+	}
+
+	if result.Status != WaitConfirmed {
+		t.Fatalf("Ожидался статус confirmed, получено %s", result.Status)
+	}
+
+	if result.Confirmations < 3 || result.BlockHash != mined.Hash() {
+		t.Fatalf("Неверный результат: %d подтверждений, блок %s", result.Confirmations, result.BlockHash.Hex())
+	}
+}
+
+func TestWaitForConfirmationsFailed(t *testing.T) {
+	mined := testHeader(10, 0)
+	chain := &testChain{head: 20, headers: map[uint64]*types.Header{10: mined}}
+	chain.receipt = minedReceipt(mined, types.ReceiptStatusFailed)
+
+	client := newConfirmServer(t, chain)
+
+	result, err := client.WaitForConfirmations(common.HexToHash("0x01"), 2, time.Second)
+	if err != nil {
+		t.Fatalf("Ошибка ожидания подтверждений: %v", err)
+	}
+
+	if result.Status != WaitFailed {
+		t.Fatalf("Ожидался статус failed, получено %s", result.Status)
+	}
+}
+
+func TestWaitForConfirmationsReorged(t *testing.T) {
+	mined := testHeader(10, 0)
+	chain := &testChain{head: 9, headers: map[uint64]*types.Header{10: mined}}
+	chain.receipt = minedReceipt(mined, types.ReceiptStatusSuccessful)
+
+	client := newConfirmServer(t, chain)
+
+	// Блок 10 заменяется другим блоком после первой проверки
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		chain.mu.Lock()
+		chain.headers[10] = testHeader(10, 1)
+		chain.mu.Unlock()
+	}()
+
+	result, err := client.WaitForConfirmations(common.HexToHash("0x01"), 1000, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Ошибка ожидания подтверждений: %v", err)
+	}
+
+	if result.Status != WaitReorged {
+		t.Fatalf("Ожидался статус reorged, получено %s", result.Status)
+	}
+
+	if result.BlockHash != mined.Hash() {
+		t.Fatal("Результат должен содержать хеш исходного блока")
+	}
+}
+
+func TestWaitForConfirmationsTimedOut(t *testing.T) {
+	chain := &testChain{head: 9, headers: map[uint64]*types.Header{}}
+	client := newConfirmServer(t, chain)
+
+	result, err := client.WaitForConfirmations(common.HexToHash("0x01"), 1, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Ошибка ожидания подтверждений: %v", err)
+	}
+
+	if result.Status != WaitTimedOut || result.Receipt != nil {
+		t.Fatalf("Ожидался статус timed out без квитанции, получено %s", result.Status)
+	}
+}
+
+func TestGetConfirmations(t *testing.T) {
+	mined := testHeader(10, 0)
+	chain := &testChain{head: 14, headers: map[uint64]*types.Header{10: mined}}
+	client := newConfirmServer(t, chain)
+
+	confirmations, canonical, err := client.GetConfirmations(minedReceipt(mined, types.ReceiptStatusSuccessful))
+	if err != nil {
+		t.Fatalf("Ошибка получения подтверждений: %v", err)
+	}
+
+	// eth_blockNumber возвращает 15
+	if !canonical || confirmations != 6 {
+		t.Fatalf("Ожидалось 6 подтверждений, получено %d (canonical=%v)", confirmations, canonical)
+	}
+
+	confirmations, canonical, err = client.GetConfirmations(minedReceipt(testHeader(10, 7), types.ReceiptStatusSuccessful))
+	if err != nil {
+		t.Fatalf("Ошибка получения подтверждений: %v", err)
+	}
+
+	if canonical || confirmations != 0 {
+		t.Fatal("Квитанция из вытесненного блока не должна считаться канонической")
+	}
+}
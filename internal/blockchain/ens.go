@@ -0,0 +1,162 @@
+package blockchain
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ENSRegistryAddress = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+const ensABIJSON = `[
+	{"type":"function","name":"resolver","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"addr","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"name","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"string"}]}
+]`
+
+var ensABI = mustParseABI(ensABIJSON)
+
+func IsENSName(s string) bool {
+	return !common.IsHexAddress(s) && strings.Contains(s, ".") &&
+		!strings.HasPrefix(s, ".") && !strings.HasSuffix(s, ".") && !strings.Contains(s, "..")
+}
+
+func NormalizeENSName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func checkASCIIName(name string) error {
+	for _, label := range strings.Split(NormalizeENSName(name), ".") {
+		for _, r := range label {
+			if r > unicode.MaxASCII {
+				return fmt.Errorf("ENS name %s has a non-ASCII label %q, which needs ENSIP-15 normalization and is not supported", name, label)
+			}
+		}
+	}
+	return nil
+}
+
+func NameHash(name string) common.Hash {
+	var node common.Hash
+
+	name = NormalizeENSName(name)
+	if name == "" {
+		return node
+	}
+
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node[:], labelHash)
+	}
+
+	return node
+}
+
+func ReverseNode(address common.Address) common.Hash {
+	return NameHash(strings.ToLower(address.Hex()[2:]) + ".addr.reverse")
+}
+
+func (c *Client) ResolveENS(name string) (common.Address, error) {
+	if !IsENSName(name) {
+		return common.Address{}, fmt.Errorf("invalid ENS name: %s", name)
+	}
+	if err := checkASCIIName(name); err != nil {
+		return common.Address{}, err
+	}
+
+	node := NameHash(name)
+
+	resolver, err := c.ensResolver(node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("ENS name %s has no resolver", name)
+	}
+
+	address, err := c.ensAddressCall(resolver, "addr", node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error resolving ENS name %s: %w", name, err)
+	}
+	if address == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("ENS name %s does not resolve to an address", name)
+	}
+
+	return address, nil
+}
+
+func (c *Client) LookupENS(address common.Address) (string, error) {
+	node := ReverseNode(address)
+
+	resolver, err := c.ensResolver(node)
+	if err != nil {
+		return "", err
+	}
+	if resolver == (common.Address{}) {
+		return "", nil
+	}
+
+	data, err := ensABI.Pack("name", node)
+	if err != nil {
+		return "", fmt.Errorf("error packing name call: %w", err)
+	}
+
+	result, err := c.CallContract(resolver, data)
+	if err != nil {
+		return "", fmt.Errorf("error looking up ENS name: %w", err)
+	}
+	if len(result) == 0 {
+		return "", nil
+	}
+
+	values, err := ensABI.Unpack("name", result)
+	if err != nil {
+		return "", fmt.Errorf("error decoding name result: %w", err)
+	}
+
+	name := values[0].(string)
+	if !IsENSName(name) {
+		return "", nil
+	}
+
+	forward, err := c.ResolveENS(name)
+	if err != nil || forward != address {
+		return "", nil
+	}
+
+	return name, nil
+}
+
+func (c *Client) ensResolver(node common.Hash) (common.Address, error) {
+	resolver, err := c.ensAddressCall(ENSRegistryAddress, "resolver", node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error getting ENS resolver: %w", err)
+	}
+	return resolver, nil
+}
+
+func (c *Client) ensAddressCall(contract common.Address, method string, node common.Hash) (common.Address, error) {
+	data, err := ensABI.Pack(method, node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error packing %s call: %w", method, err)
+	}
+
+	result, err := c.CallContract(contract, data)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(result) == 0 {
+		return common.Address{}, nil
+	}
+
+	values, err := ensABI.Unpack(method, result)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error decoding %s result: %w", method, err)
+	}
+
+	return values[0].(common.Address), nil
+}
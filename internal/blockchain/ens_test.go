@@ -0,0 +1,154 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type fakeENS struct {
+	resolver common.Address
+	records  map[common.Hash]common.Address
+	names    map[common.Hash]string
+}
+
+func (f *fakeENS) handle(params []json.RawMessage) (interface{}, error) {
+	args := decodeCallArgs(params[0])
+
+	method, err := ensABI.MethodById(args.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+
+	var node common.Hash
+	copy(node[:], args.Data[4:36])
+
+	switch {
+	case args.To == ENSRegistryAddress && method.Name == "resolver":
+		if _, ok := f.records[node]; !ok {
+			if _, ok := f.names[node]; !ok {
+				return hexutil.Bytes(make([]byte, 32)), nil
+			}
+		}
+		result, _ := method.Outputs.Pack(f.resolver)
+		return hexutil.Bytes(result), nil
+	case args.To == f.resolver && method.Name == "addr":
+		result, _ := method.Outputs.Pack(f.records[node])
+		return hexutil.Bytes(result), nil
+	case args.To == f.resolver && method.Name == "name":
+		result, _ := method.Outputs.Pack(f.names[node])
+		return hexutil.Bytes(result), nil
+	}
+
+	return "0x", nil
+}
+
+func newFakeENS(t *testing.T) (*Client, *fakeENS) {
+	server := rpctest.NewServer()
+	t.Cleanup(server.Close)
+
+	ens := &fakeENS{
+		resolver: common.HexToAddress("0x4976fb03C32e5B8cfe2b6cCB31c09Ba78EBaBa41"),
+		records:  make(map[common.Hash]common.Address),
+		names:    make(map[common.Hash]string),
+	}
+	server.Handle("eth_call", ens.handle)
+
+	return newTestClient(t, server), ens
+}
+
+func TestNameHash(t *testing.T) {
+	tests := map[string]string{
+		"":        "0x0000000000000000000000000000000000000000000000000000000000000000",
+		"eth":     "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+		"foo.eth": "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+		"Foo.ETH": "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+	}
+
+	for name, expected := range tests {
+		if got := NameHash(name).Hex(); got != expected {
+			t.Errorf("namehash(%q) = %s, ожидалось %s", name, got, expected)
+		}
+	}
+}
+
+func TestIsENSName(t *testing.T) {
+	for _, name := range []string{"vitalik.eth", "pay.alice.eth"} {
+		if !IsENSName(name) {
+			t.Errorf("%s должно быть ENS именем", name)
+		}
+	}
+	for _, name := range []string{"alice", "0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6", ".eth", "a..eth", "eth."} {
+		if IsENSName(name) {
+			t.Errorf("%s не должно быть ENS именем", name)
+		}
+	}
+}
+
+func TestResolveENS(t *testing.T) {
+	client, ens := newFakeENS(t)
+
+	owner := common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+	ens.records[NameHash("alice.eth")] = owner
+
+	address, err := client.ResolveENS("Alice.eth")
+	if err != nil {
+		t.Fatalf("Ошибка разрешения имени: %v", err)
+	}
+	if address != owner {
+		t.Errorf("Ожидался адрес %s, получено %s", owner.Hex(), address.Hex())
+	}
+
+	if _, err := client.ResolveENS("missing.eth"); err == nil {
+		t.Error("Должна быть ошибка для имени без резолвера")
+	}
+
+	ens.names[NameHash("empty.eth")] = ""
+	if _, err := client.ResolveENS("empty.eth"); err == nil {
+		t.Error("Должна быть ошибка для имени без адреса")
+	}
+
+	// Кириллическая «а» выглядит как латинская, но без нормализации ENSIP-15 такие имена отклоняются
+	ens.records[NameHash("\u0430lice.eth")] = owner
+	if _, err := client.ResolveENS("\u0430lice.eth"); err == nil || !strings.Contains(err.Error(), "non-ASCII") {
+		t.Errorf("Имя с не-ASCII меткой должно отклоняться, получено %v", err)
+	}
+}
+
+func TestLookupENS(t *testing.T) {
+	client, ens := newFakeENS(t)
+
+	owner := common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+	impostor := common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+
+	ens.records[NameHash("alice.eth")] = owner
+	ens.names[ReverseNode(owner)] = "alice.eth"
+	// Обратная запись указывает на чужое имя и не проходит прямую проверку
+	ens.names[ReverseNode(impostor)] = "alice.eth"
+
+	name, err := client.LookupENS(owner)
+	if err != nil {
+		t.Fatalf("Ошибка обратного разрешения: %v", err)
+	}
+	if name != "alice.eth" {
+		t.Errorf("Ожидалось имя alice.eth, получено %q", name)
+	}
+
+	name, err = client.LookupENS(impostor)
+	if err != nil {
+		t.Fatalf("Ошибка обратного разрешения: %v", err)
+	}
+	if name != "" {
+		t.Errorf("Непроверенное имя не должно возвращаться, получено %q", name)
+	}
+
+	name, err = client.LookupENS(common.HexToAddress("0x01"))
+	if err != nil || name != "" {
+		t.Errorf("Для адреса без обратной записи ожидалось пустое имя, получено %q, %v", name, err)
+	}
+}
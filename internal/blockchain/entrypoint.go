@@ -0,0 +1,67 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const entryPointABIJSON = `[
+	{"type":"function","name":"getNonce","stateMutability":"view","inputs":[{"name":"sender","type":"address"},{"name":"key","type":"uint192"}],"outputs":[{"name":"nonce","type":"uint256"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+const smartAccountABIJSON = `[
+	{"type":"function","name":"execute","stateMutability":"nonpayable","inputs":[{"name":"dest","type":"address"},{"name":"value","type":"uint256"},{"name":"func","type":"bytes"}],"outputs":[]}
+]`
+
+var (
+	entryPointABI   = mustParseABI(entryPointABIJSON)
+	smartAccountABI = mustParseABI(smartAccountABIJSON)
+)
+
+func (c *Client) entryPointCall(entryPoint common.Address, method string, args ...interface{}) (*big.Int, error) {
+	data, err := entryPointABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error packing %s call: %w", method, err)
+	}
+
+	result, err := c.CallContract(entryPoint, data)
+	if err != nil {
+		return nil, fmt.Errorf("error calling %s on EntryPoint %s: %w", method, entryPoint.Hex(), err)
+	}
+
+	values, err := entryPointABI.Unpack(method, result)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s result: %w", method, err)
+	}
+
+	return values[0].(*big.Int), nil
+}
+
+func (c *Client) GetEntryPointNonce(entryPoint common.Address, sender common.Address, key *big.Int) (*big.Int, error) {
+	if key == nil {
+		key = new(big.Int)
+	}
+	return c.entryPointCall(entryPoint, "getNonce", sender, key)
+}
+
+func (c *Client) GetEntryPointDeposit(entryPoint common.Address, account common.Address) (*big.Int, error) {
+	return c.entryPointCall(entryPoint, "balanceOf", account)
+}
+
+func PackAccountExecute(to common.Address, value *big.Int, data []byte) ([]byte, error) {
+	if value == nil {
+		value = new(big.Int)
+	}
+	if data == nil {
+		data = []byte{}
+	}
+
+	packed, err := smartAccountABI.Pack("execute", to, value, data)
+	if err != nil {
+		return nil, fmt.Errorf("error packing execute call: %w", err)
+	}
+	return packed, nil
+}
@@ -0,0 +1,161 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const erc20ABIJSON = `[
+	{"type":"function","name":"allowance","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"approve","stateMutability":"nonpayable","inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+	{"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"transferFrom","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+var erc20ABI = mustParseABI(erc20ABIJSON)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("invalid ABI definition: %v", err))
+	}
+	return parsed
+}
+
+func (c *Client) CallContract(to common.Address, data []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	msg := ethereum.CallMsg{
+		To:   &to,
+		Data: data,
+	}
+
+	result, err := c.client.CallContract(ctx, msg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error calling contract: %w", err)
+	}
+
+	return result, nil
+}
+
+func (c *Client) GetTokenBalance(token common.Address, holder common.Address) (*big.Int, error) {
+	data, err := erc20ABI.Pack("balanceOf", holder)
+	if err != nil {
+		return nil, fmt.Errorf("error packing balanceOf call: %w", err)
+	}
+
+	result, err := c.CallContract(token, data)
+	if err != nil {
+		return nil, fmt.Errorf("error getting token balance: %w", err)
+	}
+
+	return unpackUint256(erc20ABI, "balanceOf", result)
+}
+
+func (c *Client) GetTokenAllowance(token common.Address, owner common.Address, spender common.Address) (*big.Int, error) {
+	data, err := erc20ABI.Pack("allowance", owner, spender)
+	if err != nil {
+		return nil, fmt.Errorf("error packing allowance call: %w", err)
+	}
+
+	result, err := c.CallContract(token, data)
+	if err != nil {
+		return nil, fmt.Errorf("error getting token allowance: %w", err)
+	}
+
+	return unpackUint256(erc20ABI, "allowance", result)
+}
+
+func unpackUint256(contractABI abi.ABI, method string, data []byte) (*big.Int, error) {
+	values, err := contractABI.Unpack(method, data)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s result: %w", method, err)
+	}
+
+	value, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s result type %T", method, values[0])
+	}
+
+	return value, nil
+}
+
+func (c *Client) GetTokenDecimals(token common.Address) (uint8, error) {
+	data, err := erc20ABI.Pack("decimals")
+	if err != nil {
+		return 0, fmt.Errorf("error packing decimals call: %w", err)
+	}
+
+	result, err := c.CallContract(token, data)
+	if err != nil {
+		return 0, fmt.Errorf("error getting token decimals: %w", err)
+	}
+
+	values, err := erc20ABI.Unpack("decimals", result)
+	if err != nil {
+		return 0, fmt.Errorf("error decoding decimals result: %w", err)
+	}
+
+	return values[0].(uint8), nil
+}
+
+func (c *Client) GetTokenSymbol(token common.Address) (string, error) {
+	data, err := erc20ABI.Pack("symbol")
+	if err != nil {
+		return "", fmt.Errorf("error packing symbol call: %w", err)
+	}
+
+	result, err := c.CallContract(token, data)
+	if err != nil {
+		return "", fmt.Errorf("error getting token symbol: %w", err)
+	}
+
+	values, err := erc20ABI.Unpack("symbol", result)
+	if err != nil {
+		return "", fmt.Errorf("error decoding symbol result: %w", err)
+	}
+
+	return values[0].(string), nil
+}
+
+func PackTokenTransfer(to common.Address, amount *big.Int) ([]byte, error) {
+	data, err := erc20ABI.Pack("transfer", to, amount)
+	if err != nil {
+		return nil, fmt.Errorf("error packing transfer call: %w", err)
+	}
+	return data, nil
+}
+
+func PackTokenApprove(spender common.Address, amount *big.Int) ([]byte, error) {
+	data, err := erc20ABI.Pack("approve", spender, amount)
+	if err != nil {
+		return nil, fmt.Errorf("error packing approve call: %w", err)
+	}
+	return data, nil
+}
+
+func DecodeTokenTransferCall(data []byte) (common.Address, *big.Int, bool) {
+	method := erc20ABI.Methods["transfer"]
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return common.Address{}, nil, false
+	}
+
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return common.Address{}, nil, false
+	}
+
+	return values[0].(common.Address), values[1].(*big.Int), true
+}
@@ -0,0 +1,97 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	TransferEventTopic       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	ApprovalEventTopic       = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	ApprovalForAllEventTopic = crypto.Keccak256Hash([]byte("ApprovalForAll(address,address,bool)"))
+)
+
+type TokenTransfer struct {
+	Token common.Address
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+}
+
+func DecodeTokenTransfer(log types.Log) (*TokenTransfer, bool) {
+	if len(log.Topics) != 3 || log.Topics[0] != TransferEventTopic || len(log.Data) != 32 {
+		return nil, false
+	}
+
+	return &TokenTransfer{
+		Token: log.Address,
+		From:  common.BytesToAddress(log.Topics[1].Bytes()),
+		To:    common.BytesToAddress(log.Topics[2].Bytes()),
+		Value: new(big.Int).SetBytes(log.Data),
+	}, true
+}
+
+func AddressTopics(addresses []common.Address) []common.Hash {
+	topics := make([]common.Hash, len(addresses))
+	for i, address := range addresses {
+		topics[i] = common.BytesToHash(address.Bytes())
+	}
+	return topics
+}
+
+func (c *Client) IsWebSocket() bool {
+	return strings.HasPrefix(c.url, "ws://") || strings.HasPrefix(c.url, "wss://")
+}
+
+func (c *Client) GetChainID() (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	chainID, err := c.client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting chain ID: %w", err)
+	}
+
+	return chainID, nil
+}
+
+func (c *Client) GetBlock(number *big.Int) (*types.Block, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	block, err := c.client.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("error getting block: %w", err)
+	}
+
+	return block, nil
+}
+
+func (c *Client) FilterLogs(query ethereum.FilterQuery) ([]types.Log, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	logs, err := c.client.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error filtering logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+func (c *Client) SubscribeNewHeads(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	sub, err := c.client.SubscribeNewHead(ctx, ch)
+	if err != nil {
+		return nil, fmt.Errorf("error subscribing to new heads: %w", err)
+	}
+
+	return sub, nil
+}
@@ -0,0 +1,141 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const DefaultMulticallSize = 500
+
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+const multicall3ABIJSON = `[
+	{"type":"function","name":"aggregate3","stateMutability":"payable","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],"outputs":[{"name":"returnData","type":"tuple[]","components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]},
+	{"type":"function","name":"getEthBalance","stateMutability":"view","inputs":[{"name":"addr","type":"address"}],"outputs":[{"name":"balance","type":"uint256"}]}
+]`
+
+var multicall3ABI = mustParseABI(multicall3ABIJSON)
+
+type Call3 struct {
+	Target       common.Address `abi:"target"`
+	AllowFailure bool           `abi:"allowFailure"`
+	CallData     []byte         `abi:"callData"`
+}
+
+type Call3Result struct {
+	Success    bool   `abi:"success"`
+	ReturnData []byte `abi:"returnData"`
+	Err        error
+}
+
+func (c *Client) SetMulticallSize(size int) {
+	c.multicallSize = size
+}
+
+func (c *Client) Aggregate3(calls []Call3) ([]Call3Result, error) {
+	size := c.chunkSize(DefaultMulticallSize, c.multicallSize)
+	results := make([]Call3Result, 0, len(calls))
+	failed := 0
+
+	for start := 0; start < len(calls); start += size {
+		end := start + size
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		decoded, err := c.aggregate3Chunk(calls[start:end])
+		if err != nil {
+			decoded = make([]Call3Result, end-start)
+			for i := range decoded {
+				decoded[i].Err = err
+			}
+			failed += len(decoded)
+		}
+
+		results = append(results, decoded...)
+	}
+
+	if failed > 0 {
+		return results, &PartialError{Failed: failed, Total: len(calls)}
+	}
+
+	return results, nil
+}
+
+func (c *Client) aggregate3Chunk(calls []Call3) ([]Call3Result, error) {
+	data, err := multicall3ABI.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, fmt.Errorf("error packing aggregate3 call: %w", err)
+	}
+
+	output, err := c.CallContract(Multicall3Address, data)
+	if err != nil {
+		return nil, fmt.Errorf("error calling multicall: %w", err)
+	}
+
+	var decoded []Call3Result
+	if err := multicall3ABI.UnpackIntoInterface(&decoded, "aggregate3", output); err != nil {
+		return nil, fmt.Errorf("error decoding aggregate3 result: %w", err)
+	}
+
+	if len(decoded) != len(calls) {
+		return nil, fmt.Errorf("multicall returned %d results for %d calls", len(decoded), len(calls))
+	}
+
+	return decoded, nil
+}
+
+func (c *Client) GetBalancesMulticall(addresses []common.Address) ([]BalanceResult, error) {
+	calls := make([]Call3, len(addresses))
+	for i, address := range addresses {
+		data, err := multicall3ABI.Pack("getEthBalance", address)
+		if err != nil {
+			return nil, fmt.Errorf("error packing getEthBalance call: %w", err)
+		}
+		calls[i] = Call3{Target: Multicall3Address, AllowFailure: true, CallData: data}
+	}
+
+	return c.aggregateBalances(addresses, calls, multicall3ABI, "getEthBalance")
+}
+
+func (c *Client) GetTokenBalancesMulticall(token common.Address, holders []common.Address) ([]BalanceResult, error) {
+	calls := make([]Call3, len(holders))
+	for i, holder := range holders {
+		data, err := erc20ABI.Pack("balanceOf", holder)
+		if err != nil {
+			return nil, fmt.Errorf("error packing balanceOf call: %w", err)
+		}
+		calls[i] = Call3{Target: token, AllowFailure: true, CallData: data}
+	}
+
+	return c.aggregateBalances(holders, calls, erc20ABI, "balanceOf")
+}
+
+func (c *Client) aggregateBalances(addresses []common.Address, calls []Call3, contractABI abi.ABI, method string) ([]BalanceResult, error) {
+	callResults, err := c.Aggregate3(calls)
+	var partial *PartialError
+	if err != nil && !errors.As(err, &partial) {
+		return nil, err
+	}
+
+	results := make([]BalanceResult, len(addresses))
+	for i, address := range addresses {
+		results[i].Address = address
+
+		if callResults[i].Err != nil {
+			results[i].Err = callResults[i].Err
+			continue
+		}
+		if !callResults[i].Success {
+			results[i].Err = fmt.Errorf("%s call reverted", method)
+			continue
+		}
+
+		results[i].Balance, results[i].Err = unpackUint256(contractABI, method, callResults[i].ReturnData)
+	}
+
+	return results, partialError(results)
+}
@@ -0,0 +1,229 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type NFTStandard string
+
+const (
+	ERC721  NFTStandard = "ERC-721"
+	ERC1155 NFTStandard = "ERC-1155"
+)
+
+var (
+	erc721InterfaceID  = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	erc1155InterfaceID = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+)
+
+const erc165ABIJSON = `[
+	{"type":"function","name":"supportsInterface","stateMutability":"view","inputs":[{"name":"interfaceId","type":"bytes4"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+const erc721ABIJSON = `[
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"isApprovedForAll","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"operator","type":"address"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"setApprovalForAll","stateMutability":"nonpayable","inputs":[{"name":"operator","type":"address"},{"name":"approved","type":"bool"}],"outputs":[]},
+	{"type":"function","name":"ownerOf","stateMutability":"view","inputs":[{"name":"tokenId","type":"uint256"}],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"tokenURI","stateMutability":"view","inputs":[{"name":"tokenId","type":"uint256"}],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"safeTransferFrom","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"transferFrom","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"approve","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"outputs":[]}
+]`
+
+const erc1155ABIJSON = `[
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"account","type":"address"},{"name":"id","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"balanceOfBatch","stateMutability":"view","inputs":[{"name":"accounts","type":"address[]"},{"name":"ids","type":"uint256[]"}],"outputs":[{"name":"","type":"uint256[]"}]},
+	{"type":"function","name":"uri","stateMutability":"view","inputs":[{"name":"id","type":"uint256"}],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"isApprovedForAll","stateMutability":"view","inputs":[{"name":"account","type":"address"},{"name":"operator","type":"address"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"setApprovalForAll","stateMutability":"nonpayable","inputs":[{"name":"operator","type":"address"},{"name":"approved","type":"bool"}],"outputs":[]},
+	{"type":"function","name":"safeTransferFrom","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"id","type":"uint256"},{"name":"amount","type":"uint256"},{"name":"data","type":"bytes"}],"outputs":[]}
+]`
+
+var (
+	erc165ABI  = mustParseABI(erc165ABIJSON)
+	erc721ABI  = mustParseABI(erc721ABIJSON)
+	erc1155ABI = mustParseABI(erc1155ABIJSON)
+)
+
+func (c *Client) DetectNFTStandard(contract common.Address) (NFTStandard, error) {
+	for _, candidate := range []struct {
+		standard NFTStandard
+		id       [4]byte
+	}{{ERC721, erc721InterfaceID}, {ERC1155, erc1155InterfaceID}} {
+		supported, err := c.supportsInterface(contract, candidate.id)
+		if err != nil {
+			return "", err
+		}
+		if supported {
+			return candidate.standard, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s is not an ERC-721 or ERC-1155 contract", contract.Hex())
+}
+
+func (c *Client) supportsInterface(contract common.Address, id [4]byte) (bool, error) {
+	data, err := erc165ABI.Pack("supportsInterface", id)
+	if err != nil {
+		return false, fmt.Errorf("error packing supportsInterface call: %w", err)
+	}
+
+	result, err := c.CallContract(contract, data)
+	if err != nil {
+		return false, fmt.Errorf("error checking interface support: %w", err)
+	}
+	if len(result) == 0 {
+		return false, nil
+	}
+
+	values, err := erc165ABI.Unpack("supportsInterface", result)
+	if err != nil {
+		return false, nil
+	}
+
+	return values[0].(bool), nil
+}
+
+func (c *Client) GetNFTOwner(contract common.Address, tokenID *big.Int) (common.Address, error) {
+	data, err := erc721ABI.Pack("ownerOf", tokenID)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error packing ownerOf call: %w", err)
+	}
+
+	result, err := c.CallContract(contract, data)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error getting owner of token %s: %w", tokenID, err)
+	}
+
+	values, err := erc721ABI.Unpack("ownerOf", result)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error decoding ownerOf result: %w", err)
+	}
+
+	return values[0].(common.Address), nil
+}
+
+func (c *Client) GetNFTCount(contract common.Address, owner common.Address) (*big.Int, error) {
+	data, err := erc721ABI.Pack("balanceOf", owner)
+	if err != nil {
+		return nil, fmt.Errorf("error packing balanceOf call: %w", err)
+	}
+
+	result, err := c.CallContract(contract, data)
+	if err != nil {
+		return nil, fmt.Errorf("error getting NFT balance: %w", err)
+	}
+
+	return unpackUint256(erc721ABI, "balanceOf", result)
+}
+
+func (c *Client) GetNFTBalances(contract common.Address, owner common.Address, ids []*big.Int) ([]*big.Int, error) {
+	accounts := make([]common.Address, len(ids))
+	for i := range accounts {
+		accounts[i] = owner
+	}
+
+	data, err := erc1155ABI.Pack("balanceOfBatch", accounts, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error packing balanceOfBatch call: %w", err)
+	}
+
+	result, err := c.CallContract(contract, data)
+	if err != nil {
+		return nil, fmt.Errorf("error getting NFT balances: %w", err)
+	}
+
+	values, err := erc1155ABI.Unpack("balanceOfBatch", result)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding balanceOfBatch result: %w", err)
+	}
+
+	balances := values[0].([]*big.Int)
+	if len(balances) != len(ids) {
+		return nil, fmt.Errorf("balanceOfBatch returned %d balances for %d ids", len(balances), len(ids))
+	}
+
+	return balances, nil
+}
+
+func (c *Client) GetNFTURI(contract common.Address, standard NFTStandard, tokenID *big.Int) (string, error) {
+	contractABI, method := erc721ABI, "tokenURI"
+	if standard == ERC1155 {
+		contractABI, method = erc1155ABI, "uri"
+	}
+
+	data, err := contractABI.Pack(method, tokenID)
+	if err != nil {
+		return "", fmt.Errorf("error packing %s call: %w", method, err)
+	}
+
+	result, err := c.CallContract(contract, data)
+	if err != nil {
+		return "", fmt.Errorf("error getting %s of token %s: %w", method, tokenID, err)
+	}
+
+	values, err := contractABI.Unpack(method, result)
+	if err != nil {
+		return "", fmt.Errorf("error decoding %s result: %w", method, err)
+	}
+
+	uri := values[0].(string)
+	if standard == ERC1155 {
+		uri = strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", tokenID))
+	}
+
+	return uri, nil
+}
+
+func (c *Client) IsApprovedForAll(contract common.Address, owner common.Address, operator common.Address) (bool, error) {
+	data, err := erc721ABI.Pack("isApprovedForAll", owner, operator)
+	if err != nil {
+		return false, fmt.Errorf("error packing isApprovedForAll call: %w", err)
+	}
+
+	result, err := c.CallContract(contract, data)
+	if err != nil {
+		return false, fmt.Errorf("error checking operator approval: %w", err)
+	}
+
+	values, err := erc721ABI.Unpack("isApprovedForAll", result)
+	if err != nil {
+		return false, fmt.Errorf("error decoding isApprovedForAll result: %w", err)
+	}
+
+	return values[0].(bool), nil
+}
+
+func PackSetApprovalForAll(operator common.Address, approved bool) ([]byte, error) {
+	data, err := erc721ABI.Pack("setApprovalForAll", operator, approved)
+	if err != nil {
+		return nil, fmt.Errorf("error packing setApprovalForAll call: %w", err)
+	}
+	return data, nil
+}
+
+func PackNFTTransfer(standard NFTStandard, from, to common.Address, tokenID, amount *big.Int) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	switch standard {
+	case ERC721:
+		data, err = erc721ABI.Pack("safeTransferFrom", from, to, tokenID)
+	case ERC1155:
+		data, err = erc1155ABI.Pack("safeTransferFrom", from, to, tokenID, amount, []byte{})
+	default:
+		return nil, fmt.Errorf("unsupported NFT standard %q", standard)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error packing safeTransferFrom call: %w", err)
+	}
+
+	return data, nil
+}
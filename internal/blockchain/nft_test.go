@@ -0,0 +1,145 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	testERC721  = common.HexToAddress("0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D")
+	testERC1155 = common.HexToAddress("0x76BE3b62873462d2142405439777e971754E8E77")
+	testHolder  = common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+)
+
+func packResult(t *testing.T, contractABI abi.ABI, method string, values ...interface{}) hexutil.Bytes {
+	result, err := contractABI.Methods[method].Outputs.Pack(values...)
+	if err != nil {
+		t.Fatalf("Ошибка упаковки результата %s: %v", method, err)
+	}
+	return result
+}
+
+func newFakeNFT(t *testing.T) *Client {
+	server := rpctest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		args := decodeCallArgs(params[0])
+
+		if method, err := erc165ABI.MethodById(args.Data[:4]); err == nil {
+			values, _ := method.Inputs.Unpack(args.Data[4:])
+			id := values[0].([4]byte)
+			supported := (args.To == testERC721 && id == erc721InterfaceID) ||
+				(args.To == testERC1155 && id == erc1155InterfaceID)
+			return packResult(t, erc165ABI, "supportsInterface", supported), nil
+		}
+
+		contractABI := erc721ABI
+		if args.To == testERC1155 {
+			contractABI = erc1155ABI
+		}
+		method, err := contractABI.MethodById(args.Data[:4])
+		if err != nil {
+			return "0x", nil
+		}
+		values, _ := method.Inputs.Unpack(args.Data[4:])
+
+		switch method.Name {
+		case "ownerOf":
+			return packResult(t, contractABI, method.Name, testHolder), nil
+		case "balanceOf":
+			return packResult(t, contractABI, method.Name, big.NewInt(3)), nil
+		case "balanceOfBatch":
+			ids := values[1].([]*big.Int)
+			balances := make([]*big.Int, len(ids))
+			for i, id := range ids {
+				balances[i] = new(big.Int).Mul(id, big.NewInt(10))
+			}
+			return packResult(t, contractABI, method.Name, balances), nil
+		case "tokenURI":
+			return packResult(t, contractABI, method.Name, "ipfs://QmHash/"+values[0].(*big.Int).String()), nil
+		case "uri":
+			return packResult(t, contractABI, method.Name, "https://example.com/{id}.json"), nil
+		}
+		return "0x", nil
+	})
+
+	return newTestClient(t, server)
+}
+
+func TestDetectNFTStandard(t *testing.T) {
+	client := newFakeNFT(t)
+
+	tests := map[common.Address]NFTStandard{testERC721: ERC721, testERC1155: ERC1155}
+	for contract, expected := range tests {
+		standard, err := client.DetectNFTStandard(contract)
+		if err != nil || standard != expected {
+			t.Errorf("Стандарт %s: получено %q, %v; ожидалось %q", contract.Hex(), standard, err, expected)
+		}
+	}
+
+	if _, err := client.DetectNFTStandard(testHolder); err == nil {
+		t.Error("Адрес без ERC-165 не должен определяться как NFT")
+	}
+}
+
+func TestNFTQueries(t *testing.T) {
+	client := newFakeNFT(t)
+
+	owner, err := client.GetNFTOwner(testERC721, big.NewInt(7))
+	if err != nil || owner != testHolder {
+		t.Errorf("Неверный владелец: %s, %v", owner.Hex(), err)
+	}
+
+	count, err := client.GetNFTCount(testERC721, testHolder)
+	if err != nil || count.Int64() != 3 {
+		t.Errorf("Неверное количество токенов: %v, %v", count, err)
+	}
+
+	balances, err := client.GetNFTBalances(testERC1155, testHolder, []*big.Int{big.NewInt(1), big.NewInt(2)})
+	if err != nil || len(balances) != 2 || balances[0].Int64() != 10 || balances[1].Int64() != 20 {
+		t.Errorf("Неверные балансы ERC-1155: %v, %v", balances, err)
+	}
+
+	uri, err := client.GetNFTURI(testERC721, ERC721, big.NewInt(7))
+	if err != nil || uri != "ipfs://QmHash/7" {
+		t.Errorf("Неверный tokenURI: %q, %v", uri, err)
+	}
+
+	uri, err = client.GetNFTURI(testERC1155, ERC1155, big.NewInt(0x4cce))
+	expected := "https://example.com/0000000000000000000000000000000000000000000000000000000000004cce.json"
+	if err != nil || uri != expected {
+		t.Errorf("Неверный uri ERC-1155: %q, %v", uri, err)
+	}
+}
+
+func TestPackNFTTransfer(t *testing.T) {
+	to := common.HexToAddress("0x01")
+
+	data, err := PackNFTTransfer(ERC721, testHolder, to, big.NewInt(7), nil)
+	if err != nil {
+		t.Fatalf("Ошибка упаковки ERC-721: %v", err)
+	}
+	if hexutil.Encode(data[:4]) != "0x42842e0e" {
+		t.Errorf("Неверный селектор ERC-721: %x", data[:4])
+	}
+
+	data, err = PackNFTTransfer(ERC1155, testHolder, to, big.NewInt(7), big.NewInt(2))
+	if err != nil {
+		t.Fatalf("Ошибка упаковки ERC-1155: %v", err)
+	}
+	if hexutil.Encode(data[:4]) != "0xf242432a" {
+		t.Errorf("Неверный селектор ERC-1155: %x", data[:4])
+	}
+
+	if _, err := PackNFTTransfer("ERC-20", testHolder, to, big.NewInt(7), nil); err == nil {
+		t.Error("Ожидалась ошибка для неизвестного стандарта")
+	}
+}
@@ -0,0 +1,146 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var Permit2Address = common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+
+const permitABIJSON = `[
+	{"type":"function","name":"nonces","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"DOMAIN_SEPARATOR","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"bytes32"}]},
+	{"type":"function","name":"name","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"version","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"eip712Domain","stateMutability":"view","inputs":[],"outputs":[{"name":"fields","type":"bytes1"},{"name":"name","type":"string"},{"name":"version","type":"string"},{"name":"chainId","type":"uint256"},{"name":"verifyingContract","type":"address"},{"name":"salt","type":"bytes32"},{"name":"extensions","type":"uint256[]"}]}
+]`
+
+const permit2ABIJSON = `[
+	{"type":"function","name":"allowance","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"token","type":"address"},{"name":"spender","type":"address"}],"outputs":[{"name":"amount","type":"uint160"},{"name":"expiration","type":"uint48"},{"name":"nonce","type":"uint48"}]},
+	{"type":"function","name":"nonceBitmap","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"wordPos","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+var (
+	permitABI  = mustParseABI(permitABIJSON)
+	permit2ABI = mustParseABI(permit2ABIJSON)
+)
+
+const (
+	DomainFieldName              = 0x01
+	DomainFieldVersion           = 0x02
+	DomainFieldChainID           = 0x04
+	DomainFieldVerifyingContract = 0x08
+	DomainFieldSalt              = 0x10
+)
+
+type EIP712Domain struct {
+	Fields            byte
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract common.Address
+	Salt              common.Hash
+}
+
+func (c *Client) callView(contract common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := permitABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error packing %s call: %w", method, err)
+	}
+
+	result, err := c.CallContract(contract, data)
+	if err != nil {
+		return nil, fmt.Errorf("error calling %s: %w", method, err)
+	}
+
+	values, err := permitABI.Unpack(method, result)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s result: %w", method, err)
+	}
+
+	return values, nil
+}
+
+func (c *Client) GetEIP712Domain(contract common.Address) (*EIP712Domain, error) {
+	values, err := c.callView(contract, "eip712Domain")
+	if err != nil {
+		return nil, err
+	}
+
+	fields := values[0].([1]byte)
+	return &EIP712Domain{
+		Fields:            fields[0],
+		Name:              values[1].(string),
+		Version:           values[2].(string),
+		ChainID:           values[3].(*big.Int),
+		VerifyingContract: values[4].(common.Address),
+		Salt:              values[5].([32]byte),
+	}, nil
+}
+
+func (c *Client) GetDomainSeparator(contract common.Address) (common.Hash, error) {
+	values, err := c.callView(contract, "DOMAIN_SEPARATOR")
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return values[0].([32]byte), nil
+}
+
+func (c *Client) GetContractName(contract common.Address) (string, error) {
+	values, err := c.callView(contract, "name")
+	if err != nil {
+		return "", err
+	}
+	return values[0].(string), nil
+}
+
+func (c *Client) GetContractVersion(contract common.Address) (string, error) {
+	values, err := c.callView(contract, "version")
+	if err != nil {
+		return "", err
+	}
+	return values[0].(string), nil
+}
+
+func (c *Client) GetPermitNonce(token common.Address, owner common.Address) (*big.Int, error) {
+	values, err := c.callView(token, "nonces", owner)
+	if err != nil {
+		return nil, err
+	}
+	return values[0].(*big.Int), nil
+}
+
+func (c *Client) GetPermit2Allowance(owner, token, spender common.Address) (amount *big.Int, expiration uint64, nonce uint64, err error) {
+	data, err := permit2ABI.Pack("allowance", owner, token, spender)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("error packing allowance call: %w", err)
+	}
+
+	result, err := c.CallContract(Permit2Address, data)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("error getting Permit2 allowance: %w", err)
+	}
+
+	values, err := permit2ABI.Unpack("allowance", result)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("error decoding Permit2 allowance: %w", err)
+	}
+
+	return values[0].(*big.Int), values[1].(*big.Int).Uint64(), values[2].(*big.Int).Uint64(), nil
+}
+
+func (c *Client) GetPermit2NonceBitmap(owner common.Address, wordPos *big.Int) (*big.Int, error) {
+	data, err := permit2ABI.Pack("nonceBitmap", owner, wordPos)
+	if err != nil {
+		return nil, fmt.Errorf("error packing nonceBitmap call: %w", err)
+	}
+
+	result, err := c.CallContract(Permit2Address, data)
+	if err != nil {
+		return nil, fmt.Errorf("error getting Permit2 nonce bitmap: %w", err)
+	}
+
+	return unpackUint256(permit2ABI, "nonceBitmap", result)
+}
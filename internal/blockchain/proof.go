@@ -0,0 +1,457 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	MaxBalanceSlot = 20
+
+	LayoutSolidity = "Solidity"
+	LayoutVyper    = "Vyper"
+)
+
+var (
+	ErrBalanceSlotNotFound = errors.New("no proven storage slot holds the balanceOf value")
+	ErrBalanceSlotRequired = errors.New("a zero balance matches every empty storage slot, the balances mapping slot must be given")
+)
+
+type ProofError struct {
+	Reason string
+}
+
+func (e *ProofError) Error() string {
+	return "proof verification failed: " + e.Reason
+}
+
+var blockFields = map[string]bool{
+	"parentHash": true, "sha3Uncles": true, "miner": true, "stateRoot": true,
+	"transactionsRoot": true, "receiptsRoot": true, "logsBloom": true, "difficulty": true,
+	"number": true, "gasLimit": true, "gasUsed": true, "timestamp": true, "extraData": true,
+	"mixHash": true, "nonce": true, "baseFeePerGas": true, "withdrawalsRoot": true,
+	"blobGasUsed": true, "excessBlobGas": true, "parentBeaconBlockRoot": true, "requestsHash": true,
+	"hash": true, "size": true, "totalDifficulty": true, "transactions": true, "uncles": true,
+	"withdrawals": true, "author": true,
+}
+
+type blockHeader struct {
+	ParentHash       common.Hash      `json:"parentHash"`
+	UncleHash        common.Hash      `json:"sha3Uncles"`
+	Coinbase         common.Address   `json:"miner"`
+	Root             common.Hash      `json:"stateRoot"`
+	TxHash           common.Hash      `json:"transactionsRoot"`
+	ReceiptHash      common.Hash      `json:"receiptsRoot"`
+	Bloom            types.Bloom      `json:"logsBloom"`
+	Difficulty       *hexutil.Big     `json:"difficulty"`
+	Number           *hexutil.Big     `json:"number"`
+	GasLimit         hexutil.Uint64   `json:"gasLimit"`
+	GasUsed          hexutil.Uint64   `json:"gasUsed"`
+	Time             hexutil.Uint64   `json:"timestamp"`
+	Extra            hexutil.Bytes    `json:"extraData"`
+	MixDigest        common.Hash      `json:"mixHash"`
+	Nonce            types.BlockNonce `json:"nonce"`
+	BaseFee          *hexutil.Big     `json:"baseFeePerGas"`
+	WithdrawalsHash  *common.Hash     `json:"withdrawalsRoot"`
+	BlobGasUsed      *hexutil.Uint64  `json:"blobGasUsed"`
+	ExcessBlobGas    *hexutil.Uint64  `json:"excessBlobGas"`
+	ParentBeaconRoot *common.Hash     `json:"parentBeaconBlockRoot"`
+	RequestsHash     *common.Hash     `json:"requestsHash"`
+	Hash             common.Hash      `json:"hash"`
+}
+
+type headerRLP struct {
+	ParentHash       common.Hash
+	UncleHash        common.Hash
+	Coinbase         common.Address
+	Root             common.Hash
+	TxHash           common.Hash
+	ReceiptHash      common.Hash
+	Bloom            types.Bloom
+	Difficulty       *big.Int
+	Number           *big.Int
+	GasLimit         uint64
+	GasUsed          uint64
+	Time             uint64
+	Extra            []byte
+	MixDigest        common.Hash
+	Nonce            types.BlockNonce
+	BaseFee          *big.Int     `rlp:"optional"`
+	WithdrawalsHash  *common.Hash `rlp:"optional"`
+	BlobGasUsed      *uint64      `rlp:"optional"`
+	ExcessBlobGas    *uint64      `rlp:"optional"`
+	ParentBeaconRoot *common.Hash `rlp:"optional"`
+	RequestsHash     *common.Hash `rlp:"optional"`
+}
+
+func (h *blockHeader) computeHash() (common.Hash, error) {
+	encoded := headerRLP{
+		ParentHash:       h.ParentHash,
+		UncleHash:        h.UncleHash,
+		Coinbase:         h.Coinbase,
+		Root:             h.Root,
+		TxHash:           h.TxHash,
+		ReceiptHash:      h.ReceiptHash,
+		Bloom:            h.Bloom,
+		Difficulty:       new(big.Int),
+		Number:           new(big.Int),
+		GasLimit:         uint64(h.GasLimit),
+		GasUsed:          uint64(h.GasUsed),
+		Time:             uint64(h.Time),
+		Extra:            h.Extra,
+		MixDigest:        h.MixDigest,
+		Nonce:            h.Nonce,
+		WithdrawalsHash:  h.WithdrawalsHash,
+		ParentBeaconRoot: h.ParentBeaconRoot,
+		RequestsHash:     h.RequestsHash,
+	}
+	if h.Difficulty != nil {
+		encoded.Difficulty = h.Difficulty.ToInt()
+	}
+	if h.Number != nil {
+		encoded.Number = h.Number.ToInt()
+	}
+	if h.BaseFee != nil {
+		encoded.BaseFee = h.BaseFee.ToInt()
+	}
+	if h.BlobGasUsed != nil {
+		encoded.BlobGasUsed = (*uint64)(h.BlobGasUsed)
+	}
+	if h.ExcessBlobGas != nil {
+		encoded.ExcessBlobGas = (*uint64)(h.ExcessBlobGas)
+	}
+
+	data, err := rlp.EncodeToBytes(&encoded)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error encoding block header: %w", err)
+	}
+	return crypto.Keccak256Hash(data), nil
+}
+
+type BlockHeader struct {
+	Number    uint64
+	Hash      common.Hash
+	StateRoot common.Hash
+}
+
+func (c *Client) GetVerifiedHeader(blockHash *common.Hash) (*BlockHeader, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var raw json.RawMessage
+	var err error
+	if blockHash != nil {
+		err = c.client.Client().CallContext(ctx, &raw, "eth_getBlockByHash", *blockHash, false)
+	} else {
+		err = c.client.Client().CallContext(ctx, &raw, "eth_getBlockByNumber", "latest", false)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting block header: %w", err)
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, fmt.Errorf("block not found")
+	}
+
+	return verifyHeader(raw, blockHash)
+}
+
+func verifyHeader(raw json.RawMessage, expected *common.Hash) (*BlockHeader, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("error parsing block header: %w", err)
+	}
+
+	var unknown []string
+	for name := range fields {
+		if !blockFields[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("block header has fields that cannot be hashed: %s", strings.Join(unknown, ", "))
+	}
+
+	var header blockHeader
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("error parsing block header: %w", err)
+	}
+	if header.Number == nil {
+		return nil, fmt.Errorf("block header has no number")
+	}
+
+	computed, err := header.computeHash()
+	if err != nil {
+		return nil, err
+	}
+	if computed != header.Hash {
+		return nil, &ProofError{Reason: fmt.Sprintf("block header hashes to %s, but the node reported %s", computed.Hex(), header.Hash.Hex())}
+	}
+	if expected != nil && computed != *expected {
+		return nil, &ProofError{Reason: fmt.Sprintf("node returned block %s instead of %s", computed.Hex(), expected.Hex())}
+	}
+
+	return &BlockHeader{
+		Number:    header.Number.ToInt().Uint64(),
+		Hash:      computed,
+		StateRoot: header.Root,
+	}, nil
+}
+
+type VerifiedAccount struct {
+	Address     common.Address
+	Nonce       uint64
+	Balance     *big.Int
+	StorageRoot common.Hash
+	CodeHash    common.Hash
+	Storage     map[common.Hash]*big.Int
+}
+
+func proofDB(nodes []string) (*memorydb.Database, error) {
+	db := memorydb.New()
+	for _, node := range nodes {
+		data, err := hexutil.Decode(node)
+		if err != nil {
+			return nil, &ProofError{Reason: fmt.Sprintf("invalid proof node %q", node)}
+		}
+		if err := db.Put(crypto.Keccak256(data), data); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+type accountProof struct {
+	AccountProof []string       `json:"accountProof"`
+	Balance      *hexutil.Big   `json:"balance"`
+	CodeHash     common.Hash    `json:"codeHash"`
+	Nonce        hexutil.Uint64 `json:"nonce"`
+	StorageHash  common.Hash    `json:"storageHash"`
+	StorageProof []storageProof `json:"storageProof"`
+}
+
+type storageProof struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+func (c *Client) GetVerifiedAccount(address common.Address, header *BlockHeader, slots []common.Hash) (*VerifiedAccount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	keys := make([]string, len(slots))
+	for i, slot := range slots {
+		keys[i] = slot.Hex()
+	}
+
+	result := new(accountProof)
+	err := c.client.Client().CallContext(ctx, result, "eth_getProof", address, keys, hexutil.Uint64(header.Number))
+	if err != nil {
+		return nil, fmt.Errorf("error getting proof: %w", err)
+	}
+
+	account, err := verifyAccountProof(header.StateRoot, address, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyStorageProofs(account, slots, result.StorageProof); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func verifyAccountProof(stateRoot common.Hash, address common.Address, result *accountProof) (*VerifiedAccount, error) {
+	db, err := proofDB(result.AccountProof)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := trie.VerifyProof(stateRoot, crypto.Keccak256(address.Bytes()), db)
+	if err != nil {
+		return nil, &ProofError{Reason: fmt.Sprintf("account proof for %s does not match state root %s: %v", address.Hex(), stateRoot.Hex(), err)}
+	}
+
+	proven := types.NewEmptyStateAccount()
+	if value != nil {
+		if err := rlp.DecodeBytes(value, proven); err != nil {
+			return nil, &ProofError{Reason: fmt.Sprintf("invalid account in proof: %v", err)}
+		}
+	}
+
+	account := &VerifiedAccount{
+		Address:     address,
+		Nonce:       proven.Nonce,
+		Balance:     proven.Balance,
+		StorageRoot: proven.Root,
+		CodeHash:    common.BytesToHash(proven.CodeHash),
+		Storage:     make(map[common.Hash]*big.Int),
+	}
+
+	reportedRoot, reportedCode := result.StorageHash, result.CodeHash
+	if value == nil {
+		if reportedRoot == (common.Hash{}) {
+			reportedRoot = types.EmptyRootHash
+		}
+		if reportedCode == (common.Hash{}) {
+			reportedCode = types.EmptyCodeHash
+		}
+	}
+
+	switch {
+	case result.Balance == nil || result.Balance.ToInt().Cmp(account.Balance) != 0:
+		return nil, &ProofError{Reason: fmt.Sprintf("node reported balance %v for %s, the proof shows %s", (*big.Int)(result.Balance), address.Hex(), account.Balance)}
+	case uint64(result.Nonce) != account.Nonce:
+		return nil, &ProofError{Reason: fmt.Sprintf("node reported nonce %d for %s, the proof shows %d", result.Nonce, address.Hex(), account.Nonce)}
+	case reportedRoot != account.StorageRoot:
+		return nil, &ProofError{Reason: fmt.Sprintf("node reported storage root %s for %s, the proof shows %s", reportedRoot.Hex(), address.Hex(), account.StorageRoot.Hex())}
+	case reportedCode != account.CodeHash:
+		return nil, &ProofError{Reason: fmt.Sprintf("node reported code hash %s for %s, the proof shows %s", reportedCode.Hex(), address.Hex(), account.CodeHash.Hex())}
+	}
+
+	return account, nil
+}
+
+func verifyStorageProofs(account *VerifiedAccount, slots []common.Hash, results []storageProof) error {
+	proofs := make(map[common.Hash]storageProof, len(results))
+	for _, result := range results {
+		proofs[common.HexToHash(result.Key)] = result
+	}
+
+	for _, slot := range slots {
+		result, ok := proofs[slot]
+		if !ok {
+			return &ProofError{Reason: fmt.Sprintf("node returned no proof for storage slot %s", slot.Hex())}
+		}
+
+		var value []byte
+		if account.StorageRoot != types.EmptyRootHash {
+			db, err := proofDB(result.Proof)
+			if err != nil {
+				return err
+			}
+
+			value, err = trie.VerifyProof(account.StorageRoot, crypto.Keccak256(slot.Bytes()), db)
+			if err != nil {
+				return &ProofError{Reason: fmt.Sprintf("storage proof for slot %s does not match storage root %s: %v", slot.Hex(), account.StorageRoot.Hex(), err)}
+			}
+		}
+
+		proven := new(big.Int)
+		if value != nil {
+			var content []byte
+			if err := rlp.DecodeBytes(value, &content); err != nil {
+				return &ProofError{Reason: fmt.Sprintf("invalid storage value in proof for slot %s: %v", slot.Hex(), err)}
+			}
+			proven.SetBytes(content)
+		}
+
+		if result.Value == nil || result.Value.ToInt().Cmp(proven) != 0 {
+			return &ProofError{Reason: fmt.Sprintf("node reported %v in storage slot %s, the proof shows %s", (*big.Int)(result.Value), slot.Hex(), proven)}
+		}
+		account.Storage[slot] = proven
+	}
+
+	return nil
+}
+
+type BalanceSlot struct {
+	Key     common.Hash
+	Mapping uint64
+	Layout  string
+}
+
+func BalanceSlots(holder common.Address, mapping uint64) []BalanceSlot {
+	holderWord := common.LeftPadBytes(holder.Bytes(), 32)
+	mappingWord := common.LeftPadBytes(new(big.Int).SetUint64(mapping).Bytes(), 32)
+
+	return []BalanceSlot{
+		{Key: crypto.Keccak256Hash(holderWord, mappingWord), Mapping: mapping, Layout: LayoutSolidity},
+		{Key: crypto.Keccak256Hash(mappingWord, holderWord), Mapping: mapping, Layout: LayoutVyper},
+	}
+}
+
+type VerifiedTokenBalance struct {
+	Balance *big.Int
+	Slot    BalanceSlot
+}
+
+func (c *Client) GetVerifiedTokenBalance(token common.Address, holder common.Address, header *BlockHeader, mapping *uint64) (*VerifiedTokenBalance, error) {
+	data, err := erc20ABI.Pack("balanceOf", holder)
+	if err != nil {
+		return nil, fmt.Errorf("error packing balanceOf call: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := c.client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, new(big.Int).SetUint64(header.Number))
+	if err != nil {
+		return nil, fmt.Errorf("error getting token balance: %w", err)
+	}
+	reported, err := unpackUint256(erc20ABI, "balanceOf", result)
+	if err != nil {
+		return nil, err
+	}
+
+	if reported.Sign() == 0 && mapping == nil {
+		return nil, ErrBalanceSlotRequired
+	}
+
+	var candidates []BalanceSlot
+	if mapping != nil {
+		candidates = BalanceSlots(holder, *mapping)
+	} else {
+		for slot := uint64(0); slot < MaxBalanceSlot; slot++ {
+			candidates = append(candidates, BalanceSlots(holder, slot)...)
+		}
+	}
+
+	keys := make([]common.Hash, len(candidates))
+	for i, candidate := range candidates {
+		keys[i] = candidate.Key
+	}
+
+	account, err := c.GetVerifiedAccount(token, header, keys)
+	if err != nil {
+		return nil, err
+	}
+	if account.CodeHash == types.EmptyCodeHash {
+		return nil, fmt.Errorf("%s is not a contract at block %d", token.Hex(), header.Number)
+	}
+
+	if reported.Sign() == 0 {
+		for _, candidate := range candidates {
+			if account.Storage[candidate.Key].Sign() != 0 {
+				return nil, &ProofError{Reason: fmt.Sprintf("node reported a zero balance, but storage slot %s (%s mapping at slot %d) holds %s",
+					candidate.Key.Hex(), candidate.Layout, candidate.Mapping, account.Storage[candidate.Key])}
+			}
+		}
+		return &VerifiedTokenBalance{Balance: reported, Slot: candidates[0]}, nil
+	}
+
+	for _, candidate := range candidates {
+		if account.Storage[candidate.Key].Cmp(reported) == 0 {
+			return &VerifiedTokenBalance{Balance: reported, Slot: candidate}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: balanceOf returned %s", ErrBalanceSlotNotFound, reported)
+}
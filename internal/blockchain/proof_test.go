@@ -0,0 +1,320 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+type proofNodes []string
+
+func (p *proofNodes) Put(key []byte, value []byte) error {
+	*p = append(*p, hexutil.Encode(value))
+	return nil
+}
+
+func (p *proofNodes) Delete(key []byte) error {
+	return nil
+}
+
+func newTestTrie() *trie.Trie {
+	return trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase(), nil))
+}
+
+func prove(t *testing.T, tr *trie.Trie, key []byte) []string {
+	var nodes proofNodes
+	if err := tr.Prove(crypto.Keccak256(key), &nodes); err != nil {
+		t.Fatalf("Ошибка построения доказательства: %v", err)
+	}
+	return nodes
+}
+
+// Тестовое состояние: кошелёк с балансом в ETH и токен, хранящий балансы в mapping в слоте 9
+type proofChain struct {
+	t          *testing.T
+	state      *trie.Trie
+	storage    *trie.Trie
+	accounts   map[common.Address]*types.StateAccount
+	tokenSlot  common.Hash
+	header     map[string]interface{}
+	balanceOf  *big.Int
+	lieBalance *big.Int
+	lieStorage *big.Int
+}
+
+var testTokenBalance = big.NewInt(25_000_000)
+
+func newProofChain(t *testing.T) *proofChain {
+	chain := &proofChain{
+		t:         t,
+		state:     newTestTrie(),
+		storage:   newTestTrie(),
+		accounts:  make(map[common.Address]*types.StateAccount),
+		tokenSlot: BalanceSlots(testHolder, 9)[0].Key,
+		balanceOf: testTokenBalance,
+	}
+
+	value, _ := rlp.EncodeToBytes(testTokenBalance.Bytes())
+	chain.storage.Update(crypto.Keccak256(chain.tokenSlot.Bytes()), value)
+	other, _ := rlp.EncodeToBytes(big.NewInt(7).Bytes())
+	chain.storage.Update(crypto.Keccak256(common.HexToHash("0x02").Bytes()), other)
+
+	balance, _ := new(big.Int).SetString("1500000000000000000", 10)
+	chain.accounts[testHolder] = &types.StateAccount{Nonce: 3, Balance: balance, Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash.Bytes()}
+	chain.accounts[testERC721] = &types.StateAccount{Nonce: 1, Balance: new(big.Int), Root: chain.storage.Hash(), CodeHash: crypto.Keccak256([]byte{0x60, 0x00})}
+
+	for address, account := range chain.accounts {
+		encoded, err := rlp.EncodeToBytes(account)
+		if err != nil {
+			t.Fatalf("Ошибка кодирования аккаунта: %v", err)
+		}
+		chain.state.Update(crypto.Keccak256(address.Bytes()), encoded)
+	}
+
+	beaconRoot := common.HexToHash("0xbeac")
+	blobGas := uint64(0)
+	header, err := rpctest.Block(&types.Header{
+		Number:           big.NewInt(100),
+		Root:             chain.state.Hash(),
+		Difficulty:       new(big.Int),
+		GasLimit:         30_000_000,
+		Time:             1_700_000_000,
+		BaseFee:          big.NewInt(1e9),
+		WithdrawalsHash:  &types.EmptyWithdrawalsHash,
+		BlobGasUsed:      &blobGas,
+		ExcessBlobGas:    &blobGas,
+		ParentBeaconRoot: &beaconRoot,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Ошибка создания блока: %v", err)
+	}
+	chain.header = header
+
+	return chain
+}
+
+func (c *proofChain) serve(server *rpctest.Server) {
+	server.Handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		return c.header, nil
+	})
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		return packResult(c.t, erc20ABI, "balanceOf", c.balanceOf), nil
+	})
+	server.Handle("eth_getProof", func(params []json.RawMessage) (interface{}, error) {
+		var address common.Address
+		var keys []common.Hash
+		json.Unmarshal(params[0], &address)
+		json.Unmarshal(params[1], &keys)
+
+		result := map[string]interface{}{
+			"address":      address,
+			"accountProof": prove(c.t, c.state, address.Bytes()),
+			"balance":      "0x0",
+			"nonce":        "0x0",
+			"codeHash":     common.Hash{},
+			"storageHash":  common.Hash{},
+		}
+
+		account, ok := c.accounts[address]
+		if ok {
+			balance := account.Balance
+			if c.lieBalance != nil {
+				balance = c.lieBalance
+			}
+			result["balance"] = (*hexutil.Big)(balance)
+			result["nonce"] = hexutil.Uint64(account.Nonce)
+			result["codeHash"] = common.BytesToHash(account.CodeHash)
+			result["storageHash"] = account.Root
+		}
+
+		storageTrie := newTestTrie()
+		if address == testERC721 {
+			storageTrie = c.storage
+		}
+
+		storage := []map[string]interface{}{}
+		for _, key := range keys {
+			value := new(big.Int)
+			if raw, _ := storageTrie.Get(crypto.Keccak256(key.Bytes())); raw != nil {
+				var content []byte
+				rlp.DecodeBytes(raw, &content)
+				value.SetBytes(content)
+			}
+			if key == c.tokenSlot && c.lieStorage != nil {
+				value = c.lieStorage
+			}
+			storage = append(storage, map[string]interface{}{
+				"key":   key,
+				"value": (*hexutil.Big)(value),
+				"proof": prove(c.t, storageTrie, key.Bytes()),
+			})
+		}
+		result["storageProof"] = storage
+
+		return result, nil
+	})
+}
+
+func TestVerifiedHeader(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	chain := newProofChain(t)
+	chain.serve(server)
+	client := newTestClient(t, server)
+
+	header, err := client.GetVerifiedHeader(nil)
+	if err != nil {
+		t.Fatalf("Ошибка проверки заголовка: %v", err)
+	}
+	if header.Number != 100 || header.StateRoot != chain.state.Hash() || header.Hash != chain.header["hash"] {
+		t.Errorf("Неверный заголовок: %+v", header)
+	}
+
+	// Подменённый корень состояния не совпадает с хешем блока
+	chain.header["stateRoot"] = common.HexToHash("0x01")
+	var proofErr *ProofError
+	if _, err := client.GetVerifiedHeader(nil); !errors.As(err, &proofErr) {
+		t.Errorf("Подменённый заголовок должен отклоняться, получено %v", err)
+	}
+
+	// Неизвестное поле заголовка не позволяет посчитать хеш, но это не подмена
+	delete(chain.header, "stateRoot")
+	chain.header["futureRoot"] = common.Hash{}
+	if _, err := client.GetVerifiedHeader(nil); err == nil || errors.As(err, &proofErr) {
+		t.Errorf("Неизвестное поле заголовка должно давать обычную ошибку, получено %v", err)
+	}
+}
+
+func TestVerifiedAccount(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	chain := newProofChain(t)
+	chain.serve(server)
+	client := newTestClient(t, server)
+
+	header, err := client.GetVerifiedHeader(nil)
+	if err != nil {
+		t.Fatalf("Ошибка проверки заголовка: %v", err)
+	}
+
+	account, err := client.GetVerifiedAccount(testHolder, header, nil)
+	if err != nil {
+		t.Fatalf("Ошибка проверки аккаунта: %v", err)
+	}
+	if account.Balance.Cmp(chain.accounts[testHolder].Balance) != 0 || account.Nonce != 3 {
+		t.Errorf("Неверный аккаунт: баланс %s, nonce %d", account.Balance, account.Nonce)
+	}
+
+	// Отсутствующий аккаунт доказывается как пустой
+	missing := common.HexToAddress("0x1234")
+	account, err = client.GetVerifiedAccount(missing, header, nil)
+	if err != nil || account.Balance.Sign() != 0 {
+		t.Errorf("Отсутствующий аккаунт должен иметь нулевой баланс: %v, %v", account, err)
+	}
+
+	chain.lieBalance = big.NewInt(1e18)
+	var proofErr *ProofError
+	if _, err := client.GetVerifiedAccount(testHolder, header, nil); !errors.As(err, &proofErr) {
+		t.Errorf("Ложный баланс должен отклоняться, получено %v", err)
+	}
+
+	// Доказательство из другого состояния не сходится с корнем
+	chain.lieBalance = nil
+	other := &BlockHeader{Number: header.Number, Hash: header.Hash, StateRoot: common.HexToHash("0x02")}
+	if _, err := client.GetVerifiedAccount(testHolder, other, nil); !errors.As(err, &proofErr) {
+		t.Errorf("Доказательство для чужого корня должно отклоняться, получено %v", err)
+	}
+}
+
+func TestVerifiedTokenBalance(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	chain := newProofChain(t)
+	chain.serve(server)
+	client := newTestClient(t, server)
+
+	header, err := client.GetVerifiedHeader(nil)
+	if err != nil {
+		t.Fatalf("Ошибка проверки заголовка: %v", err)
+	}
+
+	balance, err := client.GetVerifiedTokenBalance(testERC721, testHolder, header, nil)
+	if err != nil {
+		t.Fatalf("Ошибка проверки баланса токена: %v", err)
+	}
+	if balance.Balance.Cmp(testTokenBalance) != 0 || balance.Slot.Mapping != 9 || balance.Slot.Layout != LayoutSolidity {
+		t.Errorf("Неверный баланс токена: %s в слоте %d (%s)", balance.Balance, balance.Slot.Mapping, balance.Slot.Layout)
+	}
+	if calls := server.Calls("eth_getProof"); calls != 1 {
+		t.Errorf("Все слоты должны запрашиваться одним eth_getProof, запросов: %d", calls)
+	}
+
+	wrongSlot := uint64(3)
+	if _, err := client.GetVerifiedTokenBalance(testERC721, testHolder, header, &wrongSlot); !errors.Is(err, ErrBalanceSlotNotFound) {
+		t.Errorf("Неверный слот не должен подтверждать баланс, получено %v", err)
+	}
+
+	chain.balanceOf = big.NewInt(1e12)
+	if _, err := client.GetVerifiedTokenBalance(testERC721, testHolder, header, nil); !errors.Is(err, ErrBalanceSlotNotFound) {
+		t.Errorf("Ложный balanceOf не должен подтверждаться, получено %v", err)
+	}
+
+	chain.lieStorage = big.NewInt(1e12)
+	var proofErr *ProofError
+	if _, err := client.GetVerifiedTokenBalance(testERC721, testHolder, header, nil); !errors.As(err, &proofErr) {
+		t.Errorf("Ложное значение слота должно отклоняться, получено %v", err)
+	}
+
+	chain.lieStorage = nil
+	if _, err := client.GetVerifiedTokenBalance(testHolder, testHolder, header, nil); err == nil || errors.As(err, &proofErr) {
+		t.Errorf("Адрес без кода не является токеном, получено %v", err)
+	}
+}
+
+func TestVerifiedTokenBalanceZero(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	chain := newProofChain(t)
+	chain.serve(server)
+	client := newTestClient(t, server)
+
+	header, err := client.GetVerifiedHeader(nil)
+	if err != nil {
+		t.Fatalf("Ошибка проверки заголовка: %v", err)
+	}
+
+	// Узел скрывает баланс держателя, возвращая 0: почти все слоты-кандидаты пусты
+	chain.balanceOf = new(big.Int)
+	if _, err := client.GetVerifiedTokenBalance(testERC721, testHolder, header, nil); !errors.Is(err, ErrBalanceSlotRequired) {
+		t.Errorf("Нулевой баланс без слота не должен подтверждаться, получено %v", err)
+	}
+	if calls := server.Calls("eth_getProof"); calls != 0 {
+		t.Errorf("Без слота доказательство не запрашивается, запросов: %d", calls)
+	}
+
+	slot := uint64(9)
+	var proofErr *ProofError
+	if _, err := client.GetVerifiedTokenBalance(testERC721, testHolder, header, &slot); !errors.As(err, &proofErr) {
+		t.Errorf("Нулевой balanceOf при непустом слоте должен отклоняться, получено %v", err)
+	}
+
+	balance, err := client.GetVerifiedTokenBalance(testERC721, testERC1155, header, &slot)
+	if err != nil || balance.Balance.Sign() != 0 {
+		t.Errorf("Настоящий нулевой баланс должен подтверждаться по слоту: %v, %v", balance, err)
+	}
+}
@@ -0,0 +1,45 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type RawTransaction struct {
+	Tx   *types.Transaction
+	From common.Address
+}
+
+func DecodeRawTransaction(raw []byte) (*RawTransaction, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("error decoding transaction: %w", err)
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("error recovering transaction sender: %w", err)
+	}
+
+	return &RawTransaction{Tx: tx, From: from}, nil
+}
+
+func (r *RawTransaction) TypeName() string {
+	switch r.Tx.Type() {
+	case types.LegacyTxType:
+		if !r.Tx.Protected() {
+			return "legacy (no replay protection)"
+		}
+		return "legacy (EIP-155)"
+	case types.AccessListTxType:
+		return "access list (EIP-2930)"
+	case types.DynamicFeeTxType:
+		return "dynamic fee (EIP-1559)"
+	case types.BlobTxType:
+		return "blob (EIP-4844)"
+	default:
+		return fmt.Sprintf("unknown type %d", r.Tx.Type())
+	}
+}
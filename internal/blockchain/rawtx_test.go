@@ -0,0 +1,65 @@
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestDecodeRawTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа: %v", err)
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x01")
+	chainID := big.NewInt(11155111)
+
+	cases := []struct {
+		name   string
+		signer types.Signer
+		tx     types.TxData
+		kind   string
+	}{
+		{"legacy", types.HomesteadSigner{}, &types.LegacyTx{Nonce: 1, To: &to, Gas: 21000, GasPrice: big.NewInt(1e9), Value: big.NewInt(1)},
+			"legacy (no replay protection)"},
+		{"eip155", types.NewEIP155Signer(chainID), &types.LegacyTx{Nonce: 2, To: &to, Gas: 21000, GasPrice: big.NewInt(1e9)},
+			"legacy (EIP-155)"},
+		{"access list", types.LatestSignerForChainID(chainID), &types.AccessListTx{ChainID: chainID, Nonce: 3, To: &to, Gas: 30000, GasPrice: big.NewInt(1e9),
+			AccessList: types.AccessList{{Address: to, StorageKeys: []common.Hash{{}}}}}, "access list (EIP-2930)"},
+		{"dynamic fee", types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{ChainID: chainID, Nonce: 4, To: &to, Gas: 21000,
+			GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(3e9), Data: []byte{0xa9, 0x05, 0x9c, 0xbb}}, "dynamic fee (EIP-1559)"},
+	}
+
+	for _, c := range cases {
+		tx, err := types.SignNewTx(key, c.signer, c.tx)
+		if err != nil {
+			t.Fatalf("%s: ошибка подписи: %v", c.name, err)
+		}
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: ошибка кодирования: %v", c.name, err)
+		}
+
+		decoded, err := DecodeRawTransaction(raw)
+		if err != nil {
+			t.Fatalf("%s: ошибка декодирования: %v", c.name, err)
+		}
+		if decoded.From != sender {
+			t.Errorf("%s: отправитель %s, ожидался %s", c.name, decoded.From.Hex(), sender.Hex())
+		}
+		if decoded.Tx.Hash() != tx.Hash() || decoded.Tx.Nonce() != tx.Nonce() {
+			t.Errorf("%s: транзакция декодирована неверно", c.name)
+		}
+		if decoded.TypeName() != c.kind {
+			t.Errorf("%s: тип %q, ожидался %q", c.name, decoded.TypeName(), c.kind)
+		}
+	}
+
+	if _, err := DecodeRawTransaction([]byte{0x02, 0x01}); err == nil {
+		t.Error("Повреждённая транзакция должна отклоняться")
+	}
+}
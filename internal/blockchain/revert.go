@@ -0,0 +1,188 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
+
+const (
+	RevertKindError   = "error"
+	RevertKindPanic   = "panic"
+	RevertKindCustom  = "custom"
+	RevertKindUnknown = "unknown"
+)
+
+type RevertError struct {
+	Kind   string
+	Reason string
+	Data   []byte
+}
+
+func (e *RevertError) Error() string {
+	return "execution reverted: " + e.Reason
+}
+
+func LoadABI(path string) (*abi.ABI, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening ABI file: %w", err)
+	}
+	defer file.Close()
+
+	parsed, err := abi.JSON(file)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing ABI file: %w", err)
+	}
+
+	return &parsed, nil
+}
+
+func DecodeRevert(data []byte, contractABI *abi.ABI) *RevertError {
+	revert := &RevertError{Kind: RevertKindUnknown, Data: data}
+
+	if len(data) < 4 {
+		revert.Reason = "no reason given"
+		return revert
+	}
+
+	switch {
+	case bytes.Equal(data[:4], errorSelector):
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			revert.Kind = RevertKindError
+			revert.Reason = reason
+			return revert
+		}
+	case bytes.Equal(data[:4], panicSelector):
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			revert.Kind = RevertKindPanic
+			revert.Reason = "panic: " + reason
+			return revert
+		}
+	case contractABI != nil:
+		var selector [4]byte
+		copy(selector[:], data[:4])
+
+		if customErr, err := contractABI.ErrorByID(selector); err == nil {
+			if values, err := customErr.Unpack(data); err == nil {
+				revert.Kind = RevertKindCustom
+				revert.Reason = formatCustomError(customErr.Name, values)
+				return revert
+			}
+		}
+	}
+
+	revert.Reason = fmt.Sprintf("unknown error %s", hexutil.Encode(data[:4]))
+	return revert
+}
+
+func formatCustomError(name string, values interface{}) string {
+	args, ok := values.([]interface{})
+	if !ok {
+		return name + "()"
+	}
+
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		formatted[i] = FormatABIValue(arg)
+	}
+
+	return fmt.Sprintf("%s(%s)", name, strings.Join(formatted, ", "))
+}
+
+func revertFromError(err error, contractABI *abi.ABI) (*RevertError, bool) {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if encoded, ok := dataErr.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(encoded); decodeErr == nil {
+				return DecodeRevert(data, contractABI), true
+			}
+		}
+	}
+
+	if strings.Contains(err.Error(), "revert") {
+		return &RevertError{Kind: RevertKindUnknown, Reason: err.Error()}, true
+	}
+
+	return nil, false
+}
+
+func (c *Client) SimulateTransaction(from common.Address, to common.Address, value *big.Int, data []byte, contractABI *abi.ABI) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: value,
+		Data:  data,
+	}
+
+	_, err := c.client.PendingCallContract(ctx, msg)
+	if err == nil {
+		return nil
+	}
+
+	if revert, ok := revertFromError(err, contractABI); ok {
+		return revert
+	}
+
+	return fmt.Errorf("error simulating transaction: %w", err)
+}
+
+func (c *Client) ReplayTransaction(txHash common.Hash, contractABI *abi.ABI) error {
+	tx, _, err := c.GetTransaction(txHash)
+	if err != nil {
+		return err
+	}
+
+	receipt, err := c.GetTransactionReceipt(txHash)
+	if err != nil {
+		return err
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return fmt.Errorf("error recovering transaction sender: %w", err)
+	}
+
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	parent := new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+	_, err = c.client.CallContract(ctx, msg, parent)
+	if err == nil {
+		return nil
+	}
+
+	if revert, ok := revertFromError(err, contractABI); ok {
+		return revert
+	}
+
+	return fmt.Errorf("error replaying transaction: %w", err)
+}
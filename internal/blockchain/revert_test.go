@@ -0,0 +1,111 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const customErrorsABI = `[
+	{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]},
+	{"type":"error","name":"Unauthorized","inputs":[{"name":"caller","type":"address"}]}
+]`
+
+func encodeError(t *testing.T, signature string, types []string, values ...interface{}) []byte {
+	var args abi.Arguments
+	for _, name := range types {
+		typ, err := abi.NewType(name, "", nil)
+		if err != nil {
+			t.Fatalf("Ошибка создания типа: %v", err)
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+
+	packed, err := args.Pack(values...)
+	if err != nil {
+		t.Fatalf("Ошибка кодирования: %v", err)
+	}
+
+	return append(crypto.Keccak256([]byte(signature))[:4], packed...)
+}
+
+func TestDecodeRevert(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(customErrorsABI))
+	if err != nil {
+		t.Fatalf("Ошибка разбора ABI: %v", err)
+	}
+
+	caller := common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+
+	cases := []struct {
+		data   []byte
+		abi    *abi.ABI
+		kind   string
+		reason string
+	}{
+		{encodeError(t, "Error(string)", []string{"string"}, "ERC20: transfer amount exceeds balance"), nil,
+			RevertKindError, "ERC20: transfer amount exceeds balance"},
+		{encodeError(t, "Panic(uint256)", []string{"uint256"}, big.NewInt(0x11)), nil,
+			RevertKindPanic, "panic: arithmetic underflow or overflow"},
+		{encodeError(t, "InsufficientBalance(uint256,uint256)", []string{"uint256", "uint256"}, big.NewInt(5), big.NewInt(10)), &contractABI,
+			RevertKindCustom, "InsufficientBalance(5, 10)"},
+		{encodeError(t, "Unauthorized(address)", []string{"address"}, caller), &contractABI,
+			RevertKindCustom, "Unauthorized(" + caller.Hex() + ")"},
+		{encodeError(t, "Unauthorized(address)", []string{"address"}, caller), nil,
+			RevertKindUnknown, "unknown error 0x8e4a23d6"},
+		{nil, nil, RevertKindUnknown, "no reason given"},
+	}
+
+	for _, c := range cases {
+		revert := DecodeRevert(c.data, c.abi)
+		if revert.Kind != c.kind || revert.Reason != c.reason {
+			t.Errorf("Ожидалось %s/%q, получено %s/%q", c.kind, c.reason, revert.Kind, revert.Reason)
+		}
+	}
+}
+
+func TestSimulateTransactionDecodesRevert(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	revertData := encodeError(t, "Error(string)", []string{"string"}, "not owner")
+
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		var block string
+		json.Unmarshal(params[1], &block)
+		if block != "pending" {
+			t.Errorf("Симуляция должна выполняться на pending-блоке, получено %s", block)
+		}
+		return nil, &rpctest.Error{Code: 3, Message: "execution reverted: not owner", Data: hexutil.Encode(revertData)}
+	})
+
+	client := newTestClient(t, server)
+
+	err := client.SimulateTransaction(common.Address{}, common.HexToAddress("0x01"), big.NewInt(0), nil, nil)
+
+	var revert *RevertError
+	if !errors.As(err, &revert) {
+		t.Fatalf("Ожидалась ошибка отката, получено %v", err)
+	}
+
+	if revert.Reason != "not owner" {
+		t.Fatalf("Неверная причина отката: %q", revert.Reason)
+	}
+
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		return "0x", nil
+	})
+
+	if err := client.SimulateTransaction(common.Address{}, common.HexToAddress("0x01"), big.NewInt(0), nil, nil); err != nil {
+		t.Fatalf("Успешная симуляция не должна возвращать ошибку: %v", err)
+	}
+}
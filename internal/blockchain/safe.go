@@ -0,0 +1,108 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const safeABIJSON = `[
+	{"type":"function","name":"nonce","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"getThreshold","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"getOwners","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address[]"}]},
+	{"type":"function","name":"VERSION","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"execTransaction","stateMutability":"payable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"outputs":[{"name":"success","type":"bool"}]}
+]`
+
+var safeABI = mustParseABI(safeABIJSON)
+
+type SafeInfo struct {
+	Address   common.Address
+	Version   string
+	Nonce     *big.Int
+	Threshold uint64
+	Owners    []common.Address
+}
+
+func (i *SafeInfo) IsOwner(address common.Address) bool {
+	for _, owner := range i.Owners {
+		if owner == address {
+			return true
+		}
+	}
+	return false
+}
+
+type SafeExec struct {
+	To             common.Address
+	Value          *big.Int
+	Data           []byte
+	Operation      uint8
+	SafeTxGas      *big.Int
+	BaseGas        *big.Int
+	GasPrice       *big.Int
+	GasToken       common.Address
+	RefundReceiver common.Address
+	Signatures     []byte
+}
+
+func (c *Client) safeCall(safe common.Address, method string) (interface{}, error) {
+	data, err := safeABI.Pack(method)
+	if err != nil {
+		return nil, fmt.Errorf("error packing %s call: %w", method, err)
+	}
+
+	result, err := c.CallContract(safe, data)
+	if err != nil {
+		return nil, fmt.Errorf("error calling %s on Safe %s: %w", method, safe.Hex(), err)
+	}
+
+	values, err := safeABI.Unpack(method, result)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a Safe: error decoding %s result: %w", safe.Hex(), method, err)
+	}
+
+	return values[0], nil
+}
+
+func (c *Client) GetSafeInfo(safe common.Address) (*SafeInfo, error) {
+	info := &SafeInfo{Address: safe}
+
+	version, err := c.safeCall(safe, "VERSION")
+	if err != nil {
+		return nil, err
+	}
+	info.Version = version.(string)
+
+	nonce, err := c.safeCall(safe, "nonce")
+	if err != nil {
+		return nil, err
+	}
+	info.Nonce = nonce.(*big.Int)
+
+	threshold, err := c.safeCall(safe, "getThreshold")
+	if err != nil {
+		return nil, err
+	}
+	info.Threshold = threshold.(*big.Int).Uint64()
+
+	owners, err := c.safeCall(safe, "getOwners")
+	if err != nil {
+		return nil, err
+	}
+	info.Owners = owners.([]common.Address)
+
+	return info, nil
+}
+
+func PackSafeExec(exec SafeExec) ([]byte, error) {
+	data, err := safeABI.Pack("execTransaction",
+		exec.To, exec.Value, exec.Data, exec.Operation,
+		exec.SafeTxGas, exec.BaseGas, exec.GasPrice,
+		exec.GasToken, exec.RefundReceiver, exec.Signatures)
+	if err != nil {
+		return nil, fmt.Errorf("error packing execTransaction call: %w", err)
+	}
+	return data, nil
+}
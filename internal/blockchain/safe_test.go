@@ -0,0 +1,59 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestGetSafeInfo(t *testing.T) {
+	safe := common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	owners := []common.Address{testHolder, testERC721}
+
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		args := decodeCallArgs(params[0])
+		if args.To != safe {
+			return "0x", nil
+		}
+
+		method, err := safeABI.MethodById(args.Data[:4])
+		if err != nil {
+			return "0x", nil
+		}
+		switch method.Name {
+		case "VERSION":
+			return packResult(t, safeABI, method.Name, "1.3.0"), nil
+		case "nonce":
+			return packResult(t, safeABI, method.Name, big.NewInt(42)), nil
+		case "getThreshold":
+			return packResult(t, safeABI, method.Name, big.NewInt(2)), nil
+		case "getOwners":
+			return packResult(t, safeABI, method.Name, owners), nil
+		}
+		return "0x", nil
+	})
+
+	client := newTestClient(t, server)
+
+	info, err := client.GetSafeInfo(safe)
+	if err != nil {
+		t.Fatalf("Ошибка чтения Safe: %v", err)
+	}
+	if info.Version != "1.3.0" || info.Nonce.Int64() != 42 || info.Threshold != 2 || len(info.Owners) != 2 {
+		t.Errorf("Неверные данные Safe: %+v", info)
+	}
+	if !info.IsOwner(testHolder) || info.IsOwner(testERC1155) {
+		t.Error("Неверная проверка владельца")
+	}
+
+	if _, err := client.GetSafeInfo(testHolder); err == nil {
+		t.Error("Адрес без контракта Safe должен вызывать ошибку")
+	}
+}
@@ -0,0 +1,118 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const erc1271ABIJSON = `[
+	{"type":"function","name":"isValidSignature","stateMutability":"view","inputs":[{"name":"hash","type":"bytes32"},{"name":"signature","type":"bytes"}],"outputs":[{"name":"magicValue","type":"bytes4"}]},
+	{"type":"function","name":"erc6492","stateMutability":"pure","inputs":[{"name":"factory","type":"address"},{"name":"factoryCalldata","type":"bytes"},{"name":"signature","type":"bytes"}],"outputs":[]}
+]`
+
+var erc1271ABI = mustParseABI(erc1271ABIJSON)
+
+var (
+	ERC1271MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+	ERC6492Suffix     = common.FromHex("0x6492649264926492649264926492649264926492649264926492649264926492")
+)
+
+type ERC6492Signature struct {
+	Factory         common.Address
+	FactoryCalldata []byte
+	Signature       []byte
+}
+
+func IsERC6492Signature(signature []byte) bool {
+	return bytes.HasSuffix(signature, ERC6492Suffix)
+}
+
+func UnwrapERC6492(signature []byte) (*ERC6492Signature, error) {
+	if !IsERC6492Signature(signature) {
+		return nil, fmt.Errorf("signature does not end with the ERC-6492 suffix")
+	}
+
+	values, err := erc1271ABI.Methods["erc6492"].Inputs.Unpack(signature[:len(signature)-len(ERC6492Suffix)])
+	if err != nil {
+		return nil, fmt.Errorf("error decoding ERC-6492 signature: %w", err)
+	}
+
+	return &ERC6492Signature{
+		Factory:         values[0].(common.Address),
+		FactoryCalldata: values[1].([]byte),
+		Signature:       values[2].([]byte),
+	}, nil
+}
+
+func (s *ERC6492Signature) Wrap() ([]byte, error) {
+	encoded, err := erc1271ABI.Methods["erc6492"].Inputs.Pack(s.Factory, s.FactoryCalldata, s.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding ERC-6492 signature: %w", err)
+	}
+	return append(encoded, ERC6492Suffix...), nil
+}
+
+func (c *Client) GetCode(address common.Address) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	code, err := c.client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting code of %s: %w", address.Hex(), err)
+	}
+	return code, nil
+}
+
+func packIsValidSignature(hash common.Hash, signature []byte) ([]byte, error) {
+	data, err := erc1271ABI.Pack("isValidSignature", hash, signature)
+	if err != nil {
+		return nil, fmt.Errorf("error packing isValidSignature call: %w", err)
+	}
+	return data, nil
+}
+
+func isMagicValue(output []byte) bool {
+	values, err := erc1271ABI.Unpack("isValidSignature", output)
+	if err != nil {
+		return false
+	}
+	return values[0].([4]byte) == ERC1271MagicValue
+}
+
+func (c *Client) IsValidSignature(signer common.Address, hash common.Hash, signature []byte) (bool, error) {
+	data, err := packIsValidSignature(hash, signature)
+	if err != nil {
+		return false, err
+	}
+
+	output, err := c.CallContract(signer, data)
+	if err != nil {
+		if _, reverted := revertFromError(err, nil); reverted {
+			return false, nil
+		}
+		return false, fmt.Errorf("error calling isValidSignature on %s: %w", signer.Hex(), err)
+	}
+
+	return isMagicValue(output), nil
+}
+
+func (c *Client) IsValidSignatureCounterfactual(signer common.Address, hash common.Hash, wrapped *ERC6492Signature) (bool, error) {
+	data, err := packIsValidSignature(hash, wrapped.Signature)
+	if err != nil {
+		return false, err
+	}
+
+	results, err := c.Aggregate3([]Call3{
+		{Target: wrapped.Factory, AllowFailure: true, CallData: wrapped.FactoryCalldata},
+		{Target: signer, AllowFailure: true, CallData: data},
+	})
+	if err != nil {
+		return false, fmt.Errorf("error simulating ERC-6492 deployment of %s: %w", signer.Hex(), err)
+	}
+
+	return results[1].Success && isMagicValue(results[1].ReturnData), nil
+}
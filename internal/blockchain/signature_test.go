@@ -0,0 +1,132 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	testAccount = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testFactory = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	testHash    = crypto.Keccak256Hash([]byte("Hello, Ethereum!"))
+)
+
+// Смарт-аккаунт принимает подпись, если она совпадает с ожидаемой;
+// до вызова фабрики по адресу аккаунта нет кода
+func newFakeAccount(t *testing.T, valid []byte) *Client {
+	server := rpctest.NewServer()
+	t.Cleanup(server.Close)
+
+	isValid := func(data []byte) (bool, hexutil.Bytes) {
+		values, _ := erc1271ABI.Methods["isValidSignature"].Inputs.Unpack(data[4:])
+		if values[0].([32]byte) != testHash || !bytes.Equal(values[1].([]byte), valid) {
+			return false, nil
+		}
+		return true, packResult(t, erc1271ABI, "isValidSignature", ERC1271MagicValue)
+	}
+
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		args := decodeCallArgs(params[0])
+
+		if args.To == Multicall3Address {
+			var calls []Call3
+			values, _ := multicall3ABI.Methods["aggregate3"].Inputs.Unpack(args.Data[4:])
+			multicall3ABI.Methods["aggregate3"].Inputs.Copy(&calls, values)
+
+			deployed := false
+			results := make([]Call3Result, len(calls))
+			for i, call := range calls {
+				switch call.Target {
+				case testFactory:
+					deployed = true
+					results[i] = Call3Result{Success: true}
+				case testAccount:
+					if deployed {
+						results[i].Success, results[i].ReturnData = isValid(call.CallData)
+					}
+				}
+			}
+			return packResult(t, multicall3ABI, "aggregate3", results), nil
+		}
+
+		if args.To != testAccount {
+			return "0x", nil
+		}
+		ok, result := isValid(args.Data)
+		if !ok {
+			return nil, &rpctest.Error{Code: 3, Message: "execution reverted"}
+		}
+		return result, nil
+	})
+
+	return newTestClient(t, server)
+}
+
+func TestERC6492RoundTrip(t *testing.T) {
+	wrapped := &ERC6492Signature{Factory: testFactory, FactoryCalldata: []byte{1, 2, 3}, Signature: []byte{4, 5, 6}}
+
+	encoded, err := wrapped.Wrap()
+	if err != nil {
+		t.Fatalf("Ошибка кодирования: %v", err)
+	}
+	if !IsERC6492Signature(encoded) {
+		t.Fatal("Подпись должна оканчиваться суффиксом ERC-6492")
+	}
+
+	decoded, err := UnwrapERC6492(encoded)
+	if err != nil {
+		t.Fatalf("Ошибка декодирования: %v", err)
+	}
+	if decoded.Factory != testFactory || !bytes.Equal(decoded.FactoryCalldata, wrapped.FactoryCalldata) ||
+		!bytes.Equal(decoded.Signature, wrapped.Signature) {
+		t.Errorf("Неверно декодированная подпись: %+v", decoded)
+	}
+
+	if _, err := UnwrapERC6492(wrapped.Signature); err == nil {
+		t.Error("Подпись без суффикса должна отклоняться")
+	}
+}
+
+func TestIsValidSignature(t *testing.T) {
+	signature := []byte("account signature")
+	client := newFakeAccount(t, signature)
+
+	valid, err := client.IsValidSignature(testAccount, testHash, signature)
+	if err != nil || !valid {
+		t.Errorf("Подпись аккаунта должна быть валидной: %v, %v", valid, err)
+	}
+
+	valid, err = client.IsValidSignature(testAccount, testHash, []byte("other"))
+	if err != nil || valid {
+		t.Errorf("Отклонённая контрактом подпись не должна быть валидной: %v, %v", valid, err)
+	}
+
+	valid, err = client.IsValidSignature(testHolder, testHash, signature)
+	if err != nil || valid {
+		t.Errorf("Адрес без контракта не должен подтверждать подпись: %v, %v", valid, err)
+	}
+}
+
+func TestIsValidSignatureCounterfactual(t *testing.T) {
+	signature := []byte("account signature")
+	client := newFakeAccount(t, signature)
+
+	wrapped := &ERC6492Signature{Factory: testFactory, FactoryCalldata: []byte{0xde, 0xad}, Signature: signature}
+	valid, err := client.IsValidSignatureCounterfactual(testAccount, testHash, wrapped)
+	if err != nil || !valid {
+		t.Errorf("Подпись неразвёрнутого аккаунта должна быть валидной: %v, %v", valid, err)
+	}
+
+	wrapped.Signature = []byte("other")
+	valid, err = client.IsValidSignatureCounterfactual(testAccount, testHash, wrapped)
+	if err != nil || valid {
+		t.Errorf("Неверная подпись не должна быть валидной: %v, %v", valid, err)
+	}
+}
@@ -6,15 +6,16 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type KeyPair struct {
-	PrivateKey *ecdsa.PrivateKey
-	PublicKey  *ecdsa.PublicKey
-	Address    common.Address
+	PublicKey *ecdsa.PublicKey
+	Address   common.Address
+	key       *SecureKey
 }
 
 func GenerateKeyPair() (*KeyPair, error) {
@@ -23,23 +24,47 @@ func GenerateKeyPair() (*KeyPair, error) {
 		return nil, fmt.Errorf("error generating private key: %w", err)
 	}
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("error casting public key to ECDSA")
+	return KeyPairFromPrivateKey(privateKey), nil
+}
+
+func NewKeyPair(privateKeyBytes []byte) (*KeyPair, error) {
+	privateKey, err := crypto.ToECDSA(privateKeyBytes)
+	Wipe(privateKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error restoring private key: %w", err)
+	}
+
+	return KeyPairFromPrivateKey(privateKey), nil
+}
+
+func KeyPairFromPrivateKey(privateKey *ecdsa.PrivateKey) *KeyPair {
+	publicKey := privateKey.PublicKey
+	keyPair := &KeyPair{
+		PublicKey: &publicKey,
+		Address:   crypto.PubkeyToAddress(publicKey),
+		key:       NewSecureKey(crypto.FromECDSA(privateKey)),
 	}
 
-	address := crypto.PubkeyToAddress(*publicKeyECDSA)
+	WipeECDSA(privateKey)
+	return keyPair
+}
+
+func (kp *KeyPair) WithPrivateKey(fn func(*ecdsa.PrivateKey) error) error {
+	return kp.key.Use(fn)
+}
 
-	return &KeyPair{
-		PrivateKey: privateKey,
-		PublicKey:  publicKeyECDSA,
-		Address:    address,
-	}, nil
+func (kp *KeyPair) PrivateKeyBytes() ([]byte, error) {
+	return kp.key.Bytes()
 }
 
 func (kp *KeyPair) GetPrivateKeyHex() string {
-	return hex.EncodeToString(crypto.FromECDSA(kp.PrivateKey))
+	raw, err := kp.key.Bytes()
+	if err != nil {
+		return ""
+	}
+	defer Wipe(raw)
+
+	return hex.EncodeToString(raw)
 }
 
 func (kp *KeyPair) GetPublicKeyHex() string {
@@ -51,17 +76,50 @@ func (kp *KeyPair) GetAddressHex() string {
 }
 
 func (kp *KeyPair) SignMessage(message []byte) ([]byte, error) {
-	hash := crypto.Keccak256Hash(message)
-	signature, err := crypto.Sign(hash.Bytes(), kp.PrivateKey)
+	return kp.SignHash(crypto.Keccak256(message))
+}
+
+func (kp *KeyPair) SignHash(hash []byte) ([]byte, error) {
+	var signature []byte
+	err := kp.key.Use(func(privateKey *ecdsa.PrivateKey) error {
+		var err error
+		signature, err = crypto.Sign(hash, privateKey)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error signing message: %w", err)
 	}
 	return signature, nil
 }
 
+func (kp *KeyPair) MemoryLocked() bool {
+	return kp.key.Locked()
+}
+
+func (kp *KeyPair) Destroyed() bool {
+	return kp.key.Destroyed()
+}
+
+func (kp *KeyPair) Destroy() {
+	kp.key.Destroy()
+}
+
 func VerifySignature(message []byte, signature []byte, address common.Address) bool {
-	hash := crypto.Keccak256Hash(message)
-	sigPublicKey, err := crypto.Ecrecover(hash.Bytes(), signature)
+	return VerifyHashSignature(crypto.Keccak256Hash(message), signature, address)
+}
+
+func VerifyHashSignature(hash common.Hash, signature []byte, address common.Address) bool {
+	if len(signature) != 65 {
+		return false
+	}
+
+	recoverable := make([]byte, 65)
+	copy(recoverable, signature)
+	if recoverable[64] >= 27 {
+		recoverable[64] -= 27
+	}
+
+	sigPublicKey, err := crypto.Ecrecover(hash.Bytes(), recoverable)
 	if err != nil {
 		return false
 	}
@@ -82,16 +140,36 @@ func GenerateRandomBytes(length int) ([]byte, error) {
 }
 
 func IsValidAddress(address string) bool {
-	return common.IsHexAddress(address)
+	if !common.IsHexAddress(address) {
+		return false
+	}
+
+	digits := address
+	if has0xPrefix(address) {
+		digits = address[2:]
+	}
+
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return true
+	}
+
+	return common.HexToAddress(address).Hex()[2:] == digits
 }
 
 func HexToAddress(hex string) (common.Address, error) {
 	if !common.IsHexAddress(hex) {
 		return common.Address{}, fmt.Errorf("invalid Ethereum address: %s", hex)
 	}
+	if !IsValidAddress(hex) {
+		return common.Address{}, fmt.Errorf("invalid address checksum: %s", hex)
+	}
 	return common.HexToAddress(hex), nil
 }
 
+func has0xPrefix(s string) bool {
+	return len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X')
+}
+
 func WeiToEther(wei *big.Int) *big.Float {
 	f := new(big.Float)
 	f.SetPrec(236)
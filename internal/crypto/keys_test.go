@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func TestGenerateKeyPair(t *testing.T) {
@@ -17,8 +18,8 @@ func TestGenerateKeyPair(t *testing.T) {
 		t.Fatal("KeyPair не должен быть nil")
 	}
 
-	if keyPair.PrivateKey == nil {
-		t.Fatal("Приватный ключ не должен быть nil")
+	if privateKey, err := keyPair.PrivateKeyBytes(); err != nil || len(privateKey) != 32 {
+		t.Fatal("Приватный ключ должен быть доступен")
 	}
 
 	if keyPair.PublicKey == nil {
@@ -90,6 +91,33 @@ func TestSignAndVerifyMessage(t *testing.T) {
 	}
 }
 
+func TestVerifyHashSignatureLegacyV(t *testing.T) {
+	keyPair, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Ошибка генерации пары ключей: %v", err)
+	}
+
+	hash := crypto.Keccak256Hash([]byte("Hello, Ethereum!"))
+	signature, err := keyPair.SignHash(hash.Bytes())
+	if err != nil {
+		t.Fatalf("Ошибка подписи: %v", err)
+	}
+
+	// Подпись с v = 27/28, как её возвращают кошельки и контракты
+	legacy := append([]byte{}, signature...)
+	legacy[64] += 27
+	if !VerifyHashSignature(hash, legacy, keyPair.Address) {
+		t.Error("Подпись с v = 27/28 должна быть валидной")
+	}
+	if legacy[64] < 27 {
+		t.Error("Проверка не должна изменять подпись")
+	}
+
+	if VerifyHashSignature(hash, signature[:64], keyPair.Address) {
+		t.Error("Подпись неверной длины не должна быть валидной")
+	}
+}
+
 func TestGenerateRandomBytes(t *testing.T) {
 	length := 32
 	bytes, err := GenerateRandomBytes(length)
@@ -118,8 +146,9 @@ func TestGenerateRandomBytes(t *testing.T) {
 func TestIsValidAddress(t *testing.T) {
 	// Валидные адреса
 	validAddresses := []string{
-		"0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6",
+		"0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6",
 		"0x742d35cc6634c0532925a3b8d4c9db96c4b4d8b6",
+		"0x742D35CC6634C0532925A3B8D4C9DB96C4B4D8B6",
 		"0x0000000000000000000000000000000000000000",
 	}
 
@@ -134,6 +163,7 @@ func TestIsValidAddress(t *testing.T) {
 		"0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b",   // Слишком короткий
 		"0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6G", // Неправильный символ
 		"", // Пустая строка
+		"0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6", // Неверная контрольная сумма EIP-55
 	}
 
 	for _, addr := range invalidAddresses {
@@ -144,7 +174,7 @@ func TestIsValidAddress(t *testing.T) {
 }
 
 func TestHexToAddress(t *testing.T) {
-	validAddress := "0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6"
+	validAddress := "0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6"
 	address, err := HexToAddress(validAddress)
 	if err != nil {
 		t.Fatalf("Ошибка конвертации адреса: %v", err)
@@ -161,6 +191,12 @@ func TestHexToAddress(t *testing.T) {
 	if err == nil {
 		t.Fatal("Должна быть ошибка для невалидного адреса")
 	}
+
+	// Тест с опечаткой в регистре
+	_, err = HexToAddress("0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6")
+	if err == nil {
+		t.Fatal("Должна быть ошибка для неверной контрольной суммы")
+	}
 }
 
 func TestWeiToEther(t *testing.T) {
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package crypto
+
+type lockedMemory struct {
+	buf    []byte
+	locked bool
+}
+
+func allocLocked(size int) *lockedMemory {
+	return &lockedMemory{buf: make([]byte, size)}
+}
+
+func (m *lockedMemory) free() {}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package crypto
+
+import "golang.org/x/sys/unix"
+
+type lockedMemory struct {
+	buf    []byte
+	mapped bool
+	locked bool
+}
+
+func allocLocked(size int) *lockedMemory {
+	buf, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return &lockedMemory{buf: make([]byte, size)}
+	}
+
+	mem := &lockedMemory{buf: buf[:size], mapped: true}
+	mem.locked = unix.Mlock(buf) == nil
+	return mem
+}
+
+func (m *lockedMemory) free() {
+	if !m.mapped {
+		return
+	}
+
+	if m.locked {
+		unix.Munlock(m.buf)
+	}
+	unix.Munmap(m.buf)
+	m.mapped, m.locked = false, false
+}
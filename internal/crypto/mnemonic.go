@@ -0,0 +1,107 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const DefaultDerivationPath = "m/44'/60'/0'/0/0"
+
+func NormalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+}
+
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	entropy, err := bip39.EntropyFromMnemonic(NormalizeMnemonic(mnemonic))
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+	return entropy, nil
+}
+
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", fmt.Errorf("invalid mnemonic entropy: %w", err)
+	}
+	return mnemonic, nil
+}
+
+func KeyPairFromMnemonic(mnemonic, passphrase, path string) (*KeyPair, error) {
+	mnemonic = NormalizeMnemonic(mnemonic)
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, fmt.Errorf("invalid mnemonic")
+	}
+
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid derivation path: %w", err)
+	}
+
+	seed := bip39.NewSeed(mnemonic, passphrase)
+	defer Wipe(seed)
+
+	privateKey, err := DeriveKey(seed, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return KeyPairFromPrivateKey(privateKey), nil
+}
+
+func DeriveKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key, chainCode := sum[:32], sum[32:]
+	n := crypto.S256().Params().N
+
+	for _, index := range path {
+		var data []byte
+		if index >= 0x80000000 {
+			data = append([]byte{0}, key...)
+		} else {
+			parent, err := crypto.ToECDSA(key)
+			if err != nil {
+				return nil, fmt.Errorf("error deriving key: %w", err)
+			}
+			data = crypto.CompressPubkey(&parent.PublicKey)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(n) >= 0 {
+			return nil, fmt.Errorf("invalid child key at index %d", index)
+		}
+
+		child := tweak.Add(tweak, new(big.Int).SetBytes(key))
+		child.Mod(child, n)
+		if child.Sign() == 0 {
+			return nil, fmt.Errorf("invalid child key at index %d", index)
+		}
+
+		key = child.FillBytes(make([]byte, 32))
+		chainCode = sum[32:]
+	}
+
+	privateKey, err := crypto.ToECDSA(key)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key: %w", err)
+	}
+
+	return privateKey, nil
+}
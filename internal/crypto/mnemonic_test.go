@@ -0,0 +1,66 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestDeriveKeyBIP32Vector(t *testing.T) {
+	// Тестовый вектор 1 из BIP-32
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	path, err := accounts.ParseDerivationPath("m/0'/1/2'/2/1000000000")
+	if err != nil {
+		t.Fatalf("Ошибка разбора пути: %v", err)
+	}
+
+	key, err := DeriveKey(seed, path)
+	if err != nil {
+		t.Fatalf("Ошибка вывода ключа: %v", err)
+	}
+
+	expected := "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"
+	if got := hex.EncodeToString(crypto.FromECDSA(key)); got != expected {
+		t.Errorf("Неверный ключ: %s, ожидалось %s", got, expected)
+	}
+}
+
+func TestKeyPairFromMnemonic(t *testing.T) {
+	keyPair, err := KeyPairFromMnemonic("  Abandon abandon abandon abandon abandon abandon\nabandon abandon abandon abandon abandon about ", "", DefaultDerivationPath)
+	if err != nil {
+		t.Fatalf("Ошибка восстановления из мнемоники: %v", err)
+	}
+
+	if keyPair.Address.Hex() != "0x9858EfFD232B4033E47d90003D41EC34EcaEda94" {
+		t.Errorf("Неверный адрес: %s", keyPair.Address.Hex())
+	}
+
+	if _, err := KeyPairFromMnemonic("abandon abandon abandon", "", DefaultDerivationPath); err == nil {
+		t.Error("Должна быть ошибка для неверной мнемоники")
+	}
+	if _, err := KeyPairFromMnemonic(testMnemonic, "", "m/44'/x"); err == nil {
+		t.Error("Должна быть ошибка для неверного пути")
+	}
+}
+
+func TestMnemonicEntropyRoundTrip(t *testing.T) {
+	entropy, err := MnemonicToEntropy(testMnemonic)
+	if err != nil {
+		t.Fatalf("Ошибка получения энтропии: %v", err)
+	}
+	if len(entropy) != 16 {
+		t.Fatalf("Ожидалось 16 байт энтропии, получено %d", len(entropy))
+	}
+
+	mnemonic, err := EntropyToMnemonic(entropy)
+	if err != nil {
+		t.Fatalf("Ошибка восстановления мнемоники: %v", err)
+	}
+	if mnemonic != testMnemonic {
+		t.Errorf("Мнемоника не совпадает: %s", mnemonic)
+	}
+}
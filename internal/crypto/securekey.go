@@ -0,0 +1,103 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrKeyDestroyed = errors.New("key material has been wiped")
+
+type SecureKey struct {
+	mu     sync.Mutex
+	buf    []byte
+	mem    *lockedMemory
+	wiped  bool
+	locked bool
+}
+
+func NewSecureKey(raw []byte) *SecureKey {
+	mem := allocLocked(len(raw))
+	copy(mem.buf, raw)
+	Wipe(raw)
+
+	key := &SecureKey{buf: mem.buf, mem: mem, locked: mem.locked}
+	runtime.SetFinalizer(key, (*SecureKey).Destroy)
+	return key
+}
+
+func (k *SecureKey) Use(fn func(*ecdsa.PrivateKey) error) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.wiped {
+		return ErrKeyDestroyed
+	}
+
+	privateKey, err := crypto.ToECDSA(k.buf)
+	if err != nil {
+		return fmt.Errorf("error restoring private key: %w", err)
+	}
+	defer WipeECDSA(privateKey)
+
+	return fn(privateKey)
+}
+
+func (k *SecureKey) Bytes() ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.wiped {
+		return nil, ErrKeyDestroyed
+	}
+
+	out := make([]byte, len(k.buf))
+	copy(out, k.buf)
+	return out, nil
+}
+
+func (k *SecureKey) Locked() bool {
+	return k.locked
+}
+
+func (k *SecureKey) Destroyed() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.wiped
+}
+
+func (k *SecureKey) Destroy() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.wiped {
+		return
+	}
+
+	Wipe(k.buf)
+	k.mem.free()
+	k.buf = nil
+	k.wiped = true
+}
+
+func Wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+func WipeECDSA(key *ecdsa.PrivateKey) {
+	if key == nil || key.D == nil {
+		return
+	}
+
+	words := key.D.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	key.D.SetInt64(0)
+}
@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSecureKeyWipesInput(t *testing.T) {
+	raw := bytes.Repeat([]byte{0x11}, 32)
+	key := NewSecureKey(raw)
+	defer key.Destroy()
+
+	if !bytes.Equal(raw, make([]byte, 32)) {
+		t.Fatal("Исходный буфер должен быть обнулён")
+	}
+
+	stored, err := key.Bytes()
+	if err != nil {
+		t.Fatalf("Ошибка чтения ключа: %v", err)
+	}
+	if !bytes.Equal(stored, bytes.Repeat([]byte{0x11}, 32)) {
+		t.Fatal("Ключ должен сохраниться в защищённом буфере")
+	}
+}
+
+func TestSecureKeyUse(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа: %v", err)
+	}
+	expected := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	key := NewSecureKey(crypto.FromECDSA(privateKey))
+	defer key.Destroy()
+
+	var used *ecdsa.PrivateKey
+	err = key.Use(func(k *ecdsa.PrivateKey) error {
+		used = k
+		if crypto.PubkeyToAddress(k.PublicKey) != expected {
+			t.Fatal("Восстановленный ключ не совпадает с исходным")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Ошибка использования ключа: %v", err)
+	}
+
+	if used.D.Sign() != 0 {
+		t.Fatal("Временный ключ должен обнуляться после использования")
+	}
+}
+
+func TestSecureKeyDestroy(t *testing.T) {
+	keyPair, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Ошибка генерации пары ключей: %v", err)
+	}
+	address := keyPair.Address
+
+	keyPair.Destroy()
+	keyPair.Destroy()
+
+	if !keyPair.Destroyed() {
+		t.Fatal("Ключ должен быть помечен как уничтоженный")
+	}
+	if keyPair.Address != address {
+		t.Fatal("Адрес должен сохраниться после уничтожения ключа")
+	}
+
+	if _, err := keyPair.PrivateKeyBytes(); !errors.Is(err, ErrKeyDestroyed) {
+		t.Fatalf("Ожидалась ошибка ErrKeyDestroyed, получено: %v", err)
+	}
+	if _, err := keyPair.SignMessage([]byte("test")); !errors.Is(err, ErrKeyDestroyed) {
+		t.Fatalf("Подпись уничтоженным ключом должна завершаться ошибкой, получено: %v", err)
+	}
+}
+
+func TestWipeECDSA(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа: %v", err)
+	}
+
+	WipeECDSA(privateKey)
+	WipeECDSA(nil)
+
+	if privateKey.D.Sign() != 0 {
+		t.Fatal("Скаляр ключа должен быть обнулён")
+	}
+}
@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func IsChecksumAddress(address string) bool {
+	if !common.IsHexAddress(address) || !strings.HasPrefix(address, "0x") {
+		return false
+	}
+	return common.HexToAddress(address).Hex() == address
+}
+
+func ParseUnits(amount string, decimals int) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+	if amount == "" {
+		return nil, fmt.Errorf("empty amount")
+	}
+
+	whole, frac, hasFrac := strings.Cut(amount, ".")
+	if whole == "" && frac == "" {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+	if hasFrac && frac == "" {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+
+	for _, part := range []string{whole, frac} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return nil, fmt.Errorf("invalid amount: %s", amount)
+			}
+		}
+	}
+
+	if len(frac) > decimals {
+		return nil, fmt.Errorf("amount %s has more than %d decimal places", amount, decimals)
+	}
+
+	digits := whole + frac + strings.Repeat("0", decimals-len(frac))
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+
+	return value, nil
+}
+
+func FormatUnits(value *big.Int, decimals int) string {
+	sign := ""
+	abs := new(big.Int).Set(value)
+	if abs.Sign() < 0 {
+		sign = "-"
+		abs.Neg(abs)
+	}
+
+	digits := abs.String()
+	if decimals == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	whole := digits[:len(digits)-decimals]
+	frac := strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if frac == "" {
+		return sign + whole
+	}
+
+	return sign + whole + "." + frac
+}
@@ -0,0 +1,78 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestIsChecksumAddress(t *testing.T) {
+	if !IsChecksumAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6") {
+		t.Fatal("Адрес с корректной контрольной суммой должен приниматься")
+	}
+
+	invalid := []string{
+		"0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6", // Неверный регистр
+		"0x742d35cc6634c0532925a3b8d4c9db96c4b4d8b6", // Без контрольной суммы
+		"742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6",   // Без префикса
+		"0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B",  // Слишком короткий
+	}
+
+	for _, address := range invalid {
+		if IsChecksumAddress(address) {
+			t.Errorf("Адрес не должен приниматься: %s", address)
+		}
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	cases := []struct {
+		amount   string
+		decimals int
+		expected string
+	}{
+		{"1", 18, "1000000000000000000"},
+		{"0.5", 18, "500000000000000000"},
+		{".25", 6, "250000"},
+		{"123.000001", 6, "123000001"},
+		{"0.000000000000000001", 18, "1"},
+		{"42", 0, "42"},
+	}
+
+	for _, c := range cases {
+		value, err := ParseUnits(c.amount, c.decimals)
+		if err != nil {
+			t.Fatalf("Ошибка разбора суммы %s: %v", c.amount, err)
+		}
+		if value.String() != c.expected {
+			t.Fatalf("Сумма %s: ожидалось %s, получено %s", c.amount, c.expected, value)
+		}
+	}
+
+	invalid := []string{"", ".", "1.", "-1", "1e18", "0x10", "1.2.3", "0.0000001"}
+	for _, amount := range invalid {
+		if _, err := ParseUnits(amount, 6); err == nil {
+			t.Errorf("Должна быть ошибка для суммы %q", amount)
+		}
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	cases := []struct {
+		value    *big.Int
+		decimals int
+		expected string
+	}{
+		{big.NewInt(1e18), 18, "1"},
+		{big.NewInt(5e17), 18, "0.5"},
+		{big.NewInt(1), 18, "0.000000000000000001"},
+		{big.NewInt(123000001), 6, "123.000001"},
+		{big.NewInt(-2500000), 6, "-2.5"},
+		{big.NewInt(0), 6, "0"},
+	}
+
+	for _, c := range cases {
+		if formatted := FormatUnits(c.value, c.decimals); formatted != c.expected {
+			t.Fatalf("Ожидалось %s, получено %s", c.expected, formatted)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package eip681
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"crypto-wallet/internal/crypto"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	Scheme           = "ethereum"
+	FunctionTransfer = "transfer"
+)
+
+type Request struct {
+	Target    string
+	ChainID   *big.Int
+	Function  string
+	Recipient string
+	Value     *big.Int
+	GasLimit  uint64
+	GasPrice  *big.Int
+}
+
+func NewPayment(to common.Address, chainID *big.Int, value *big.Int) *Request {
+	return &Request{Target: to.Hex(), ChainID: chainID, Value: value}
+}
+
+func NewTokenPayment(token common.Address, chainID *big.Int, to common.Address, amount *big.Int) *Request {
+	return &Request{
+		Target:    token.Hex(),
+		ChainID:   chainID,
+		Function:  FunctionTransfer,
+		Recipient: to.Hex(),
+		Value:     amount,
+	}
+}
+
+func (r *Request) IsToken() bool {
+	return r.Function == FunctionTransfer
+}
+
+func Parse(uri string) (*Request, error) {
+	rest, ok := cutPrefixFold(uri, Scheme+":")
+	if !ok {
+		return nil, fmt.Errorf("not an %s: URI", Scheme)
+	}
+	rest = strings.TrimPrefix(rest, "pay-")
+
+	path, query, _ := strings.Cut(rest, "?")
+	path, function, _ := strings.Cut(path, "/")
+	target, chain, hasChain := strings.Cut(path, "@")
+
+	if target == "" {
+		return nil, fmt.Errorf("payment request has no target address")
+	}
+	if common.IsHexAddress(target) && !crypto.IsValidAddress(target) {
+		return nil, fmt.Errorf("invalid address checksum: %s", target)
+	}
+
+	req := &Request{Target: target, Function: function}
+
+	if hasChain {
+		chainID, ok := new(big.Int).SetString(chain, 10)
+		if !ok || chainID.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid chain ID: %s", chain)
+		}
+		req.ChainID = chainID
+	}
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	switch function {
+	case "":
+		if v := params.Get("value"); v != "" {
+			if req.Value, err = parseNumber(v); err != nil {
+				return nil, fmt.Errorf("invalid value: %w", err)
+			}
+		}
+	case FunctionTransfer:
+		req.Recipient = params.Get("address")
+		if req.Recipient == "" {
+			return nil, fmt.Errorf("token transfer request has no recipient address")
+		}
+		if common.IsHexAddress(req.Recipient) && !crypto.IsValidAddress(req.Recipient) {
+			return nil, fmt.Errorf("invalid address checksum: %s", req.Recipient)
+		}
+		if v := params.Get("uint256"); v != "" {
+			if req.Value, err = parseNumber(v); err != nil {
+				return nil, fmt.Errorf("invalid uint256: %w", err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported function: %s", function)
+	}
+
+	if v := params.Get("gasLimit"); v != "" {
+		gasLimit, err := parseNumber(v)
+		if err != nil || !gasLimit.IsUint64() {
+			return nil, fmt.Errorf("invalid gasLimit: %s", v)
+		}
+		req.GasLimit = gasLimit.Uint64()
+	}
+	if v := params.Get("gasPrice"); v != "" {
+		if req.GasPrice, err = parseNumber(v); err != nil {
+			return nil, fmt.Errorf("invalid gasPrice: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (r *Request) String() string {
+	var b strings.Builder
+	b.WriteString(Scheme + ":" + r.Target)
+
+	if r.ChainID != nil {
+		b.WriteString("@" + r.ChainID.String())
+	}
+	if r.Function != "" {
+		b.WriteString("/" + r.Function)
+	}
+
+	var params []string
+	if r.IsToken() {
+		params = append(params, "address="+r.Recipient)
+		if r.Value != nil {
+			params = append(params, "uint256="+r.Value.String())
+		}
+	} else if r.Value != nil {
+		params = append(params, "value="+r.Value.String())
+	}
+	if r.GasLimit != 0 {
+		params = append(params, "gasLimit="+strconv.FormatUint(r.GasLimit, 10))
+	}
+	if r.GasPrice != nil {
+		params = append(params, "gasPrice="+r.GasPrice.String())
+	}
+
+	if len(params) > 0 {
+		b.WriteString("?" + strings.Join(params, "&"))
+	}
+
+	return b.String()
+}
+
+func parseNumber(s string) (*big.Int, error) {
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(s), "e")
+
+	exp := 0
+	if hasExponent {
+		var err error
+		if exp, err = strconv.Atoi(exponent); err != nil || exp < 0 || exp > 77 {
+			return nil, fmt.Errorf("invalid exponent in %s", s)
+		}
+	}
+
+	whole, fraction, _ := strings.Cut(mantissa, ".")
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > exp {
+		return nil, fmt.Errorf("%s is not an integer", s)
+	}
+
+	if fraction != "" {
+		whole += "." + fraction
+	}
+
+	value, err := crypto.ParseUnits(whole, exp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %s: %w", s, err)
+	}
+	if value.BitLen() > 256 {
+		return nil, fmt.Errorf("%s does not fit in uint256", s)
+	}
+
+	return value, nil
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
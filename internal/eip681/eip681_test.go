@@ -0,0 +1,101 @@
+package eip681
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	recipient = common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+	token     = common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+)
+
+func TestParsePayment(t *testing.T) {
+	req, err := Parse("ethereum:pay-0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6@11155111?value=2.014e18&gasLimit=21000")
+	if err != nil {
+		t.Fatalf("Ошибка разбора URI: %v", err)
+	}
+
+	if req.Target != recipient.Hex() {
+		t.Errorf("Неверный получатель: %s", req.Target)
+	}
+	if req.ChainID == nil || req.ChainID.Int64() != 11155111 {
+		t.Errorf("Неверный chain ID: %v", req.ChainID)
+	}
+	if req.IsToken() {
+		t.Error("Запрос не должен быть переводом токена")
+	}
+	if req.Value.String() != "2014000000000000000" {
+		t.Errorf("Неверная сумма: %s", req.Value)
+	}
+	if req.GasLimit != 21000 {
+		t.Errorf("Неверный лимит газа: %d", req.GasLimit)
+	}
+}
+
+func TestParseTokenTransfer(t *testing.T) {
+	uri := "ethereum:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed/transfer?address=0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6&uint256=1e6"
+
+	req, err := Parse(uri)
+	if err != nil {
+		t.Fatalf("Ошибка разбора URI: %v", err)
+	}
+
+	if !req.IsToken() || req.Target != token.Hex() || req.Recipient != recipient.Hex() {
+		t.Errorf("Неверный запрос перевода токена: %+v", req)
+	}
+	if req.ChainID != nil {
+		t.Errorf("Chain ID не указан, получено %v", req.ChainID)
+	}
+	if req.Value.Int64() != 1000000 {
+		t.Errorf("Неверная сумма: %s", req.Value)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	uris := []string{
+		"bitcoin:1BoatSLRHtKNngkdXEeobR76b53LETtpyT",
+		"ethereum:",
+		"ethereum:0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6?value=1",
+		"ethereum:0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6@abc",
+		"ethereum:0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6?value=1.5",
+		"ethereum:0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6?value=-1",
+		"ethereum:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed/transfer?uint256=1",
+		"ethereum:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed/approve?address=0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6",
+	}
+
+	for _, uri := range uris {
+		if _, err := Parse(uri); err == nil {
+			t.Errorf("Должна быть ошибка для %s", uri)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	chainID := big.NewInt(1)
+
+	requests := []*Request{
+		NewPayment(recipient, chainID, big.NewInt(1e18)),
+		NewPayment(recipient, nil, nil),
+		NewTokenPayment(token, chainID, recipient, big.NewInt(2500000)),
+	}
+
+	for _, req := range requests {
+		uri := req.String()
+
+		parsed, err := Parse(uri)
+		if err != nil {
+			t.Fatalf("Ошибка разбора %s: %v", uri, err)
+		}
+		if parsed.String() != uri {
+			t.Errorf("URI изменился после разбора: %s != %s", parsed.String(), uri)
+		}
+	}
+
+	expected := "ethereum:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed@1/transfer?address=0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6&uint256=2500000"
+	if got := requests[2].String(); got != expected {
+		t.Errorf("Неверный URI: %s", got)
+	}
+}
@@ -0,0 +1,209 @@
+package gasoracle
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/crypto"
+)
+
+type Tier string
+
+const (
+	Slow     Tier = "slow"
+	Standard Tier = "standard"
+	Fast     Tier = "fast"
+)
+
+var Tiers = []Tier{Slow, Standard, Fast}
+
+var tierPercentiles = []float64{10, 50, 90}
+
+const (
+	DefaultBlocks = 20
+	DefaultTTL    = 15 * time.Second
+)
+
+var ErrNoFeeMarket = errors.New("chain has no EIP-1559 base fee")
+
+type CeilingError struct {
+	Price   *big.Int
+	Ceiling *big.Int
+}
+
+func (e *CeilingError) Error() string {
+	return fmt.Sprintf("gas price %s gwei is above the %s gwei ceiling",
+		crypto.FormatUnits(e.Price, 9), crypto.FormatUnits(e.Ceiling, 9))
+}
+
+func ParseTier(input string) (Tier, error) {
+	for _, tier := range Tiers {
+		if strings.EqualFold(input, string(tier)) {
+			return tier, nil
+		}
+	}
+	return "", fmt.Errorf("unknown fee tier %q: use slow, standard or fast", input)
+}
+
+type Fee struct {
+	Tip      *big.Int
+	MaxFee   *big.Int
+	GasPrice *big.Int
+}
+
+type Estimate struct {
+	BaseFee     *big.Int
+	NextBaseFee *big.Int
+	Blocks      int
+	Tiers       map[Tier]Fee
+}
+
+type Oracle struct {
+	Client  *blockchain.Client
+	Blocks  int
+	TTL     time.Duration
+	Ceiling *big.Int
+
+	mu      sync.Mutex
+	cached  *Estimate
+	fetched time.Time
+	now     func() time.Time
+}
+
+func New(client *blockchain.Client) *Oracle {
+	return &Oracle{Client: client, Blocks: DefaultBlocks, TTL: DefaultTTL}
+}
+
+func (o *Oracle) clock() time.Time {
+	if o.now != nil {
+		return o.now()
+	}
+	return time.Now()
+}
+
+func (o *Oracle) Estimate() (*Estimate, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.cached != nil && o.clock().Sub(o.fetched) < o.TTL {
+		return o.cached, nil
+	}
+
+	blocks := o.Blocks
+	if blocks <= 0 {
+		blocks = DefaultBlocks
+	}
+
+	history, err := o.Client.FeeHistory(uint64(blocks), tierPercentiles)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate, err := estimateFromHistory(history.BaseFee, history.Reward)
+	if err != nil {
+		return nil, err
+	}
+
+	o.cached = estimate
+	o.fetched = o.clock()
+	return estimate, nil
+}
+
+func estimateFromHistory(baseFees []*big.Int, rewards [][]*big.Int) (*Estimate, error) {
+	if len(baseFees) < 2 || len(rewards) == 0 {
+		return nil, fmt.Errorf("fee history is empty")
+	}
+
+	next := baseFees[len(baseFees)-1]
+	if next == nil || next.Sign() == 0 {
+		return nil, ErrNoFeeMarket
+	}
+
+	estimate := &Estimate{
+		BaseFee:     baseFees[len(baseFees)-2],
+		NextBaseFee: next,
+		Blocks:      len(rewards),
+		Tiers:       make(map[Tier]Fee),
+	}
+
+	headroom := new(big.Int).Div(new(big.Int).Mul(next, big.NewInt(9)), big.NewInt(8))
+	maxBase := new(big.Int).Mul(next, big.NewInt(2))
+
+	for i, tier := range Tiers {
+		samples := make([]*big.Int, 0, len(rewards))
+		for _, block := range rewards {
+			if i < len(block) && block[i] != nil {
+				samples = append(samples, block[i])
+			}
+		}
+
+		tip := median(samples)
+		estimate.Tiers[tier] = Fee{
+			Tip:      tip,
+			MaxFee:   new(big.Int).Add(maxBase, tip),
+			GasPrice: new(big.Int).Add(headroom, tip),
+		}
+	}
+
+	return estimate, nil
+}
+
+func median(values []*big.Int) *big.Int {
+	if len(values) == 0 {
+		return new(big.Int)
+	}
+
+	sorted := append([]*big.Int(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return new(big.Int).Set(sorted[middle])
+	}
+	sum := new(big.Int).Add(sorted[middle-1], sorted[middle])
+	return sum.Div(sum, big.NewInt(2))
+}
+
+func (o *Oracle) Fee(tier Tier) (Fee, error) {
+	estimate, err := o.Estimate()
+	if err != nil {
+		return Fee{}, err
+	}
+
+	fee, ok := estimate.Tiers[tier]
+	if !ok {
+		return Fee{}, fmt.Errorf("unknown fee tier %q", tier)
+	}
+	if err := o.CheckCeiling(fee.GasPrice); err != nil {
+		return Fee{}, err
+	}
+	return fee, nil
+}
+
+func (o *Oracle) GasPrice(tier Tier) (*big.Int, error) {
+	fee, err := o.Fee(tier)
+	if errors.Is(err, ErrNoFeeMarket) {
+		price, err := o.Client.GetGasPrice()
+		if err != nil {
+			return nil, err
+		}
+		return price, o.CheckCeiling(price)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fee.GasPrice, nil
+}
+
+func (o *Oracle) CheckCeiling(price *big.Int) error {
+	if o.Ceiling != nil && price != nil && price.Cmp(o.Ceiling) > 0 {
+		return &CeilingError{Price: price, Ceiling: o.Ceiling}
+	}
+	return nil
+}
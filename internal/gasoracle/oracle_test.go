@@ -0,0 +1,167 @@
+package gasoracle
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const gwei = 1_000_000_000
+
+func hexGwei(value float64) string {
+	return hexutil.EncodeBig(big.NewInt(int64(value * gwei)))
+}
+
+// Пять блоков с чаевыми 1/2/3 gwei, один блок со скачком до 50/80/100 gwei
+func feeHistory() map[string]interface{} {
+	rewards := make([][]string, 5)
+	for i := range rewards {
+		rewards[i] = []string{hexGwei(1), hexGwei(2), hexGwei(3)}
+	}
+	rewards[2] = []string{hexGwei(50), hexGwei(80), hexGwei(100)}
+
+	return map[string]interface{}{
+		"oldestBlock":   "0x100",
+		"baseFeePerGas": []string{hexGwei(10), hexGwei(10), hexGwei(10), hexGwei(10), hexGwei(10), hexGwei(16)},
+		"gasUsedRatio":  []float64{0.5, 0.5, 0.5, 0.5, 1},
+		"reward":        rewards,
+	}
+}
+
+func newTestOracle(t *testing.T, server *rpctest.Server) *Oracle {
+	client, err := blockchain.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Ошибка создания клиента: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return New(client)
+}
+
+func TestEstimateTiers(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_feeHistory", func(params []json.RawMessage) (interface{}, error) {
+		return feeHistory(), nil
+	})
+
+	oracle := newTestOracle(t, server)
+	estimate, err := oracle.Estimate()
+	if err != nil {
+		t.Fatalf("Ошибка оценки комиссии: %v", err)
+	}
+
+	if estimate.NextBaseFee.Int64() != 16*gwei || estimate.BaseFee.Int64() != 10*gwei || estimate.Blocks != 5 {
+		t.Errorf("Неверная базовая комиссия: %s -> %s, %d блоков", estimate.BaseFee, estimate.NextBaseFee, estimate.Blocks)
+	}
+
+	// Медиана по блокам отбрасывает скачок в одном блоке
+	expectedTips := map[Tier]int64{Slow: 1 * gwei, Standard: 2 * gwei, Fast: 3 * gwei}
+	for tier, tip := range expectedTips {
+		fee := estimate.Tiers[tier]
+		if fee.Tip.Int64() != tip {
+			t.Errorf("%s: чаевые %s, ожидалось %d", tier, fee.Tip, tip)
+		}
+		if fee.MaxFee.Int64() != 32*gwei+tip {
+			t.Errorf("%s: максимальная комиссия %s", tier, fee.MaxFee)
+		}
+		if fee.GasPrice.Int64() != 18*gwei+tip {
+			t.Errorf("%s: цена газа %s", tier, fee.GasPrice)
+		}
+	}
+}
+
+func TestEstimateCache(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_feeHistory", func(params []json.RawMessage) (interface{}, error) {
+		return feeHistory(), nil
+	})
+
+	now := time.Unix(1700000000, 0)
+	oracle := newTestOracle(t, server)
+	oracle.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := oracle.Estimate(); err != nil {
+			t.Fatalf("Ошибка оценки комиссии: %v", err)
+		}
+	}
+	if calls := server.Calls("eth_feeHistory"); calls != 1 {
+		t.Errorf("Повторные запросы в пределах TTL должны браться из кеша, запросов: %d", calls)
+	}
+
+	now = now.Add(DefaultTTL)
+	if _, err := oracle.Estimate(); err != nil {
+		t.Fatalf("Ошибка оценки комиссии: %v", err)
+	}
+	if calls := server.Calls("eth_feeHistory"); calls != 2 {
+		t.Errorf("После истечения TTL история должна запрашиваться заново, запросов: %d", calls)
+	}
+}
+
+func TestCeiling(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_feeHistory", func(params []json.RawMessage) (interface{}, error) {
+		return feeHistory(), nil
+	})
+
+	oracle := newTestOracle(t, server)
+	oracle.Ceiling = big.NewInt(20 * gwei)
+
+	if _, err := oracle.GasPrice(Standard); err != nil {
+		t.Errorf("Стандартная цена ниже потолка: %v", err)
+	}
+
+	oracle.Ceiling = big.NewInt(19 * gwei)
+	var ceiling *CeilingError
+	if _, err := oracle.GasPrice(Fast); !errors.As(err, &ceiling) {
+		t.Errorf("Цена выше потолка должна отклоняться, получено %v", err)
+	}
+}
+
+func TestNoFeeMarketFallback(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_feeHistory", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{
+			"oldestBlock":   "0x100",
+			"baseFeePerGas": []string{"0x0", "0x0"},
+			"gasUsedRatio":  []float64{0.5},
+			"reward":        [][]string{{"0x0", "0x0", "0x0"}},
+		}, nil
+	})
+	server.Handle("eth_gasPrice", func(params []json.RawMessage) (interface{}, error) {
+		return hexGwei(5), nil
+	})
+
+	oracle := newTestOracle(t, server)
+	if _, err := oracle.Estimate(); !errors.Is(err, ErrNoFeeMarket) {
+		t.Fatalf("Ожидалась ошибка ErrNoFeeMarket, получено %v", err)
+	}
+
+	price, err := oracle.GasPrice(Standard)
+	if err != nil || price.Int64() != 5*gwei {
+		t.Errorf("Без EIP-1559 должна использоваться цена узла: %v, %v", price, err)
+	}
+}
+
+func TestParseTier(t *testing.T) {
+	if tier, err := ParseTier("FAST"); err != nil || tier != Fast {
+		t.Errorf("ParseTier(FAST) = %s, %v", tier, err)
+	}
+	if _, err := ParseTier("turbo"); err == nil {
+		t.Error("Неизвестный уровень должен отклоняться")
+	}
+}
@@ -0,0 +1,172 @@
+package indexer
+
+import (
+	"fmt"
+	"math/big"
+
+	"crypto-wallet/internal/blockchain"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type Indexer struct {
+	Client    *blockchain.Client
+	Store     *Store
+	Addresses []common.Address
+
+	signer types.Signer
+}
+
+func (ix *Indexer) IndexRange(from uint64, to uint64, progress func(block uint64, found int)) (int, error) {
+	total := 0
+
+	for number := from; number <= to; number++ {
+		records, err := ix.scanBlock(number)
+		if err != nil {
+			return total, fmt.Errorf("error indexing block %d: %w", number, err)
+		}
+
+		if err := ix.Store.Put(records, ix.Addresses, number); err != nil {
+			return total, err
+		}
+
+		total += len(records)
+		if progress != nil {
+			progress(number, len(records))
+		}
+	}
+
+	return total, nil
+}
+
+func (ix *Indexer) scanBlock(number uint64) ([]Record, error) {
+	if ix.signer == nil {
+		chainID, err := ix.Client.GetChainID()
+		if err != nil {
+			return nil, err
+		}
+		ix.signer = types.LatestSignerForChainID(chainID)
+	}
+
+	block, err := ix.Client.GetBlock(new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, err
+	}
+
+	watched := make(map[common.Address]bool, len(ix.Addresses))
+	for _, address := range ix.Addresses {
+		watched[address] = true
+	}
+
+	var records []Record
+
+	for i, tx := range block.Transactions() {
+		from, err := types.Sender(ix.signer, tx)
+		if err != nil {
+			return nil, fmt.Errorf("error recovering sender of %s: %w", tx.Hash().Hex(), err)
+		}
+
+		var to common.Address
+		if tx.To() != nil {
+			to = *tx.To()
+		}
+
+		if !watched[from] && !watched[to] {
+			continue
+		}
+
+		receipt, err := ix.Client.GetTransactionReceipt(tx.Hash())
+		if err != nil {
+			return nil, err
+		}
+
+		status := StatusSuccess
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			status = StatusFailed
+		}
+
+		if tx.To() == nil {
+			to = receipt.ContractAddress
+		}
+
+		for _, record := range directedRecords(from, to, watched) {
+			record.BlockNumber = number
+			record.Timestamp = block.Time()
+			record.TxHash = tx.Hash()
+			record.TxIndex = uint(i)
+			record.Asset = NativeAsset
+			record.Value = tx.Value().String()
+			record.Status = status
+			records = append(records, record)
+		}
+	}
+
+	logs, err := ix.transferLogs(block.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, log := range logs {
+		transfer, ok := blockchain.DecodeTokenTransfer(log)
+		if !ok || log.Removed {
+			continue
+		}
+
+		logIndex := log.Index
+		for _, record := range directedRecords(transfer.From, transfer.To, watched) {
+			record.BlockNumber = number
+			record.Timestamp = block.Time()
+			record.TxHash = log.TxHash
+			record.TxIndex = log.TxIndex
+			record.LogIndex = &logIndex
+			record.Asset = transfer.Token.Hex()
+			record.Value = transfer.Value.String()
+			record.Status = StatusSuccess
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+func (ix *Indexer) transferLogs(blockHash common.Hash) ([]types.Log, error) {
+	topics := blockchain.AddressTopics(ix.Addresses)
+	seen := make(map[uint]bool)
+	var logs []types.Log
+
+	for _, position := range []int{1, 2} {
+		filter := [][]common.Hash{{blockchain.TransferEventTopic}, nil, nil}
+		filter[position] = topics
+
+		found, err := ix.Client.FilterLogs(ethereum.FilterQuery{BlockHash: &blockHash, Topics: filter})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, log := range found {
+			if !seen[log.Index] {
+				seen[log.Index] = true
+				logs = append(logs, log)
+			}
+		}
+	}
+
+	return logs, nil
+}
+
+func directedRecords(from common.Address, to common.Address, watched map[common.Address]bool) []Record {
+	if from == to && watched[from] {
+		return []Record{{Address: from, Direction: DirectionSelf, Counterparty: to}}
+	}
+
+	var records []Record
+	if watched[from] {
+		records = append(records, Record{Address: from, Direction: DirectionOut, Counterparty: to})
+	}
+	if watched[to] {
+		records = append(records, Record{Address: to, Direction: DirectionIn, Counterparty: from})
+	}
+	return records
+}
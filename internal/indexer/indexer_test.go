@@ -0,0 +1,144 @@
+package indexer
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var chainID = big.NewInt(11155111)
+
+func TestIndexRange(t *testing.T) {
+	ourKey, _ := crypto.GenerateKey()
+	strangerKey, _ := crypto.GenerateKey()
+	me := crypto.PubkeyToAddress(ourKey.PublicKey)
+	signer := types.LatestSignerForChainID(chainID)
+
+	sign := func(key *ecdsa.PrivateKey, nonce uint64, to common.Address, value int64) *types.Transaction {
+		tx := types.NewTransaction(nonce, to, big.NewInt(value), 21000, big.NewInt(1e9), nil)
+		signed, err := types.SignTx(tx, signer, key)
+		if err != nil {
+			t.Fatalf("Ошибка подписи: %v", err)
+		}
+		return signed
+	}
+
+	outgoing := sign(ourKey, 0, other, 100)
+	failed := sign(ourKey, 1, token, 0)
+	incoming := sign(strangerKey, 0, me, 200)
+	unrelated := sign(strangerKey, 1, other, 300)
+
+	header := &types.Header{Number: big.NewInt(7), Difficulty: big.NewInt(0), Time: 1700000084}
+	block, err := rpctest.Block(header, []*types.Transaction{outgoing, failed, incoming, unrelated})
+	if err != nil {
+		t.Fatalf("Ошибка создания блока: %v", err)
+	}
+
+	tokenLog := types.Log{
+		Address: token,
+		Topics: []common.Hash{
+			blockchain.TransferEventTopic,
+			common.BytesToHash(me.Bytes()),
+			common.BytesToHash(other.Bytes()),
+		},
+		Data:    common.LeftPadBytes(big.NewInt(55).Bytes(), 32),
+		TxHash:  failed.Hash(),
+		TxIndex: 1,
+		Index:   4,
+	}
+
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_chainId", func(params []json.RawMessage) (interface{}, error) {
+		return hexutil.Big(*chainID), nil
+	})
+	server.Handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		return block, nil
+	})
+	server.Handle("eth_getTransactionReceipt", func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		json.Unmarshal(params[0], &hash)
+		if hash == unrelated.Hash() {
+			t.Errorf("Квитанция посторонней транзакции не должна запрашиваться")
+		}
+
+		status := types.ReceiptStatusSuccessful
+		if hash == failed.Hash() {
+			status = types.ReceiptStatusFailed
+		}
+		return &types.Receipt{Status: status, TxHash: hash, BlockNumber: header.Number, Logs: []*types.Log{}}, nil
+	})
+	server.Handle("eth_getLogs", func(params []json.RawMessage) (interface{}, error) {
+		var query struct {
+			Topics []interface{} `json:"topics"`
+		}
+		json.Unmarshal(params[0], &query)
+
+		// Перевод токена находится только по позиции отправителя
+		if len(query.Topics) > 1 && query.Topics[1] != nil {
+			return []types.Log{tokenLog}, nil
+		}
+		return []types.Log{}, nil
+	})
+
+	client, err := blockchain.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Ошибка создания клиента: %v", err)
+	}
+	defer client.Close()
+
+	store := openTestStore(t)
+	ix := &Indexer{Client: client, Store: store, Addresses: []common.Address{me}}
+
+	found, err := ix.IndexRange(7, 7, nil)
+	if err != nil {
+		t.Fatalf("Ошибка индексации: %v", err)
+	}
+	if found != 4 {
+		t.Fatalf("Ожидалось 4 записи, получено %d", found)
+	}
+
+	records, err := store.Query(Query{Address: me})
+	if err != nil {
+		t.Fatalf("Ошибка запроса: %v", err)
+	}
+
+	type summary struct {
+		direction, asset, value, status string
+	}
+	expected := []summary{
+		{DirectionIn, NativeAsset, "200", StatusSuccess},
+		{DirectionOut, token.Hex(), "55", StatusSuccess},
+		{DirectionOut, NativeAsset, "0", StatusFailed},
+		{DirectionOut, NativeAsset, "100", StatusSuccess},
+	}
+
+	for i, record := range records {
+		got := summary{record.Direction, record.Asset, record.Value, record.Status}
+		if got != expected[i] {
+			t.Fatalf("Запись %d: ожидалось %+v, получено %+v", i, expected[i], got)
+		}
+		if record.Timestamp != 1700000084 || record.BlockNumber != 7 {
+			t.Fatalf("Неверные данные блока: %+v", record)
+		}
+	}
+
+	if records[0].Counterparty != crypto.PubkeyToAddress(strangerKey.PublicKey) {
+		t.Fatal("Для входящей транзакции контрагентом должен быть отправитель")
+	}
+
+	lastBlock, ok, err := store.LastBlock(me)
+	if err != nil || !ok || lastBlock != 7 {
+		t.Fatalf("Прогресс индексации не сохранен: %d %v %v", lastBlock, ok, err)
+	}
+}
@@ -0,0 +1,208 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	DirectionIn   = "in"
+	DirectionOut  = "out"
+	DirectionSelf = "self"
+
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+
+	NativeAsset = "ETH"
+)
+
+var (
+	recordsBucket = []byte("records")
+	metaBucket    = []byte("meta")
+)
+
+type Record struct {
+	Address      common.Address `json:"address"`
+	BlockNumber  uint64         `json:"block_number"`
+	Timestamp    uint64         `json:"timestamp"`
+	TxHash       common.Hash    `json:"tx_hash"`
+	TxIndex      uint           `json:"tx_index"`
+	LogIndex     *uint          `json:"log_index,omitempty"`
+	Direction    string         `json:"direction"`
+	Counterparty common.Address `json:"counterparty"`
+	Asset        string         `json:"asset"`
+	Value        string         `json:"value"`
+	Status       string         `json:"status"`
+}
+
+type Query struct {
+	Address   common.Address
+	Direction string
+	Asset     string
+	Status    string
+	Offset    int
+	Limit     int
+}
+
+type Store struct {
+	db *bolt.DB
+}
+
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening history database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{recordsBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error initializing history database: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) Put(records []Record, addresses []common.Address, lastBlock uint64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(recordsBucket)
+
+		for _, record := range records {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return fmt.Errorf("error serializing record: %w", err)
+			}
+			if err := bucket.Put(recordKey(record), data); err != nil {
+				return fmt.Errorf("error storing record: %w", err)
+			}
+		}
+
+		meta := tx.Bucket(metaBucket)
+		value := make([]byte, 8)
+		binary.BigEndian.PutUint64(value, lastBlock)
+
+		for _, address := range addresses {
+			if stored := meta.Get(address.Bytes()); len(stored) == 8 && binary.BigEndian.Uint64(stored) >= lastBlock {
+				continue
+			}
+			if err := meta.Put(address.Bytes(), value); err != nil {
+				return fmt.Errorf("error storing index progress: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *Store) LastBlock(address common.Address) (uint64, bool, error) {
+	var lastBlock uint64
+	var found bool
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(metaBucket).Get(address.Bytes())
+		if len(value) == 8 {
+			lastBlock = binary.BigEndian.Uint64(value)
+			found = true
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("error reading index progress: %w", err)
+	}
+
+	return lastBlock, found, nil
+}
+
+func (s *Store) Query(q Query) ([]Record, error) {
+	var records []Record
+	skipped := 0
+	prefix := q.Address.Bytes()
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(recordsBucket).Cursor()
+
+		seek := append(append([]byte{}, prefix...), bytes.Repeat([]byte{0xff}, 17)...)
+		key, value := cursor.Seek(seek)
+		if key == nil {
+			key, value = cursor.Last()
+		} else if !bytes.Equal(key, seek) {
+			key, value = cursor.Prev()
+		}
+
+		for ; key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Prev() {
+			var record Record
+			if err := json.Unmarshal(value, &record); err != nil {
+				return fmt.Errorf("error parsing record: %w", err)
+			}
+
+			if !q.matches(record) {
+				continue
+			}
+
+			if skipped < q.Offset {
+				skipped++
+				continue
+			}
+
+			records = append(records, record)
+			if q.Limit > 0 && len(records) >= q.Limit {
+				break
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (q Query) matches(record Record) bool {
+	if q.Direction != "" && record.Direction != q.Direction {
+		return false
+	}
+	if q.Asset != "" && !strings.EqualFold(record.Asset, q.Asset) {
+		return false
+	}
+	if q.Status != "" && record.Status != q.Status {
+		return false
+	}
+	return true
+}
+
+func recordKey(record Record) []byte {
+	key := make([]byte, 0, 20+8+4+1+4)
+	key = append(key, record.Address.Bytes()...)
+	key = binary.BigEndian.AppendUint64(key, record.BlockNumber)
+	key = binary.BigEndian.AppendUint32(key, uint32(record.TxIndex))
+
+	if record.LogIndex == nil {
+		key = append(key, 0)
+		key = binary.BigEndian.AppendUint32(key, 0)
+	} else {
+		key = append(key, 1)
+		key = binary.BigEndian.AppendUint32(key, uint32(*record.LogIndex))
+	}
+
+	return key
+}
@@ -0,0 +1,125 @@
+package indexer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ours  = common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+	other = common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	token = common.HexToAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359")
+)
+
+func openTestStore(t *testing.T) *Store {
+	store, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Ошибка открытия базы: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestStoreQueryFiltersAndPages(t *testing.T) {
+	store := openTestStore(t)
+
+	logIndex := uint(3)
+	records := []Record{
+		{Address: ours, BlockNumber: 10, Direction: DirectionIn, Asset: NativeAsset, Value: "1", Status: StatusSuccess},
+		{Address: ours, BlockNumber: 11, Direction: DirectionOut, Asset: NativeAsset, Value: "2", Status: StatusFailed},
+		{Address: ours, BlockNumber: 12, Direction: DirectionIn, Asset: token.Hex(), Value: "3", Status: StatusSuccess, LogIndex: &logIndex},
+		{Address: ours, BlockNumber: 13, Direction: DirectionOut, Asset: NativeAsset, Value: "4", Status: StatusSuccess},
+		{Address: other, BlockNumber: 14, Direction: DirectionIn, Asset: NativeAsset, Value: "5", Status: StatusSuccess},
+	}
+
+	if err := store.Put(records, []common.Address{ours, other}, 14); err != nil {
+		t.Fatalf("Ошибка записи: %v", err)
+	}
+
+	all, err := store.Query(Query{Address: ours})
+	if err != nil {
+		t.Fatalf("Ошибка запроса: %v", err)
+	}
+
+	// Записи возвращаются от новых к старым и только для указанного адреса
+	if len(all) != 4 || all[0].Value != "4" || all[3].Value != "1" {
+		t.Fatalf("Неверный порядок или состав записей: %+v", all)
+	}
+
+	page, err := store.Query(Query{Address: ours, Offset: 1, Limit: 2})
+	if err != nil {
+		t.Fatalf("Ошибка запроса: %v", err)
+	}
+	if len(page) != 2 || page[0].Value != "3" || page[1].Value != "2" {
+		t.Fatalf("Неверная страница: %+v", page)
+	}
+
+	filters := []struct {
+		query    Query
+		expected []string
+	}{
+		{Query{Address: ours, Direction: DirectionOut}, []string{"4", "2"}},
+		{Query{Address: ours, Asset: "eth"}, []string{"4", "2", "1"}},
+		{Query{Address: ours, Asset: token.Hex()}, []string{"3"}},
+		{Query{Address: ours, Status: StatusFailed}, []string{"2"}},
+		{Query{Address: ours, Direction: DirectionIn, Status: StatusSuccess, Asset: NativeAsset}, []string{"1"}},
+	}
+
+	for _, f := range filters {
+		found, err := store.Query(f.query)
+		if err != nil {
+			t.Fatalf("Ошибка запроса: %v", err)
+		}
+
+		values := make([]string, len(found))
+		for i, record := range found {
+			values[i] = record.Value
+		}
+
+		if len(values) != len(f.expected) {
+			t.Fatalf("Фильтр %+v: ожидалось %v, получено %v", f.query, f.expected, values)
+		}
+		for i := range values {
+			if values[i] != f.expected[i] {
+				t.Fatalf("Фильтр %+v: ожидалось %v, получено %v", f.query, f.expected, values)
+			}
+		}
+	}
+
+	lastBlock, found, err := store.LastBlock(ours)
+	if err != nil || !found || lastBlock != 14 {
+		t.Fatalf("Неверный последний блок: %d, %v, %v", lastBlock, found, err)
+	}
+
+	if _, found, _ := store.LastBlock(token); found {
+		t.Fatal("Для непроиндексированного адреса прогресса быть не должно")
+	}
+}
+
+func TestStorePutKeepsLatestBlock(t *testing.T) {
+	store := openTestStore(t)
+
+	if err := store.Put(nil, []common.Address{ours}, 100); err != nil {
+		t.Fatalf("Ошибка записи: %v", err)
+	}
+
+	// Дозагрузка старых блоков не должна отодвигать прогресс назад
+	backfill := []Record{{Address: ours, BlockNumber: 5, Direction: DirectionIn, Asset: NativeAsset, Value: "1", Status: StatusSuccess}}
+	if err := store.Put(backfill, []common.Address{ours, other}, 10); err != nil {
+		t.Fatalf("Ошибка записи: %v", err)
+	}
+
+	if lastBlock, _, _ := store.LastBlock(ours); lastBlock != 100 {
+		t.Fatalf("Последний блок должен остаться 100, получено %d", lastBlock)
+	}
+	if lastBlock, found, _ := store.LastBlock(other); !found || lastBlock != 10 {
+		t.Fatalf("Для нового адреса должен сохраниться блок 10, получено %d", lastBlock)
+	}
+
+	records, err := store.Query(Query{Address: ours})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("Записи дозагрузки должны сохраниться: %v, %v", records, err)
+	}
+}
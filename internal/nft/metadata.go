@@ -0,0 +1,159 @@
+package nft
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	DefaultIPFSGateway = "https://ipfs.io/ipfs/"
+	maxMetadataSize    = 1 << 20
+)
+
+var ErrRemoteURI = errors.New("metadata is not embedded in the URI")
+
+type Attribute struct {
+	TraitType string      `json:"trait_type"`
+	Value     interface{} `json:"value"`
+}
+
+type Metadata struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Image       string      `json:"image"`
+	ExternalURL string      `json:"external_url"`
+	Attributes  []Attribute `json:"attributes"`
+}
+
+type Fetcher struct {
+	Client      *http.Client
+	IPFSGateway string
+}
+
+func Decode(uri string) (*Metadata, error) {
+	if !strings.HasPrefix(strings.ToLower(uri), "data:") {
+		return nil, ErrRemoteURI
+	}
+
+	data, err := decodeDataURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMetadata(data)
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, uri string) (*Metadata, error) {
+	if strings.HasPrefix(strings.ToLower(uri), "data:") {
+		return Decode(uri)
+	}
+
+	location, err := f.ResolveURL(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating metadata request: %w", err)
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching metadata: %s returned %s", location, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading metadata: %w", err)
+	}
+	if len(data) > maxMetadataSize {
+		return nil, fmt.Errorf("metadata at %s is larger than %d bytes", location, maxMetadataSize)
+	}
+
+	return parseMetadata(data)
+}
+
+func (f *Fetcher) ResolveURL(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid metadata URI: %w", err)
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+		return uri, nil
+	case "ipfs":
+		gateway := f.IPFSGateway
+		if gateway == "" {
+			gateway = DefaultIPFSGateway
+		}
+		path := parsed.Opaque
+		if path == "" {
+			path = parsed.Host + parsed.EscapedPath()
+		}
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "/"), "ipfs/")
+		if path == "" {
+			return "", fmt.Errorf("invalid metadata URI %q: missing IPFS content ID", uri)
+		}
+		if parsed.RawQuery != "" {
+			path += "?" + parsed.RawQuery
+		}
+		return strings.TrimSuffix(gateway, "/") + "/" + path, nil
+	default:
+		return "", fmt.Errorf("unsupported metadata URI scheme %q", parsed.Scheme)
+	}
+}
+
+func decodeDataURI(uri string) ([]byte, error) {
+	header, payload, found := strings.Cut(uri[len("data:"):], ",")
+	if !found {
+		return nil, fmt.Errorf("invalid data URI: missing ','")
+	}
+
+	params := strings.Split(header, ";")
+	mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+	if mediaType != "" && mediaType != "application/json" && mediaType != "text/plain" {
+		return nil, fmt.Errorf("unsupported metadata media type %q", mediaType)
+	}
+
+	if strings.EqualFold(params[len(params)-1], "base64") {
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			if data, err = base64.RawStdEncoding.DecodeString(payload); err != nil {
+				return nil, fmt.Errorf("invalid base64 in data URI: %w", err)
+			}
+		}
+		return data, nil
+	}
+
+	if unescaped, err := url.PathUnescape(payload); err == nil {
+		return []byte(unescaped), nil
+	}
+	return []byte(payload), nil
+}
+
+func parseMetadata(data []byte) (*Metadata, error) {
+	var metadata Metadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("error parsing metadata: %w", err)
+	}
+	return &metadata, nil
+}
@@ -0,0 +1,110 @@
+package nft
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const sampleMetadata = `{"name":"Token #1","description":"Тестовый токен","image":"ipfs://QmImage","attributes":[{"trait_type":"Color","value":"red"},{"trait_type":"Level","value":3}]}`
+
+func TestDecodeBase64DataURI(t *testing.T) {
+	uri := "data:application/json;base64," + base64.StdEncoding.EncodeToString([]byte(sampleMetadata))
+
+	metadata, err := Decode(uri)
+	if err != nil {
+		t.Fatalf("Ошибка разбора метаданных: %v", err)
+	}
+
+	if metadata.Name != "Token #1" || metadata.Image != "ipfs://QmImage" {
+		t.Errorf("Неверные метаданные: %+v", metadata)
+	}
+	if len(metadata.Attributes) != 2 || metadata.Attributes[0].TraitType != "Color" || metadata.Attributes[1].Value != float64(3) {
+		t.Errorf("Неверные атрибуты: %+v", metadata.Attributes)
+	}
+}
+
+func TestDecodePlainDataURI(t *testing.T) {
+	metadata, err := Decode(`data:application/json;utf8,{"name":"Plain%20token"}`)
+	if err != nil {
+		t.Fatalf("Ошибка разбора метаданных: %v", err)
+	}
+	if metadata.Name != "Plain token" {
+		t.Errorf("Неверное имя: %q", metadata.Name)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	if _, err := Decode("https://example.com/1.json"); !errors.Is(err, ErrRemoteURI) {
+		t.Errorf("Ожидалась ошибка ErrRemoteURI, получено: %v", err)
+	}
+
+	uris := []string{
+		"data:application/json;base64,@@@",
+		"data:image/png;base64,AAAA",
+		"data:application/json",
+		"data:,not json",
+	}
+	for _, uri := range uris {
+		if _, err := Decode(uri); err == nil {
+			t.Errorf("Ожидалась ошибка для %q", uri)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ipfs/QmMeta/1.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(sampleMetadata))
+	}))
+	defer server.Close()
+
+	fetcher := &Fetcher{Client: server.Client(), IPFSGateway: server.URL + "/ipfs/"}
+
+	metadata, err := fetcher.Fetch(context.Background(), "ipfs://QmMeta/1.json")
+	if err != nil {
+		t.Fatalf("Ошибка загрузки метаданных: %v", err)
+	}
+	if metadata.Name != "Token #1" {
+		t.Errorf("Неверное имя: %q", metadata.Name)
+	}
+
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/missing.json"); err == nil {
+		t.Error("Ожидалась ошибка для отсутствующих метаданных")
+	}
+	if _, err := fetcher.Fetch(context.Background(), "ftp://example.com/1.json"); err == nil {
+		t.Error("Ожидалась ошибка для неподдерживаемой схемы")
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	fetcher := &Fetcher{}
+
+	tests := map[string]string{
+		"ipfs://QmHash/1.json":       DefaultIPFSGateway + "QmHash/1.json",
+		"ipfs://ipfs/QmHash":         DefaultIPFSGateway + "QmHash",
+		"ipfs:///ipfs/QmHash/2.json": DefaultIPFSGateway + "QmHash/2.json",
+		"ipfs:QmAbc":                 DefaultIPFSGateway + "QmAbc",
+		"ipfs:QmAbc/3.json":          DefaultIPFSGateway + "QmAbc/3.json",
+		"https://example.com/1.json": "https://example.com/1.json",
+	}
+	for uri, expected := range tests {
+		location, err := fetcher.ResolveURL(uri)
+		if err != nil || location != expected {
+			t.Errorf("ResolveURL(%q) = %q, %v; ожидалось %q", uri, location, err, expected)
+		}
+	}
+
+	// URI без идентификатора содержимого задаёт контракт, он не должен ронять разбор
+	for _, uri := range []string{"ipfs:", "ipfs://", "ipfs://ipfs/"} {
+		if location, err := fetcher.ResolveURL(uri); err == nil {
+			t.Errorf("ResolveURL(%q) = %q, ожидалась ошибка", uri, location)
+		}
+	}
+}
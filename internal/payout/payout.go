@@ -0,0 +1,234 @@
+package payout
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/crypto"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const NativeAsset = "ETH"
+
+type Row struct {
+	Line      int
+	Recipient common.Address
+	Amount    string
+	Token     *common.Address
+	Value     *big.Int
+}
+
+func (r Row) Asset() string {
+	if r.Token == nil {
+		return NativeAsset
+	}
+	return r.Token.Hex()
+}
+
+type RowError struct {
+	Line    int
+	Message string
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+type ValidationError struct {
+	Errors []RowError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, rowErr := range e.Errors {
+		messages[i] = rowErr.Error()
+	}
+	return fmt.Sprintf("%d invalid rows:\n  %s", len(e.Errors), strings.Join(messages, "\n  "))
+}
+
+type Summary struct {
+	Totals   map[string]*big.Int
+	Decimals map[string]int
+	GasLimit uint64
+	GasPrice *big.Int
+	Fee      *big.Int
+}
+
+func ParseFile(path string) ([]Row, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening payout file: %w", err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+func Parse(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading payout file: %w", err)
+	}
+
+	var rows []Row
+	var rowErrors []RowError
+
+	for i, record := range records {
+		line := i + 1
+
+		if i == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		if len(record) < 2 || len(record) > 3 {
+			rowErrors = append(rowErrors, RowError{line, "expected columns: address,amount[,token]"})
+			continue
+		}
+
+		row := Row{Line: line, Amount: strings.TrimSpace(record[1])}
+
+		recipient := strings.TrimSpace(record[0])
+		if !crypto.IsChecksumAddress(recipient) {
+			rowErrors = append(rowErrors, RowError{line, fmt.Sprintf("recipient %q is not a checksummed address", recipient)})
+			continue
+		}
+		row.Recipient = common.HexToAddress(recipient)
+
+		if len(record) == 3 && strings.TrimSpace(record[2]) != "" {
+			token := strings.TrimSpace(record[2])
+			if !crypto.IsChecksumAddress(token) {
+				rowErrors = append(rowErrors, RowError{line, fmt.Sprintf("token %q is not a checksummed address", token)})
+				continue
+			}
+			tokenAddr := common.HexToAddress(token)
+			row.Token = &tokenAddr
+		}
+
+		maxDecimals := 18
+		if row.Token != nil {
+			maxDecimals = math.MaxUint8
+		}
+
+		if _, err := crypto.ParseUnits(row.Amount, maxDecimals); err != nil {
+			rowErrors = append(rowErrors, RowError{line, err.Error()})
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, &ValidationError{Errors: rowErrors}
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("payout file contains no rows")
+	}
+
+	return rows, nil
+}
+
+func Resolve(client *blockchain.Client, rows []Row) (map[string]int, error) {
+	decimals := map[string]int{NativeAsset: 18}
+	var rowErrors []RowError
+
+	for i := range rows {
+		asset := rows[i].Asset()
+
+		if _, ok := decimals[asset]; !ok {
+			tokenDecimals, err := client.GetTokenDecimals(*rows[i].Token)
+			if err != nil {
+				return nil, fmt.Errorf("error getting decimals of token %s: %w", asset, err)
+			}
+			decimals[asset] = int(tokenDecimals)
+		}
+
+		value, err := crypto.ParseUnits(rows[i].Amount, decimals[asset])
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{rows[i].Line, err.Error()})
+			continue
+		}
+
+		if value.Sign() == 0 {
+			rowErrors = append(rowErrors, RowError{rows[i].Line, "amount must be positive"})
+			continue
+		}
+
+		rows[i].Value = value
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, &ValidationError{Errors: rowErrors}
+	}
+
+	return decimals, nil
+}
+
+func Estimate(client *blockchain.Client, from common.Address, rows []Row, decimals map[string]int, gasPrice *big.Int) (*Summary, error) {
+	summary := &Summary{
+		Totals:   make(map[string]*big.Int),
+		Decimals: decimals,
+	}
+
+	for _, row := range rows {
+		asset := row.Asset()
+		if summary.Totals[asset] == nil {
+			summary.Totals[asset] = new(big.Int)
+		}
+		summary.Totals[asset].Add(summary.Totals[asset], row.Value)
+
+		to, value, data, err := row.call()
+		if err != nil {
+			return nil, err
+		}
+
+		gasLimit, err := client.EstimateGas(from, &to, value, data)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.Line, err)
+		}
+		summary.GasLimit += gasLimit
+	}
+
+	summary.GasPrice = gasPrice
+	summary.Fee = new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(summary.GasLimit))
+
+	return summary, nil
+}
+
+func (r Row) call() (common.Address, *big.Int, []byte, error) {
+	if r.Token == nil {
+		return r.Recipient, r.Value, nil, nil
+	}
+
+	data, err := blockchain.PackTokenTransfer(r.Recipient, r.Value)
+	if err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("line %d: %w", r.Line, err)
+	}
+
+	return *r.Token, new(big.Int), data, nil
+}
+
+func fingerprint(rows []Row) string {
+	hash := sha256.New()
+	for _, row := range rows {
+		fmt.Fprintf(hash, "%d,%s,%s,%s\n", row.Line, row.Recipient.Hex(), row.Amount, row.Asset())
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
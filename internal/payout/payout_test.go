@@ -0,0 +1,68 @@
+package payout
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	alice = "0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6"
+	bob   = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	token = "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"
+)
+
+func TestParseValidRows(t *testing.T) {
+	input := "address,amount,token\n" +
+		alice + ",0.5\n" +
+		bob + ",12.25," + token + "\n"
+
+	rows, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Ошибка разбора файла: %v", err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("Ожидалось 2 строки, получено %d", len(rows))
+	}
+
+	if rows[0].Line != 2 || rows[0].Token != nil || rows[0].Asset() != NativeAsset {
+		t.Fatalf("Неверная первая строка: %+v", rows[0])
+	}
+
+	if rows[1].Token == nil || rows[1].Token.Hex() != token || rows[1].Amount != "12.25" {
+		t.Fatalf("Неверная вторая строка: %+v", rows[1])
+	}
+}
+
+func TestParseReportsEveryInvalidRow(t *testing.T) {
+	input := strings.ToLower(alice) + ",1\n" +
+		bob + ",1e18\n" +
+		bob + ",1,0xnot-a-token\n" +
+		bob + "\n" +
+		alice + ",0.0000000000000000001\n" +
+		alice + ",1\n"
+
+	_, err := Parse(strings.NewReader(input))
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Ожидалась ошибка валидации, получено %v", err)
+	}
+
+	if len(validationErr.Errors) != 5 {
+		t.Fatalf("Ожидалось 5 ошибок, получено %d: %v", len(validationErr.Errors), err)
+	}
+
+	for i, rowErr := range validationErr.Errors {
+		if rowErr.Line != i+1 {
+			t.Fatalf("Ошибка %d относится к строке %d", i, rowErr.Line)
+		}
+	}
+}
+
+func TestParseEmptyFile(t *testing.T) {
+	if _, err := Parse(strings.NewReader("address,amount\n")); err == nil {
+		t.Fatal("Должна быть ошибка для файла без строк")
+	}
+}
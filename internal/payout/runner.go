@@ -0,0 +1,324 @@
+package payout
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusSigned    = "signed"
+	StatusSent      = "sent"
+	StatusConfirmed = "confirmed"
+	StatusFailed    = "failed"
+	StatusError     = "error"
+)
+
+type Result struct {
+	Line      int     `json:"line"`
+	Recipient string  `json:"recipient"`
+	Amount    string  `json:"amount"`
+	Asset     string  `json:"asset"`
+	Nonce     *uint64 `json:"nonce,omitempty"`
+	TxHash    string  `json:"tx_hash,omitempty"`
+	RawTx     string  `json:"raw_tx,omitempty"`
+	Status    string  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type state struct {
+	Fingerprint string    `json:"fingerprint"`
+	Results     []*Result `json:"results"`
+}
+
+type Runner struct {
+	Wallet       *wallet.Wallet
+	StatePath    string
+	WaitAttempts int
+	Progress     func(result Result)
+}
+
+func (r *Runner) Run(rows []Row) ([]Result, error) {
+	st, err := r.loadState(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	nextNonce, err := r.Wallet.Blockchain.GetNonce(r.Wallet.KeyPair.Address)
+	if err != nil {
+		return r.results(st), fmt.Errorf("error getting nonce: %w", err)
+	}
+
+	for i, row := range rows {
+		result := st.Results[i]
+		if result.Status == StatusError && result.RawTx != "" {
+			result.Status = StatusSigned
+		}
+
+		switch result.Status {
+		case StatusSent, StatusConfirmed, StatusFailed:
+			continue
+		case StatusSigned:
+			tx, err := r.recoverSigned(result, nextNonce)
+			if err != nil {
+				err = r.keepSigned(st, result, err)
+				return r.results(st), err
+			}
+			if tx != nil {
+				if err := r.Wallet.Broadcast(tx); err != nil {
+					err = r.keepSigned(st, result, err)
+					return r.results(st), err
+				}
+			}
+			result.Status = StatusSent
+			result.Error = ""
+			if *result.Nonce >= nextNonce {
+				nextNonce = *result.Nonce + 1
+			}
+			if err := r.saveState(st); err != nil {
+				return r.results(st), err
+			}
+			r.report(result)
+			continue
+		}
+
+		nonce := nextNonce
+		result.Nonce = &nonce
+		result.Error = ""
+
+		to, value, data, err := row.call()
+		if err != nil {
+			err = r.fail(st, result, err)
+			return r.results(st), err
+		}
+
+		signedTx, err := r.Wallet.SignRequest(wallet.TxRequest{
+			To:    to,
+			Value: value,
+			Data:  data,
+			Nonce: &nonce,
+		})
+		if err != nil {
+			err = r.fail(st, result, err)
+			return r.results(st), err
+		}
+
+		raw, err := signedTx.MarshalBinary()
+		if err != nil {
+			err = r.fail(st, result, err)
+			return r.results(st), err
+		}
+
+		result.TxHash = signedTx.Hash().Hex()
+		result.RawTx = hex.EncodeToString(raw)
+		result.Status = StatusSigned
+		if err := r.saveState(st); err != nil {
+			return r.results(st), err
+		}
+
+		if err := r.Wallet.Broadcast(signedTx); err != nil {
+			err = r.keepSigned(st, result, err)
+			return r.results(st), err
+		}
+
+		result.Status = StatusSent
+		if err := r.saveState(st); err != nil {
+			return r.results(st), err
+		}
+		r.report(result)
+
+		nextNonce++
+	}
+
+	for _, result := range st.Results {
+		if result.Status != StatusSent {
+			continue
+		}
+
+		receipt, err := r.Wallet.Blockchain.WaitForTransaction(common.HexToHash(result.TxHash), r.waitAttempts())
+		if err != nil {
+			continue
+		}
+
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			result.Status = StatusConfirmed
+		} else {
+			result.Status = StatusFailed
+		}
+
+		if err := r.saveState(st); err != nil {
+			return r.results(st), err
+		}
+		r.report(result)
+	}
+
+	return r.results(st), nil
+}
+
+func (r *Runner) recoverSigned(result *Result, chainNonce uint64) (*types.Transaction, error) {
+	hash := common.HexToHash(result.TxHash)
+
+	if receipt, err := r.Wallet.Blockchain.GetTransactionReceipt(hash); err == nil && receipt != nil {
+		return nil, nil
+	}
+
+	if tx, _, err := r.Wallet.Blockchain.GetTransaction(hash); err == nil && tx != nil {
+		return nil, nil
+	}
+
+	if *result.Nonce < chainNonce {
+		return nil, fmt.Errorf("nonce %d was consumed by another transaction, check %s manually", *result.Nonce, result.TxHash)
+	}
+
+	raw, err := hex.DecodeString(result.RawTx)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding stored transaction: %w", err)
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("error decoding stored transaction: %w", err)
+	}
+
+	return tx, nil
+}
+
+func (r *Runner) fail(st *state, result *Result, err error) error {
+	result.Status = StatusError
+	result.Error = err.Error()
+
+	if saveErr := r.saveState(st); saveErr != nil {
+		return saveErr
+	}
+	r.report(result)
+
+	return fmt.Errorf("line %d: %w", result.Line, err)
+}
+
+func (r *Runner) keepSigned(st *state, result *Result, err error) error {
+	result.Error = err.Error()
+
+	if saveErr := r.saveState(st); saveErr != nil {
+		return saveErr
+	}
+	r.report(result)
+
+	return fmt.Errorf("line %d: %w", result.Line, err)
+}
+
+func (r *Runner) report(result *Result) {
+	if r.Progress != nil {
+		r.Progress(*result)
+	}
+}
+
+func (r *Runner) waitAttempts() int {
+	if r.WaitAttempts > 0 {
+		return r.WaitAttempts
+	}
+	return 60
+}
+
+func (r *Runner) results(st *state) []Result {
+	results := make([]Result, len(st.Results))
+	for i, result := range st.Results {
+		results[i] = *result
+	}
+	return results
+}
+
+func (r *Runner) loadState(rows []Row) (*state, error) {
+	expected := fingerprint(rows)
+
+	data, err := os.ReadFile(r.StatePath)
+	if err == nil {
+		var st state
+		if err := json.Unmarshal(data, &st); err != nil {
+			return nil, fmt.Errorf("error parsing payout state: %w", err)
+		}
+		if st.Fingerprint != expected || len(st.Results) != len(rows) {
+			return nil, fmt.Errorf("payout state %s belongs to a different payout file", r.StatePath)
+		}
+		return &st, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading payout state: %w", err)
+	}
+
+	st := &state{Fingerprint: expected, Results: make([]*Result, len(rows))}
+	for i, row := range rows {
+		st.Results[i] = &Result{
+			Line:      row.Line,
+			Recipient: row.Recipient.Hex(),
+			Amount:    row.Amount,
+			Asset:     row.Asset(),
+			Status:    StatusPending,
+		}
+	}
+
+	return st, nil
+}
+
+func (r *Runner) saveState(st *state) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing payout state: %w", err)
+	}
+
+	tmp := r.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("error writing payout state: %w", err)
+	}
+
+	if err := os.Rename(tmp, r.StatePath); err != nil {
+		return fmt.Errorf("error writing payout state: %w", err)
+	}
+
+	return nil
+}
+
+func WriteResults(path string, results []Result) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating results file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"line", "address", "amount", "asset", "nonce", "tx_hash", "status", "error"})
+
+	for _, result := range results {
+		nonce := ""
+		if result.Nonce != nil {
+			nonce = strconv.FormatUint(*result.Nonce, 10)
+		}
+
+		writer.Write([]string{
+			strconv.Itoa(result.Line),
+			result.Recipient,
+			result.Amount,
+			result.Asset,
+			nonce,
+			result.TxHash,
+			result.Status,
+			result.Error,
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing results file: %w", err)
+	}
+
+	return nil
+}
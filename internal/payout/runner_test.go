@@ -0,0 +1,320 @@
+package payout
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"crypto-wallet/internal/rpctest"
+	"crypto-wallet/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type testChain struct {
+	*rpctest.Server
+
+	mu     sync.Mutex
+	nonce  uint64
+	sent   []*types.Transaction
+	sends  int
+	failOn int
+	landed bool
+}
+
+func newTestChain(t *testing.T, nonce uint64) *testChain {
+	chain := &testChain{Server: rpctest.NewServer(), nonce: nonce}
+	t.Cleanup(chain.Close)
+
+	chain.Handle("net_version", func(params []json.RawMessage) (interface{}, error) {
+		return "11155111", nil
+	})
+	chain.Handle("eth_getTransactionCount", func(params []json.RawMessage) (interface{}, error) {
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		return hexutil.Uint64(chain.nonce), nil
+	})
+	chain.Handle("eth_gasPrice", func(params []json.RawMessage) (interface{}, error) {
+		return "0x3b9aca00", nil
+	})
+	chain.Handle("eth_estimateGas", func(params []json.RawMessage) (interface{}, error) {
+		return "0x5208", nil
+	})
+	chain.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		return hexutil.Bytes(common.LeftPadBytes([]byte{6}, 32)), nil
+	})
+	chain.Handle("eth_getTransactionByHash", func(params []json.RawMessage) (interface{}, error) {
+		return nil, nil
+	})
+	chain.Handle("eth_sendRawTransaction", func(params []json.RawMessage) (interface{}, error) {
+		var raw hexutil.Bytes
+		json.Unmarshal(params[0], &raw)
+
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, err
+		}
+
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+
+		chain.sends++
+		if chain.sends == chain.failOn {
+			if chain.landed {
+				chain.sent = append(chain.sent, tx)
+				chain.nonce = tx.Nonce() + 1
+				return nil, fmt.Errorf("request timed out")
+			}
+			return nil, fmt.Errorf("insufficient funds for gas * price + value")
+		}
+
+		chain.sent = append(chain.sent, tx)
+		chain.nonce = tx.Nonce() + 1
+		return tx.Hash(), nil
+	})
+	chain.Handle("eth_getTransactionReceipt", func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		json.Unmarshal(params[0], &hash)
+
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+
+		for _, tx := range chain.sent {
+			if tx.Hash() == hash {
+				return &types.Receipt{
+					Status:      types.ReceiptStatusSuccessful,
+					TxHash:      hash,
+					BlockNumber: big.NewInt(100),
+					GasUsed:     21000,
+					Logs:        []*types.Log{},
+				}, nil
+			}
+		}
+		return nil, nil
+	})
+
+	return chain
+}
+
+func (c *testChain) sentNonces() []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	nonces := make([]uint64, len(c.sent))
+	for i, tx := range c.sent {
+		nonces[i] = tx.Nonce()
+	}
+	return nonces
+}
+
+func newTestWallet(t *testing.T, chain *testChain) *wallet.Wallet {
+	w, err := wallet.NewWallet(chain.URL, filepath.Join(t.TempDir(), "wallet.json"))
+	if err != nil {
+		t.Fatalf("Ошибка создания кошелька: %v", err)
+	}
+	t.Cleanup(w.Close)
+
+	if err := w.GenerateNewWallet(); err != nil {
+		t.Fatalf("Ошибка генерации кошелька: %v", err)
+	}
+	return w
+}
+
+func testRows(t *testing.T, w *wallet.Wallet) []Row {
+	input := alice + ",0.5\n" +
+		bob + ",1.5\n" +
+		alice + ",12.25," + token + "\n"
+
+	rows, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Ошибка разбора файла: %v", err)
+	}
+
+	if _, err := Resolve(w.Blockchain, rows); err != nil {
+		t.Fatalf("Ошибка проверки сумм: %v", err)
+	}
+	return rows
+}
+
+func TestResolveAndEstimate(t *testing.T) {
+	chain := newTestChain(t, 0)
+	w := newTestWallet(t, chain)
+	rows := testRows(t, w)
+
+	if rows[2].Value.String() != "12250000" {
+		t.Fatalf("Сумма токена должна учитывать 6 знаков, получено %s", rows[2].Value)
+	}
+
+	decimals := map[string]int{NativeAsset: 18, token: 6}
+	gasPrice, err := w.GasPrice()
+	if err != nil {
+		t.Fatalf("Ошибка получения цены газа: %v", err)
+	}
+
+	summary, err := Estimate(w.Blockchain, w.KeyPair.Address, rows, decimals, gasPrice)
+	if err != nil {
+		t.Fatalf("Ошибка оценки: %v", err)
+	}
+
+	if summary.Totals[NativeAsset].String() != "2000000000000000000" {
+		t.Fatalf("Неверная сумма ETH: %s", summary.Totals[NativeAsset])
+	}
+
+	if summary.GasLimit != 3*21000 {
+		t.Fatalf("Неверный суммарный лимит газа: %d", summary.GasLimit)
+	}
+
+	if summary.Fee.String() != "63000000000000" {
+		t.Fatalf("Неверная оценка комиссии: %s", summary.Fee)
+	}
+}
+
+func TestRunAssignsSequentialNonces(t *testing.T) {
+	chain := newTestChain(t, 5)
+	w := newTestWallet(t, chain)
+	rows := testRows(t, w)
+
+	runner := &Runner{Wallet: w, StatePath: filepath.Join(t.TempDir(), "state.json"), WaitAttempts: 1}
+	results, err := runner.Run(rows)
+	if err != nil {
+		t.Fatalf("Ошибка выплаты: %v", err)
+	}
+
+	nonces := chain.sentNonces()
+	if fmt.Sprint(nonces) != "[5 6 7]" {
+		t.Fatalf("Неверные nonce: %v", nonces)
+	}
+
+	for _, result := range results {
+		if result.Status != StatusConfirmed || result.TxHash == "" {
+			t.Fatalf("Неверный результат: %+v", result)
+		}
+	}
+
+	tokenTx := chain.sent[2]
+	if tokenTx.To() == nil || tokenTx.To().Hex() != token || tokenTx.Value().Sign() != 0 {
+		t.Fatal("Перевод токена должен отправляться на контракт без ETH")
+	}
+	if common.BytesToAddress(tokenTx.Data()[4:36]).Hex() != alice {
+		t.Fatal("Неверный получатель токенов")
+	}
+}
+
+func TestRunResumesAfterFailure(t *testing.T) {
+	chain := newTestChain(t, 0)
+	w := newTestWallet(t, chain)
+	rows := testRows(t, w)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	// Первая транзакция проходит, вторая падает
+	chain.mu.Lock()
+	chain.failOn = 2
+	chain.mu.Unlock()
+
+	runner := &Runner{Wallet: w, StatePath: statePath, WaitAttempts: 1}
+	results, err := runner.Run(rows)
+	if err == nil {
+		t.Fatal("Должна быть ошибка при сбое отправки")
+	}
+
+	if results[0].Status != StatusSent || results[1].Status != StatusSigned || results[2].Status != StatusPending {
+		t.Fatalf("Неверные статусы после сбоя: %s %s %s", results[0].Status, results[1].Status, results[2].Status)
+	}
+
+	results, err = runner.Run(rows)
+	if err != nil {
+		t.Fatalf("Ошибка возобновления: %v", err)
+	}
+
+	if fmt.Sprint(chain.sentNonces()) != "[0 1 2]" {
+		t.Fatalf("Транзакции не должны повторяться: %v", chain.sentNonces())
+	}
+
+	for _, result := range results {
+		if result.Status != StatusConfirmed {
+			t.Fatalf("Неверный статус после возобновления: %+v", result)
+		}
+	}
+
+	resultsPath := filepath.Join(t.TempDir(), "results.csv")
+	if err := WriteResults(resultsPath, results); err != nil {
+		t.Fatalf("Ошибка записи результатов: %v", err)
+	}
+
+	file, err := os.Open(resultsPath)
+	if err != nil {
+		t.Fatalf("Ошибка открытия результатов: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Ошибка чтения результатов: %v", err)
+	}
+
+	if len(records) != 4 || records[2][4] != "1" || records[2][6] != StatusConfirmed {
+		t.Fatalf("Неверный файл результатов: %v", records)
+	}
+}
+
+func TestRunDoesNotRepayLandedBroadcast(t *testing.T) {
+	chain := newTestChain(t, 0)
+	w := newTestWallet(t, chain)
+	rows := testRows(t, w)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	// Отправка второй транзакции завершается ошибкой, но транзакция попадает в сеть
+	chain.mu.Lock()
+	chain.failOn = 2
+	chain.landed = true
+	chain.mu.Unlock()
+
+	runner := &Runner{Wallet: w, StatePath: statePath, WaitAttempts: 1}
+	results, err := runner.Run(rows)
+	if err == nil {
+		t.Fatal("Должна быть ошибка при сбое отправки")
+	}
+	if results[1].Status != StatusSigned || results[1].RawTx == "" {
+		t.Fatalf("Строка должна остаться подписанной: %+v", results[1])
+	}
+	signedHash := results[1].TxHash
+
+	results, err = runner.Run(rows)
+	if err != nil {
+		t.Fatalf("Ошибка возобновления: %v", err)
+	}
+
+	if fmt.Sprint(chain.sentNonces()) != "[0 1 2]" {
+		t.Fatalf("Выплата не должна подписываться повторно: %v", chain.sentNonces())
+	}
+	if chain.sends != 3 {
+		t.Fatalf("Попавшая в сеть транзакция не должна отправляться снова, отправок: %d", chain.sends)
+	}
+	if results[1].TxHash != signedHash || results[1].Status != StatusConfirmed || results[1].Error != "" {
+		t.Fatalf("Неверный результат восстановленной строки: %+v", results[1])
+	}
+}
+
+func TestRunRejectsForeignState(t *testing.T) {
+	chain := newTestChain(t, 0)
+	w := newTestWallet(t, chain)
+	rows := testRows(t, w)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	runner := &Runner{Wallet: w, StatePath: statePath, WaitAttempts: 1}
+	if _, err := runner.Run(rows); err != nil {
+		t.Fatalf("Ошибка выплаты: %v", err)
+	}
+
+	if _, err := runner.Run(rows[:2]); err == nil {
+		t.Fatal("Должна быть ошибка для состояния другой выплаты")
+	}
+}
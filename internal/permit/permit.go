@@ -0,0 +1,265 @@
+package permit
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"crypto-wallet/internal/blockchain"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var (
+	MaxUint160 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+	MaxUint48  = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 48), big.NewInt(1))
+)
+
+var permitTypes = []apitypes.Type{
+	{Name: "owner", Type: "address"},
+	{Name: "spender", Type: "address"},
+	{Name: "value", Type: "uint256"},
+	{Name: "nonce", Type: "uint256"},
+	{Name: "deadline", Type: "uint256"},
+}
+
+var permitSingleTypes = apitypes.Types{
+	"PermitSingle": {
+		{Name: "details", Type: "PermitDetails"},
+		{Name: "spender", Type: "address"},
+		{Name: "sigDeadline", Type: "uint256"},
+	},
+	"PermitDetails": {
+		{Name: "token", Type: "address"},
+		{Name: "amount", Type: "uint160"},
+		{Name: "expiration", Type: "uint48"},
+		{Name: "nonce", Type: "uint48"},
+	},
+}
+
+var permitTransferFromTypes = apitypes.Types{
+	"PermitTransferFrom": {
+		{Name: "permitted", Type: "TokenPermissions"},
+		{Name: "spender", Type: "address"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "deadline", Type: "uint256"},
+	},
+	"TokenPermissions": {
+		{Name: "token", Type: "address"},
+		{Name: "amount", Type: "uint256"},
+	},
+}
+
+type Signature struct {
+	V uint8
+	R common.Hash
+	S common.Hash
+}
+
+func ParseSignature(sig []byte) (Signature, error) {
+	if len(sig) != 65 {
+		return Signature{}, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+
+	v := sig[64]
+	if v < 27 {
+		v += 27
+	}
+	if v != 27 && v != 28 {
+		return Signature{}, fmt.Errorf("invalid signature recovery id %d", sig[64])
+	}
+
+	return Signature{V: v, R: common.BytesToHash(sig[:32]), S: common.BytesToHash(sig[32:64])}, nil
+}
+
+func (s Signature) Packed() []byte {
+	packed := make([]byte, 0, 65)
+	packed = append(packed, s.R[:]...)
+	packed = append(packed, s.S[:]...)
+	return append(packed, s.V)
+}
+
+func domainTypes(domain apitypes.TypedDataDomain) []apitypes.Type {
+	var types []apitypes.Type
+	if domain.Name != "" {
+		types = append(types, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if domain.Version != "" {
+		types = append(types, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if domain.ChainId != nil {
+		types = append(types, apitypes.Type{Name: "chainId", Type: "uint256"})
+	}
+	if domain.VerifyingContract != "" {
+		types = append(types, apitypes.Type{Name: "verifyingContract", Type: "address"})
+	}
+	if domain.Salt != "" {
+		types = append(types, apitypes.Type{Name: "salt", Type: "bytes32"})
+	}
+	return types
+}
+
+func newTypedData(domain apitypes.TypedDataDomain, primaryType string, types apitypes.Types, message apitypes.TypedDataMessage) apitypes.TypedData {
+	all := apitypes.Types{"EIP712Domain": domainTypes(domain)}
+	for name, fields := range types {
+		all[name] = fields
+	}
+
+	return apitypes.TypedData{
+		Types:       all,
+		PrimaryType: primaryType,
+		Domain:      domain,
+		Message:     message,
+	}
+}
+
+func DomainSeparator(domain apitypes.TypedDataDomain) (common.Hash, error) {
+	typedData := apitypes.TypedData{
+		Types:  apitypes.Types{"EIP712Domain": domainTypes(domain)},
+		Domain: domain,
+	}
+
+	hash, err := typedData.HashStruct("EIP712Domain", domain.Map())
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error hashing domain: %w", err)
+	}
+	return common.BytesToHash(hash), nil
+}
+
+func TokenDomain(client *blockchain.Client, token common.Address, chainID *big.Int) (apitypes.TypedDataDomain, error) {
+	if domain, err := client.GetEIP712Domain(token); err == nil {
+		return eip5267Domain(domain), nil
+	}
+
+	separator, err := client.GetDomainSeparator(token)
+	if err != nil {
+		return apitypes.TypedDataDomain{}, fmt.Errorf("%s does not support EIP-2612 permits: %w", token.Hex(), err)
+	}
+
+	name, err := client.GetContractName(token)
+	if err != nil {
+		return apitypes.TypedDataDomain{}, err
+	}
+
+	versions := []string{"1", "2", ""}
+	if version, err := client.GetContractVersion(token); err == nil {
+		versions = append([]string{version}, versions...)
+	}
+
+	for _, version := range versions {
+		domain := apitypes.TypedDataDomain{
+			Name:              name,
+			Version:           version,
+			ChainId:           (*math.HexOrDecimal256)(chainID),
+			VerifyingContract: token.Hex(),
+		}
+
+		hash, err := DomainSeparator(domain)
+		if err == nil && bytes.Equal(hash[:], separator[:]) {
+			return domain, nil
+		}
+	}
+
+	return apitypes.TypedDataDomain{}, fmt.Errorf("cannot reproduce the DOMAIN_SEPARATOR of %s (%s)", token.Hex(), separator.Hex())
+}
+
+func eip5267Domain(domain *blockchain.EIP712Domain) apitypes.TypedDataDomain {
+	var result apitypes.TypedDataDomain
+
+	if domain.Fields&blockchain.DomainFieldName != 0 {
+		result.Name = domain.Name
+	}
+	if domain.Fields&blockchain.DomainFieldVersion != 0 {
+		result.Version = domain.Version
+	}
+	if domain.Fields&blockchain.DomainFieldChainID != 0 {
+		result.ChainId = (*math.HexOrDecimal256)(domain.ChainID)
+	}
+	if domain.Fields&blockchain.DomainFieldVerifyingContract != 0 {
+		result.VerifyingContract = domain.VerifyingContract.Hex()
+	}
+	if domain.Fields&blockchain.DomainFieldSalt != 0 {
+		result.Salt = domain.Salt.Hex()
+	}
+
+	return result
+}
+
+func NewPermit(domain apitypes.TypedDataDomain, owner, spender common.Address, value, nonce, deadline *big.Int) apitypes.TypedData {
+	return newTypedData(domain, "Permit", apitypes.Types{"Permit": permitTypes}, apitypes.TypedDataMessage{
+		"owner":    owner.Hex(),
+		"spender":  spender.Hex(),
+		"value":    value.String(),
+		"nonce":    nonce.String(),
+		"deadline": deadline.String(),
+	})
+}
+
+func Permit2Domain(chainID *big.Int) apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              "Permit2",
+		ChainId:           (*math.HexOrDecimal256)(chainID),
+		VerifyingContract: blockchain.Permit2Address.Hex(),
+	}
+}
+
+type PermitDetails struct {
+	Token      common.Address
+	Amount     *big.Int
+	Expiration uint64
+	Nonce      uint64
+}
+
+func NewPermitSingle(chainID *big.Int, details PermitDetails, spender common.Address, sigDeadline *big.Int) (apitypes.TypedData, error) {
+	if details.Amount.Sign() < 0 || details.Amount.Cmp(MaxUint160) > 0 {
+		return apitypes.TypedData{}, fmt.Errorf("Permit2 amount must fit in uint160")
+	}
+	if new(big.Int).SetUint64(details.Expiration).Cmp(MaxUint48) > 0 || new(big.Int).SetUint64(details.Nonce).Cmp(MaxUint48) > 0 {
+		return apitypes.TypedData{}, fmt.Errorf("Permit2 expiration and nonce must fit in uint48")
+	}
+
+	return newTypedData(Permit2Domain(chainID), "PermitSingle", permitSingleTypes, apitypes.TypedDataMessage{
+		"details": map[string]interface{}{
+			"token":      details.Token.Hex(),
+			"amount":     details.Amount.String(),
+			"expiration": fmt.Sprint(details.Expiration),
+			"nonce":      fmt.Sprint(details.Nonce),
+		},
+		"spender":     spender.Hex(),
+		"sigDeadline": sigDeadline.String(),
+	}), nil
+}
+
+func NewPermitTransferFrom(chainID *big.Int, token common.Address, amount *big.Int, spender common.Address, nonce, deadline *big.Int) apitypes.TypedData {
+	return newTypedData(Permit2Domain(chainID), "PermitTransferFrom", permitTransferFromTypes, apitypes.TypedDataMessage{
+		"permitted": map[string]interface{}{
+			"token":  token.Hex(),
+			"amount": amount.String(),
+		},
+		"spender":  spender.Hex(),
+		"nonce":    nonce.String(),
+		"deadline": deadline.String(),
+	})
+}
+
+func NextUnorderedNonce(client *blockchain.Client, owner common.Address, maxWords int) (*big.Int, error) {
+	for word := 0; word < maxWords; word++ {
+		wordPos := big.NewInt(int64(word))
+
+		bitmap, err := client.GetPermit2NonceBitmap(owner, wordPos)
+		if err != nil {
+			return nil, err
+		}
+
+		for bit := 0; bit < 256; bit++ {
+			if bitmap.Bit(bit) == 0 {
+				nonce := new(big.Int).Lsh(wordPos, 8)
+				return nonce.Or(nonce, big.NewInt(int64(bit))), nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no unused Permit2 nonce in the first %d words", maxWords)
+}
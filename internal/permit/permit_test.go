@@ -0,0 +1,184 @@
+package permit
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var (
+	usdc    = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	spender = common.HexToAddress("0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD")
+)
+
+func usdcDomain() apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainId:           (*math.HexOrDecimal256)(big.NewInt(1)),
+		VerifyingContract: usdc.Hex(),
+	}
+}
+
+func TestDomainSeparator(t *testing.T) {
+	tests := map[string]apitypes.TypedDataDomain{
+		"0x06c37168a7db5138defc7866392bb87a741f9b3d104deb5094588ce041cae335": usdcDomain(),
+		"0x866a5aba21966af95d6c7ab78eb2b2fc913915c28be3b9aa07cc04ff903e3f28": Permit2Domain(big.NewInt(1)),
+	}
+
+	for expected, domain := range tests {
+		hash, err := DomainSeparator(domain)
+		if err != nil {
+			t.Fatalf("Ошибка вычисления разделителя домена %s: %v", domain.Name, err)
+		}
+		if hash.Hex() != expected {
+			t.Errorf("Разделитель домена %s: %s, ожидалось %s", domain.Name, hash.Hex(), expected)
+		}
+	}
+}
+
+func signAndRecover(t *testing.T, typedData apitypes.TypedData) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа: %v", err)
+	}
+
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		t.Fatalf("Ошибка хеширования %s: %v", typedData.PrimaryType, err)
+	}
+
+	raw, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("Ошибка подписи: %v", err)
+	}
+
+	signature, err := ParseSignature(raw)
+	if err != nil {
+		t.Fatalf("Ошибка разбора подписи: %v", err)
+	}
+	if signature.V != 27 && signature.V != 28 {
+		t.Errorf("v должен быть 27 или 28, получено %d", signature.V)
+	}
+
+	packed := signature.Packed()
+	if len(packed) != 65 || !bytes.Equal(packed[:64], raw[:64]) || packed[64] != signature.V {
+		t.Errorf("Неверная упакованная подпись: %x", packed)
+	}
+
+	packed[64] -= 27
+	public, err := crypto.SigToPub(hash, packed)
+	if err != nil || crypto.PubkeyToAddress(*public) != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("Подпись %s восстанавливает неверный адрес", typedData.PrimaryType)
+	}
+}
+
+func TestPermitSignatures(t *testing.T) {
+	owner := common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+	chainID := big.NewInt(1)
+
+	signAndRecover(t, NewPermit(usdcDomain(), owner, spender, big.NewInt(1000000), big.NewInt(0), big.NewInt(1700000000)))
+
+	single, err := NewPermitSingle(chainID, PermitDetails{Token: usdc, Amount: MaxUint160, Expiration: 1700000000, Nonce: 3}, spender, big.NewInt(1700000000))
+	if err != nil {
+		t.Fatalf("Ошибка создания PermitSingle: %v", err)
+	}
+	signAndRecover(t, single)
+
+	signAndRecover(t, NewPermitTransferFrom(chainID, usdc, big.NewInt(5), spender, big.NewInt(1<<8+3), big.NewInt(1700000000)))
+
+	_, err = NewPermitSingle(chainID, PermitDetails{Token: usdc, Amount: new(big.Int).Add(MaxUint160, big.NewInt(1))}, spender, big.NewInt(0))
+	if err == nil {
+		t.Error("Сумма больше uint160 должна отклоняться")
+	}
+}
+
+func TestParseSignatureInvalid(t *testing.T) {
+	if _, err := ParseSignature(make([]byte, 64)); err == nil {
+		t.Error("Подпись неверной длины должна отклоняться")
+	}
+
+	sig := make([]byte, 65)
+	sig[64] = 5
+	if _, err := ParseSignature(sig); err == nil {
+		t.Error("Неверный recovery id должен отклоняться")
+	}
+}
+
+func newTokenClient(t *testing.T, handle func(selector []byte) (interface{}, error)) *blockchain.Client {
+	server := rpctest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		var args struct {
+			Data  hexutil.Bytes `json:"data"`
+			Input hexutil.Bytes `json:"input"`
+		}
+		json.Unmarshal(params[0], &args)
+		if len(args.Data) == 0 {
+			args.Data = args.Input
+		}
+		return handle(args.Data[:4])
+	})
+
+	client, err := blockchain.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Ошибка создания клиента: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func selector(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+func encodeString(s string) hexutil.Bytes {
+	data := common.LeftPadBytes(big.NewInt(32).Bytes(), 32)
+	data = append(data, common.LeftPadBytes(big.NewInt(int64(len(s))).Bytes(), 32)...)
+	return append(data, common.RightPadBytes([]byte(s), (len(s)+31)/32*32)...)
+}
+
+func TestTokenDomainFromSeparator(t *testing.T) {
+	separator, _ := DomainSeparator(usdcDomain())
+
+	client := newTokenClient(t, func(sel []byte) (interface{}, error) {
+		switch {
+		case bytes.Equal(sel, selector("DOMAIN_SEPARATOR()")):
+			return hexutil.Bytes(separator[:]), nil
+		case bytes.Equal(sel, selector("name()")):
+			return encodeString("USD Coin"), nil
+		}
+		return nil, &rpctest.Error{Code: 3, Message: "execution reverted"}
+	})
+
+	domain, err := TokenDomain(client, usdc, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("Ошибка определения домена: %v", err)
+	}
+	if domain.Name != "USD Coin" || domain.Version != "2" {
+		t.Errorf("Неверный домен: %+v", domain)
+	}
+}
+
+func TestTokenDomainWithoutPermit(t *testing.T) {
+	client := newTokenClient(t, func(sel []byte) (interface{}, error) {
+		return nil, &rpctest.Error{Code: 3, Message: "execution reverted"}
+	})
+
+	if _, err := TokenDomain(client, usdc, big.NewInt(1)); err == nil {
+		t.Error("Токен без DOMAIN_SEPARATOR не должен поддерживать permit")
+	}
+}
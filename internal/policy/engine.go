@@ -0,0 +1,201 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"crypto-wallet/internal/crypto"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const Window = 24 * time.Hour
+
+type Transaction struct {
+	Hash     common.Hash
+	To       common.Address
+	Value    *big.Int
+	Data     []byte
+	GasPrice *big.Int
+}
+
+type Spend struct {
+	Time   time.Time   `json:"time"`
+	TxHash common.Hash `json:"tx_hash"`
+	Asset  string      `json:"asset"`
+	Amount string      `json:"amount"`
+}
+
+type Engine struct {
+	rules      *rules
+	ledgerPath string
+	now        func() time.Time
+	mu         sync.Mutex
+}
+
+func NewEngine(policy *Policy, ledgerPath string) (*Engine, error) {
+	compiled, err := policy.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Engine{
+		rules:      compiled,
+		ledgerPath: ledgerPath,
+		now:        time.Now,
+	}, nil
+}
+
+func (e *Engine) Check(tx Transaction) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	r := e.rules
+
+	if r.maxGasPrice != nil && tx.GasPrice != nil && tx.GasPrice.Cmp(r.maxGasPrice) > 0 {
+		return &Violation{RuleMaxGasPrice, fmt.Sprintf("gas price %s gwei exceeds maximum %s gwei",
+			crypto.FormatUnits(tx.GasPrice, 9), crypto.FormatUnits(r.maxGasPrice, 9))}
+	}
+
+	if len(tx.Data) > 0 && len(r.methods) > 0 {
+		selectors, ok := r.methods[tx.To]
+		if !ok {
+			return &Violation{RuleAllowedMethods, fmt.Sprintf("contract %s is not in the method allowlist", tx.To.Hex())}
+		}
+
+		var selector [4]byte
+		copy(selector[:], tx.Data)
+		if len(tx.Data) < 4 || !selectors[selector] {
+			return &Violation{RuleAllowedMethods, fmt.Sprintf("method 0x%x is not allowed on %s", selector, tx.To.Hex())}
+		}
+	}
+
+	var ledger []Spend
+	for _, move := range movements(tx) {
+		if r.denied[move.recipient] || r.denied[tx.To] {
+			return &Violation{RuleDeniedRecipients, fmt.Sprintf("recipient %s is denied", move.recipient.Hex())}
+		}
+
+		if len(r.allowed) > 0 && !r.allowed[move.recipient] {
+			return &Violation{RuleAllowedRecipients, fmt.Sprintf("recipient %s is not in the allowlist", move.recipient.Hex())}
+		}
+
+		l, ok := r.assets[move.asset]
+		if !ok {
+			continue
+		}
+
+		if l.maxPerTx != nil && move.amount.Cmp(l.maxPerTx) > 0 {
+			return &Violation{RuleMaxPerTx, fmt.Sprintf("%s %s exceeds the per-transaction maximum of %s",
+				crypto.FormatUnits(move.amount, l.decimals), move.asset, crypto.FormatUnits(l.maxPerTx, l.decimals))}
+		}
+
+		if l.dailyLimit == nil {
+			continue
+		}
+
+		if ledger == nil {
+			var err error
+			if ledger, err = e.loadLedger(); err != nil {
+				return err
+			}
+		}
+
+		spent := e.spentSince(ledger, move.asset, e.now().Add(-Window))
+		total := new(big.Int).Add(spent, move.amount)
+		if total.Cmp(l.dailyLimit) > 0 {
+			return &Violation{RuleDailyLimit, fmt.Sprintf("%s %s would bring 24h spending to %s, above the limit of %s",
+				crypto.FormatUnits(move.amount, l.decimals), move.asset,
+				crypto.FormatUnits(total, l.decimals), crypto.FormatUnits(l.dailyLimit, l.decimals))}
+		}
+	}
+
+	return nil
+}
+
+func (e *Engine) Record(tx Transaction) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ledger, err := e.loadLedger()
+	if err != nil {
+		return err
+	}
+
+	for _, spend := range ledger {
+		if spend.TxHash == tx.Hash {
+			return nil
+		}
+	}
+
+	now := e.now()
+	cutoff := now.Add(-Window)
+
+	kept := ledger[:0]
+	for _, spend := range ledger {
+		if spend.Time.After(cutoff) {
+			kept = append(kept, spend)
+		}
+	}
+
+	for _, move := range movements(tx) {
+		if move.amount.Sign() == 0 {
+			continue
+		}
+		kept = append(kept, Spend{Time: now, TxHash: tx.Hash, Asset: move.asset, Amount: move.amount.String()})
+	}
+
+	return e.saveLedger(kept)
+}
+
+func (e *Engine) spentSince(ledger []Spend, asset string, since time.Time) *big.Int {
+	total := new(big.Int)
+	for _, spend := range ledger {
+		if spend.Asset != asset || !spend.Time.After(since) {
+			continue
+		}
+		if amount, ok := new(big.Int).SetString(spend.Amount, 10); ok {
+			total.Add(total, amount)
+		}
+	}
+	return total
+}
+
+func (e *Engine) loadLedger() ([]Spend, error) {
+	data, err := os.ReadFile(e.ledgerPath)
+	if os.IsNotExist(err) {
+		return []Spend{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading spending ledger: %w", err)
+	}
+
+	var ledger []Spend
+	if err := json.Unmarshal(data, &ledger); err != nil {
+		return nil, fmt.Errorf("error parsing spending ledger: %w", err)
+	}
+
+	return ledger, nil
+}
+
+func (e *Engine) saveLedger(ledger []Spend) error {
+	data, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing spending ledger: %w", err)
+	}
+
+	tmp := e.ledgerPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("error writing spending ledger: %w", err)
+	}
+
+	if err := os.Rename(tmp, e.ledgerPath); err != nil {
+		return fmt.Errorf("error writing spending ledger: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package policy
+
+import (
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"crypto-wallet/internal/blockchain"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	alice = common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+	bob   = common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	token = common.HexToAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359")
+)
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+}
+
+func newTestEngine(t *testing.T, p Policy) *Engine {
+	engine, err := NewEngine(&p, filepath.Join(t.TempDir(), "spending.json"))
+	if err != nil {
+		t.Fatalf("Ошибка создания политики: %v", err)
+	}
+	return engine
+}
+
+func expectViolation(t *testing.T, err error, rule string) {
+	t.Helper()
+
+	var violation *Violation
+	if !errors.As(err, &violation) {
+		t.Fatalf("Ожидалось нарушение %s, получено: %v", rule, err)
+	}
+	if violation.Rule != rule {
+		t.Errorf("Ожидалось правило %s, получено %s", rule, violation.Rule)
+	}
+}
+
+func tokenTransfer(t *testing.T, to common.Address, amount *big.Int) []byte {
+	data, err := blockchain.PackTokenTransfer(to, amount)
+	if err != nil {
+		t.Fatalf("Ошибка упаковки transfer: %v", err)
+	}
+	return data
+}
+
+func TestCheckMaxPerTx(t *testing.T) {
+	decimals := 6
+	engine := newTestEngine(t, Policy{Assets: map[string]AssetLimits{
+		"ETH":       {MaxPerTx: "1"},
+		token.Hex(): {Decimals: &decimals, MaxPerTx: "100"},
+	}})
+
+	if err := engine.Check(Transaction{To: bob, Value: ether(1)}); err != nil {
+		t.Errorf("Перевод в пределах лимита отклонен: %v", err)
+	}
+
+	expectViolation(t, engine.Check(Transaction{To: bob, Value: ether(2)}), RuleMaxPerTx)
+
+	data := tokenTransfer(t, bob, big.NewInt(100_000001))
+	expectViolation(t, engine.Check(Transaction{To: token, Value: new(big.Int), Data: data}), RuleMaxPerTx)
+}
+
+func TestCheckDailyLimit(t *testing.T) {
+	engine := newTestEngine(t, Policy{Assets: map[string]AssetLimits{
+		"ETH": {DailyLimit: "3"},
+	}})
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		tx := Transaction{Hash: common.BigToHash(big.NewInt(int64(i + 1))), To: bob, Value: ether(1)}
+		if err := engine.Check(tx); err != nil {
+			t.Fatalf("Перевод %d отклонен: %v", i, err)
+		}
+		if err := engine.Record(tx); err != nil {
+			t.Fatalf("Ошибка записи расхода: %v", err)
+		}
+	}
+
+	// Повторная запись той же транзакции не должна учитываться дважды
+	if err := engine.Record(Transaction{Hash: common.BigToHash(big.NewInt(2)), To: bob, Value: ether(1)}); err != nil {
+		t.Fatalf("Ошибка записи расхода: %v", err)
+	}
+
+	expectViolation(t, engine.Check(Transaction{To: bob, Value: ether(2)}), RuleDailyLimit)
+
+	if err := engine.Check(Transaction{To: bob, Value: ether(1)}); err != nil {
+		t.Errorf("Перевод в пределах дневного лимита отклонен: %v", err)
+	}
+
+	now = now.Add(Window + time.Minute)
+	if err := engine.Check(Transaction{To: bob, Value: ether(3)}); err != nil {
+		t.Errorf("Лимит должен был обновиться через 24 часа: %v", err)
+	}
+}
+
+func TestCheckRecipients(t *testing.T) {
+	engine := newTestEngine(t, Policy{AllowedRecipients: []string{alice.Hex()}})
+
+	if err := engine.Check(Transaction{To: alice, Value: ether(1)}); err != nil {
+		t.Errorf("Разрешенный получатель отклонен: %v", err)
+	}
+
+	expectViolation(t, engine.Check(Transaction{To: bob, Value: ether(1)}), RuleAllowedRecipients)
+
+	data := tokenTransfer(t, bob, big.NewInt(1))
+	expectViolation(t, engine.Check(Transaction{To: token, Value: new(big.Int), Data: data}), RuleAllowedRecipients)
+
+	engine = newTestEngine(t, Policy{DeniedRecipients: []string{bob.Hex()}})
+	expectViolation(t, engine.Check(Transaction{To: bob, Value: ether(1)}), RuleDeniedRecipients)
+
+	data = tokenTransfer(t, bob, big.NewInt(1))
+	expectViolation(t, engine.Check(Transaction{To: token, Value: new(big.Int), Data: data}), RuleDeniedRecipients)
+}
+
+func TestCheckGasPriceAndMethods(t *testing.T) {
+	engine := newTestEngine(t, Policy{
+		MaxGasPriceGwei: "50",
+		AllowedMethods:  map[string][]string{token.Hex(): {"transfer(address,uint256)"}},
+	})
+
+	expectViolation(t, engine.Check(Transaction{To: bob, Value: ether(1), GasPrice: big.NewInt(51e9)}), RuleMaxGasPrice)
+
+	if err := engine.Check(Transaction{To: bob, Value: ether(1), GasPrice: big.NewInt(50e9)}); err != nil {
+		t.Errorf("Обычный перевод отклонен: %v", err)
+	}
+
+	data := tokenTransfer(t, bob, big.NewInt(1))
+	if err := engine.Check(Transaction{To: token, Value: new(big.Int), Data: data}); err != nil {
+		t.Errorf("Разрешенный метод отклонен: %v", err)
+	}
+
+	approve := append(common.FromHex("0x095ea7b3"), make([]byte, 64)...)
+	expectViolation(t, engine.Check(Transaction{To: token, Value: new(big.Int), Data: approve}), RuleAllowedMethods)
+	expectViolation(t, engine.Check(Transaction{To: bob, Value: new(big.Int), Data: data}), RuleAllowedMethods)
+}
@@ -0,0 +1,212 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/crypto"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethereumCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+const NativeAsset = "ETH"
+
+const (
+	RuleMaxPerTx          = "max_per_tx"
+	RuleDailyLimit        = "daily_limit"
+	RuleAllowedRecipients = "allowed_recipients"
+	RuleDeniedRecipients  = "denied_recipients"
+	RuleMaxGasPrice       = "max_gas_price"
+	RuleAllowedMethods    = "allowed_methods"
+)
+
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("policy violation (%s): %s", v.Rule, v.Message)
+}
+
+type AssetLimits struct {
+	Decimals   *int   `json:"decimals,omitempty"`
+	MaxPerTx   string `json:"max_per_tx,omitempty"`
+	DailyLimit string `json:"daily_limit,omitempty"`
+}
+
+type Policy struct {
+	Assets            map[string]AssetLimits `json:"assets,omitempty"`
+	AllowedRecipients []string               `json:"allowed_recipients,omitempty"`
+	DeniedRecipients  []string               `json:"denied_recipients,omitempty"`
+	MaxGasPriceGwei   string                 `json:"max_gas_price_gwei,omitempty"`
+	AllowedMethods    map[string][]string    `json:"allowed_methods,omitempty"`
+}
+
+type limits struct {
+	decimals   int
+	maxPerTx   *big.Int
+	dailyLimit *big.Int
+}
+
+type rules struct {
+	assets      map[string]limits
+	allowed     map[common.Address]bool
+	denied      map[common.Address]bool
+	maxGasPrice *big.Int
+	methods     map[common.Address]map[[4]byte]bool
+}
+
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading policy file: %w", err)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("error parsing policy file: %w", err)
+	}
+
+	return &policy, nil
+}
+
+func (p *Policy) compile() (*rules, error) {
+	r := &rules{
+		assets:  make(map[string]limits),
+		allowed: make(map[common.Address]bool),
+		denied:  make(map[common.Address]bool),
+		methods: make(map[common.Address]map[[4]byte]bool),
+	}
+
+	for asset, spec := range p.Assets {
+		key, err := assetKey(asset)
+		if err != nil {
+			return nil, err
+		}
+
+		decimals := 18
+		if spec.Decimals != nil {
+			decimals = *spec.Decimals
+		} else if key != NativeAsset {
+			return nil, fmt.Errorf("policy for token %s must set decimals", asset)
+		}
+
+		l := limits{decimals: decimals}
+		if spec.MaxPerTx != "" {
+			if l.maxPerTx, err = crypto.ParseUnits(spec.MaxPerTx, decimals); err != nil {
+				return nil, fmt.Errorf("invalid max_per_tx for %s: %w", asset, err)
+			}
+		}
+		if spec.DailyLimit != "" {
+			if l.dailyLimit, err = crypto.ParseUnits(spec.DailyLimit, decimals); err != nil {
+				return nil, fmt.Errorf("invalid daily_limit for %s: %w", asset, err)
+			}
+		}
+
+		r.assets[key] = l
+	}
+
+	for _, list := range []struct {
+		entries []string
+		target  map[common.Address]bool
+	}{{p.AllowedRecipients, r.allowed}, {p.DeniedRecipients, r.denied}} {
+		for _, entry := range list.entries {
+			address, err := crypto.HexToAddress(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid policy address: %w", err)
+			}
+			list.target[address] = true
+		}
+	}
+
+	if p.MaxGasPriceGwei != "" {
+		maxGasPrice, err := crypto.ParseUnits(p.MaxGasPriceGwei, 9)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_gas_price_gwei: %w", err)
+		}
+		r.maxGasPrice = maxGasPrice
+	}
+
+	for contract, methods := range p.AllowedMethods {
+		address, err := crypto.HexToAddress(contract)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy contract: %w", err)
+		}
+
+		selectors := make(map[[4]byte]bool)
+		for _, method := range methods {
+			selector, err := parseSelector(method)
+			if err != nil {
+				return nil, err
+			}
+			selectors[selector] = true
+		}
+		r.methods[address] = selectors
+	}
+
+	return r, nil
+}
+
+func assetKey(asset string) (string, error) {
+	if strings.EqualFold(asset, NativeAsset) {
+		return NativeAsset, nil
+	}
+
+	address, err := crypto.HexToAddress(asset)
+	if err != nil {
+		return "", fmt.Errorf("invalid policy asset %q", asset)
+	}
+
+	return address.Hex(), nil
+}
+
+func parseSelector(method string) ([4]byte, error) {
+	var selector [4]byte
+
+	if strings.HasPrefix(method, "0x") {
+		decoded, err := hexutil.Decode(method)
+		if err != nil || len(decoded) != 4 {
+			return selector, fmt.Errorf("invalid method selector %q", method)
+		}
+		copy(selector[:], decoded)
+		return selector, nil
+	}
+
+	if !strings.Contains(method, "(") || !strings.HasSuffix(method, ")") {
+		return selector, fmt.Errorf("invalid method signature %q", method)
+	}
+
+	copy(selector[:], ethereumCrypto.Keccak256([]byte(strings.ReplaceAll(method, " ", "")))[:4])
+	return selector, nil
+}
+
+type movement struct {
+	asset     string
+	recipient common.Address
+	amount    *big.Int
+}
+
+func movements(tx Transaction) []movement {
+	var moves []movement
+
+	if tx.Value != nil && tx.Value.Sign() > 0 {
+		moves = append(moves, movement{asset: NativeAsset, recipient: tx.To, amount: tx.Value})
+	}
+
+	if recipient, amount, ok := blockchain.DecodeTokenTransferCall(tx.Data); ok {
+		moves = append(moves, movement{asset: tx.To.Hex(), recipient: recipient, amount: amount})
+	}
+
+	if len(moves) == 0 {
+		moves = append(moves, movement{asset: NativeAsset, recipient: tx.To, amount: new(big.Int)})
+	}
+
+	return moves
+}
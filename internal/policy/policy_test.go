@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	data := `{
+		"assets": {"eth": {"max_per_tx": "0.5", "daily_limit": "2"}},
+		"allowed_recipients": ["0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6"],
+		"max_gas_price_gwei": "50"
+	}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("Ошибка записи файла: %v", err)
+	}
+
+	p, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatalf("Ошибка загрузки политики: %v", err)
+	}
+
+	compiled, err := p.compile()
+	if err != nil {
+		t.Fatalf("Ошибка разбора политики: %v", err)
+	}
+
+	limits, ok := compiled.assets[NativeAsset]
+	if !ok {
+		t.Fatal("Лимиты ETH не найдены")
+	}
+	if limits.maxPerTx.String() != "500000000000000000" {
+		t.Errorf("Неверный max_per_tx: %s", limits.maxPerTx)
+	}
+	if compiled.maxGasPrice.String() != "50000000000" {
+		t.Errorf("Неверный max_gas_price: %s", compiled.maxGasPrice)
+	}
+	if len(compiled.allowed) != 1 {
+		t.Errorf("Ожидался 1 разрешенный адрес, получено %d", len(compiled.allowed))
+	}
+}
+
+func TestCompileRejectsInvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+	}{
+		{"токен без decimals", Policy{Assets: map[string]AssetLimits{token.Hex(): {MaxPerTx: "1"}}}},
+		{"неверный актив", Policy{Assets: map[string]AssetLimits{"BTC": {MaxPerTx: "1"}}}},
+		{"неверная сумма", Policy{Assets: map[string]AssetLimits{"ETH": {MaxPerTx: "1e18"}}}},
+		{"неверный адрес", Policy{DeniedRecipients: []string{"0x123"}}},
+		{"неверный метод", Policy{AllowedMethods: map[string][]string{token.Hex(): {"transfer"}}}},
+		{"неверный селектор", Policy{AllowedMethods: map[string][]string{token.Hex(): {"0xa9059c"}}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.policy.compile(); err == nil {
+			t.Errorf("%s: ожидалась ошибка", tt.name)
+		}
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	fromSignature, err := parseSelector("transfer(address, uint256)")
+	if err != nil {
+		t.Fatalf("Ошибка разбора сигнатуры: %v", err)
+	}
+
+	fromHex, err := parseSelector("0xa9059cbb")
+	if err != nil {
+		t.Fatalf("Ошибка разбора селектора: %v", err)
+	}
+
+	if fromSignature != fromHex {
+		t.Errorf("Селекторы не совпадают: %x != %x", fromSignature, fromHex)
+	}
+}
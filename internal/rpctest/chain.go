@@ -0,0 +1,41 @@
+package rpctest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func Block(header *types.Header, txs []*types.Transaction) (map[string]interface{}, error) {
+	header = types.CopyHeader(header)
+	header.UncleHash = types.EmptyUncleHash
+	header.TxHash = types.EmptyTxsHash
+
+	encodedTxs := make([]interface{}, len(txs))
+	if len(txs) > 0 {
+		hashes := make([][]byte, len(txs))
+		for i, tx := range txs {
+			encodedTxs[i] = tx
+			hashes[i] = tx.Hash().Bytes()
+		}
+		header.TxHash = crypto.Keccak256Hash(hashes...)
+	}
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding header: %w", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("error encoding header: %w", err)
+	}
+
+	fields["hash"] = header.Hash()
+	fields["transactions"] = encodedTxs
+	fields["uncles"] = []string{}
+
+	return fields, nil
+}
@@ -0,0 +1,142 @@
+package rpctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+type Handler func(params []json.RawMessage) (interface{}, error)
+
+type Error struct {
+	Code    int
+	Message string
+	Data    string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	calls    map[string]int
+	requests int
+}
+
+type request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *errorObject    `json:"error,omitempty"`
+}
+
+type errorObject struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+func NewServer() *Server {
+	s := &Server{
+		handlers: make(map[string]Handler),
+		calls:    make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *Server) Handle(method string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = handler
+}
+
+func (s *Server) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests++
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if len(body) > 0 && body[0] == '[' {
+		var reqs []request
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resps := make([]response, len(reqs))
+		for i, req := range reqs {
+			resps[i] = s.dispatch(req)
+		}
+		json.NewEncoder(w).Encode(resps)
+		return
+	}
+
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(s.dispatch(req))
+}
+
+func (s *Server) dispatch(req request) response {
+	s.mu.Lock()
+	s.calls[req.Method]++
+	handler, ok := s.handlers[req.Method]
+	s.mu.Unlock()
+
+	resp := response{JSONRPC: "2.0", ID: req.ID}
+	if !ok {
+		resp.Error = &errorObject{Code: -32601, Message: fmt.Sprintf("the method %s does not exist/is not available", req.Method)}
+		return resp
+	}
+
+	result, err := handler(req.Params)
+	if err != nil {
+		if rpcErr, ok := err.(*Error); ok {
+			resp.Error = &errorObject{Code: rpcErr.Code, Message: rpcErr.Message, Data: rpcErr.Data}
+		} else {
+			resp.Error = &errorObject{Code: -32000, Message: err.Error()}
+		}
+		return resp
+	}
+
+	if result == nil {
+		resp.Result = json.RawMessage("null")
+	} else {
+		resp.Result = result
+	}
+	return resp
+}
@@ -0,0 +1,282 @@
+package safe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"crypto-wallet/internal/blockchain"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+const (
+	OperationCall         uint8 = 0
+	OperationDelegateCall uint8 = 1
+)
+
+var safeTxType = []apitypes.Type{
+	{Name: "to", Type: "address"},
+	{Name: "value", Type: "uint256"},
+	{Name: "data", Type: "bytes"},
+	{Name: "operation", Type: "uint8"},
+	{Name: "safeTxGas", Type: "uint256"},
+	{Name: "baseGas", Type: "uint256"},
+	{Name: "gasPrice", Type: "uint256"},
+	{Name: "gasToken", Type: "address"},
+	{Name: "refundReceiver", Type: "address"},
+	{Name: "nonce", Type: "uint256"},
+}
+
+type Transaction struct {
+	Safe           common.Address `json:"safe"`
+	ChainID        *big.Int       `json:"chainId"`
+	Version        string         `json:"version"`
+	To             common.Address `json:"to"`
+	Value          *big.Int       `json:"value"`
+	Data           hexutil.Bytes  `json:"data"`
+	Operation      uint8          `json:"operation"`
+	SafeTxGas      *big.Int       `json:"safeTxGas"`
+	BaseGas        *big.Int       `json:"baseGas"`
+	GasPrice       *big.Int       `json:"gasPrice"`
+	GasToken       common.Address `json:"gasToken"`
+	RefundReceiver common.Address `json:"refundReceiver"`
+	Nonce          *big.Int       `json:"nonce"`
+}
+
+func NewTransaction(info *blockchain.SafeInfo, chainID *big.Int, to common.Address, value *big.Int, data []byte) *Transaction {
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	return &Transaction{
+		Safe:      info.Address,
+		ChainID:   chainID,
+		Version:   info.Version,
+		To:        to,
+		Value:     value,
+		Data:      data,
+		Operation: OperationCall,
+		SafeTxGas: new(big.Int),
+		BaseGas:   new(big.Int),
+		GasPrice:  new(big.Int),
+		Nonce:     info.Nonce,
+	}
+}
+
+func (tx *Transaction) TypedData() apitypes.TypedData {
+	domainType := []apitypes.Type{{Name: "chainId", Type: "uint256"}, {Name: "verifyingContract", Type: "address"}}
+	domain := apitypes.TypedDataDomain{ChainId: (*math.HexOrDecimal256)(tx.ChainID), VerifyingContract: tx.Safe.Hex()}
+
+	if !versionAtLeast(tx.Version, 1, 3) {
+		domainType = domainType[1:]
+		domain.ChainId = nil
+	}
+
+	data := tx.Data
+	if data == nil {
+		data = hexutil.Bytes{}
+	}
+
+	return apitypes.TypedData{
+		Types:       apitypes.Types{"EIP712Domain": domainType, "SafeTx": safeTxType},
+		PrimaryType: "SafeTx",
+		Domain:      domain,
+		Message: apitypes.TypedDataMessage{
+			"to":             tx.To.Hex(),
+			"value":          tx.Value.String(),
+			"data":           data.String(),
+			"operation":      strconv.Itoa(int(tx.Operation)),
+			"safeTxGas":      tx.SafeTxGas.String(),
+			"baseGas":        tx.BaseGas.String(),
+			"gasPrice":       tx.GasPrice.String(),
+			"gasToken":       tx.GasToken.Hex(),
+			"refundReceiver": tx.RefundReceiver.Hex(),
+			"nonce":          tx.Nonce.String(),
+		},
+	}
+}
+
+func (tx *Transaction) Hash() (common.Hash, error) {
+	hash, _, err := apitypes.TypedDataAndHash(tx.TypedData())
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error hashing Safe transaction: %w", err)
+	}
+	return common.BytesToHash(hash), nil
+}
+
+func versionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(strings.TrimSpace(version), ".", 3)
+	if len(parts) < 2 {
+		return true
+	}
+
+	gotMajor, err1 := strconv.Atoi(parts[0])
+	gotMinor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return true
+	}
+
+	return gotMajor > major || (gotMajor == major && gotMinor >= minor)
+}
+
+type Signature struct {
+	Signer    common.Address `json:"signer"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+type Proposal struct {
+	Transaction
+	Hash       common.Hash `json:"safeTxHash"`
+	Signatures []Signature `json:"signatures"`
+}
+
+func NewProposal(tx *Transaction) (*Proposal, error) {
+	hash, err := tx.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return &Proposal{Transaction: *tx, Hash: hash}, nil
+}
+
+func LoadProposal(path string) (*Proposal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Safe proposal: %w", err)
+	}
+
+	var proposal Proposal
+	if err := json.Unmarshal(data, &proposal); err != nil {
+		return nil, fmt.Errorf("error parsing Safe proposal %s: %w", path, err)
+	}
+	if proposal.ChainID == nil || proposal.Value == nil || proposal.Nonce == nil ||
+		proposal.SafeTxGas == nil || proposal.BaseGas == nil || proposal.GasPrice == nil {
+		return nil, fmt.Errorf("Safe proposal %s is incomplete", path)
+	}
+
+	hash, err := proposal.Transaction.Hash()
+	if err != nil {
+		return nil, err
+	}
+	if hash != proposal.Hash {
+		return nil, fmt.Errorf("Safe proposal %s: transaction hashes to %s, not %s", path, hash.Hex(), proposal.Hash.Hex())
+	}
+
+	for _, signature := range proposal.Signatures {
+		if err := verifySignature(hash, signature); err != nil {
+			return nil, fmt.Errorf("Safe proposal %s: %w", path, err)
+		}
+	}
+
+	return &proposal, nil
+}
+
+func (p *Proposal) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing Safe proposal: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error writing Safe proposal: %w", err)
+	}
+	return nil
+}
+
+func verifySignature(hash common.Hash, signature Signature) error {
+	sig := signature.Signature
+	if len(sig) != 65 || (sig[64] != 27 && sig[64] != 28) {
+		return fmt.Errorf("invalid signature from %s", signature.Signer.Hex())
+	}
+
+	recoverable := make([]byte, 65)
+	copy(recoverable, sig)
+	recoverable[64] -= 27
+
+	public, err := crypto.SigToPub(hash[:], recoverable)
+	if err != nil || crypto.PubkeyToAddress(*public) != signature.Signer {
+		return fmt.Errorf("signature does not match signer %s", signature.Signer.Hex())
+	}
+
+	return nil
+}
+
+func (p *Proposal) AddSignature(signature Signature) (bool, error) {
+	if err := verifySignature(p.Hash, signature); err != nil {
+		return false, err
+	}
+
+	for _, existing := range p.Signatures {
+		if existing.Signer == signature.Signer {
+			return false, nil
+		}
+	}
+
+	p.Signatures = append(p.Signatures, signature)
+	return true, nil
+}
+
+func (p *Proposal) Merge(other *Proposal) (int, error) {
+	if other.Hash != p.Hash {
+		return 0, fmt.Errorf("proposal %s is for a different transaction than %s", other.Hash.Hex(), p.Hash.Hex())
+	}
+
+	added := 0
+	for _, signature := range other.Signatures {
+		ok, err := p.AddSignature(signature)
+		if err != nil {
+			return added, err
+		}
+		if ok {
+			added++
+		}
+	}
+	return added, nil
+}
+
+func (p *Proposal) EncodeSignatures(info *blockchain.SafeInfo) ([]byte, error) {
+	var valid []Signature
+	for _, signature := range p.Signatures {
+		if info.IsOwner(signature.Signer) {
+			valid = append(valid, signature)
+		}
+	}
+
+	if uint64(len(valid)) < info.Threshold {
+		return nil, fmt.Errorf("Safe needs %d owner signatures, have %d", info.Threshold, len(valid))
+	}
+
+	sort.Slice(valid, func(i, j int) bool {
+		return bytes.Compare(valid[i].Signer.Bytes(), valid[j].Signer.Bytes()) < 0
+	})
+
+	encoded := make([]byte, 0, len(valid)*65)
+	for _, signature := range valid {
+		encoded = append(encoded, signature.Signature...)
+	}
+	return encoded, nil
+}
+
+func (p *Proposal) Exec(signatures []byte) blockchain.SafeExec {
+	return blockchain.SafeExec{
+		To:             p.To,
+		Value:          p.Value,
+		Data:           p.Data,
+		Operation:      p.Operation,
+		SafeTxGas:      p.SafeTxGas,
+		BaseGas:        p.BaseGas,
+		GasPrice:       p.GasPrice,
+		GasToken:       p.GasToken,
+		RefundReceiver: p.RefundReceiver,
+		Signatures:     signatures,
+	}
+}
@@ -0,0 +1,185 @@
+package safe
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"crypto-wallet/internal/blockchain"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	safeAddress = common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	recipient   = common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+
+	safeTxTypeHash       = common.HexToHash("0xbb8310d486368db6bd6f849402fdd73ad53d316b5a4b2644ad6efe0f941286d8")
+	domainTypeHash       = common.HexToHash("0x47e79534a245952e8b16893a336b85a3d9ea9fa8c573f3d803afb92a79469218")
+	legacyDomainTypeHash = common.HexToHash("0x035aff83d86937d35b32e04f0ddc6ff469290eef2f1b692d8a815c89404d4749")
+)
+
+func word(value *big.Int) []byte {
+	return common.LeftPadBytes(value.Bytes(), 32)
+}
+
+func manualHash(tx *Transaction, legacy bool) common.Hash {
+	var domain []byte
+	if legacy {
+		domain = crypto.Keccak256(legacyDomainTypeHash[:], common.LeftPadBytes(tx.Safe.Bytes(), 32))
+	} else {
+		domain = crypto.Keccak256(domainTypeHash[:], word(tx.ChainID), common.LeftPadBytes(tx.Safe.Bytes(), 32))
+	}
+
+	message := crypto.Keccak256(
+		safeTxTypeHash[:],
+		common.LeftPadBytes(tx.To.Bytes(), 32),
+		word(tx.Value),
+		crypto.Keccak256(tx.Data),
+		word(big.NewInt(int64(tx.Operation))),
+		word(tx.SafeTxGas),
+		word(tx.BaseGas),
+		word(tx.GasPrice),
+		common.LeftPadBytes(tx.GasToken.Bytes(), 32),
+		common.LeftPadBytes(tx.RefundReceiver.Bytes(), 32),
+		word(tx.Nonce),
+	)
+
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain, message)
+}
+
+func testTransaction(version string) *Transaction {
+	info := &blockchain.SafeInfo{Address: safeAddress, Version: version, Nonce: big.NewInt(7)}
+	return NewTransaction(info, big.NewInt(11155111), recipient, big.NewInt(1e15), hexutil.MustDecode("0xa9059cbb"))
+}
+
+func TestTransactionHash(t *testing.T) {
+	for _, version := range []string{"1.3.0", "1.4.1", "1.1.1"} {
+		tx := testTransaction(version)
+
+		hash, err := tx.Hash()
+		if err != nil {
+			t.Fatalf("Ошибка вычисления хеша для версии %s: %v", version, err)
+		}
+
+		expected := manualHash(tx, version == "1.1.1")
+		if hash != expected {
+			t.Errorf("Хеш SafeTx для версии %s: %s, ожидалось %s", version, hash.Hex(), expected.Hex())
+		}
+	}
+}
+
+func signProposal(t *testing.T, proposal *Proposal, key *ecdsa.PrivateKey) Signature {
+	t.Helper()
+
+	sig, err := crypto.Sign(proposal.Hash[:], key)
+	if err != nil {
+		t.Fatalf("Ошибка подписи: %v", err)
+	}
+	sig[64] += 27
+
+	return Signature{Signer: crypto.PubkeyToAddress(key.PublicKey), Signature: sig}
+}
+
+func TestProposalSignatures(t *testing.T) {
+	proposal, err := NewProposal(testTransaction("1.3.0"))
+	if err != nil {
+		t.Fatalf("Ошибка создания предложения: %v", err)
+	}
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	owners := make([]common.Address, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		owners[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	info := &blockchain.SafeInfo{Address: safeAddress, Threshold: 2, Owners: owners}
+
+	if ok, err := proposal.AddSignature(signProposal(t, proposal, keys[0])); !ok || err != nil {
+		t.Fatalf("Ошибка добавления подписи: %v", err)
+	}
+	if ok, _ := proposal.AddSignature(signProposal(t, proposal, keys[0])); ok {
+		t.Error("Повторная подпись владельца не должна добавляться")
+	}
+
+	forged := signProposal(t, proposal, keys[1])
+	forged.Signer = owners[2]
+	if _, err := proposal.AddSignature(forged); err == nil {
+		t.Error("Подпись с чужим адресом должна отклоняться")
+	}
+
+	if _, err := proposal.EncodeSignatures(info); err == nil {
+		t.Error("Одной подписи недостаточно при пороге 2")
+	}
+
+	// Подпись второго владельца приходит из отдельного файла
+	path := filepath.Join(t.TempDir(), "bob.json")
+	other := *proposal
+	other.Signatures = []Signature{signProposal(t, proposal, keys[1])}
+	if err := other.Save(path); err != nil {
+		t.Fatalf("Ошибка сохранения предложения: %v", err)
+	}
+
+	loaded, err := LoadProposal(path)
+	if err != nil {
+		t.Fatalf("Ошибка загрузки предложения: %v", err)
+	}
+	if added, err := proposal.Merge(loaded); added != 1 || err != nil {
+		t.Fatalf("Ожидалась одна новая подпись, получено %d, %v", added, err)
+	}
+
+	encoded, err := proposal.EncodeSignatures(info)
+	if err != nil {
+		t.Fatalf("Ошибка кодирования подписей: %v", err)
+	}
+	if len(encoded) != 130 {
+		t.Fatalf("Неверная длина подписей: %d", len(encoded))
+	}
+
+	first, second := owners[0], owners[1]
+	if bytes.Compare(first.Bytes(), second.Bytes()) > 0 {
+		first, second = second, first
+	}
+	for i, signer := range []common.Address{first, second} {
+		chunk := make([]byte, 65)
+		copy(chunk, encoded[i*65:(i+1)*65])
+		chunk[64] -= 27
+		public, err := crypto.SigToPub(proposal.Hash[:], chunk)
+		if err != nil || crypto.PubkeyToAddress(*public) != signer {
+			t.Errorf("Подписи должны быть упорядочены по адресу владельца")
+		}
+	}
+}
+
+func TestLoadProposalRejectsTampering(t *testing.T) {
+	proposal, err := NewProposal(testTransaction("1.3.0"))
+	if err != nil {
+		t.Fatalf("Ошибка создания предложения: %v", err)
+	}
+	proposal.Value = big.NewInt(1e18)
+
+	path := filepath.Join(t.TempDir(), "proposal.json")
+	if err := proposal.Save(path); err != nil {
+		t.Fatalf("Ошибка сохранения предложения: %v", err)
+	}
+
+	if _, err := LoadProposal(path); err == nil {
+		t.Error("Изменённое предложение должно отклоняться")
+	}
+}
+
+func TestMergeDifferentTransaction(t *testing.T) {
+	a, _ := NewProposal(testTransaction("1.3.0"))
+
+	tx := testTransaction("1.3.0")
+	tx.Nonce = big.NewInt(8)
+	b, _ := NewProposal(tx)
+
+	if _, err := a.Merge(b); err == nil {
+		t.Error("Подписи другой транзакции не должны объединяться")
+	}
+}
@@ -0,0 +1,158 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+type Share struct {
+	Index byte
+	Value []byte
+}
+
+var (
+	expTable [510]byte
+	logTable [256]byte
+)
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		expTable[i] = x
+		expTable[i+255] = x
+		logTable[x] = byte(i)
+		x = mulNoTable(x, 3)
+	}
+}
+
+func mulNoTable(a, b byte) byte {
+	var product byte
+	for b > 0 {
+		if b&1 != 0 {
+			product ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return product
+}
+
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+func div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
+
+func Split(secret []byte, threshold, shares int) ([]Share, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("secret must not be empty")
+	}
+	if threshold < 2 {
+		return nil, fmt.Errorf("threshold must be at least 2")
+	}
+	if shares < threshold {
+		return nil, fmt.Errorf("number of shares must be at least the threshold")
+	}
+	if shares > 255 {
+		return nil, fmt.Errorf("at most 255 shares are supported")
+	}
+
+	result := make([]Share, shares)
+	for i := range result {
+		result[i] = Share{Index: byte(i + 1), Value: make([]byte, len(secret))}
+	}
+
+	coefficients := make([]byte, threshold)
+	for pos, b := range secret {
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, fmt.Errorf("error generating random coefficients: %w", err)
+		}
+		coefficients[0] = b
+
+		for i := range result {
+			result[i].Value[pos] = evaluate(coefficients, result[i].Index)
+		}
+	}
+
+	for i := range coefficients {
+		coefficients[i] = 0
+	}
+
+	return result, nil
+}
+
+func evaluate(coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = mul(y, x) ^ coefficients[i]
+	}
+	return y
+}
+
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, fmt.Errorf("at least 2 shares are required")
+	}
+
+	for _, share := range shares {
+		if share.Index == 0 {
+			return nil, fmt.Errorf("invalid share index 0")
+		}
+	}
+
+	return Interpolate(shares, 0)
+}
+
+func Interpolate(shares []Share, x byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no shares given")
+	}
+
+	length := len(shares[0].Value)
+	seen := make(map[byte]bool)
+	for _, share := range shares {
+		if seen[share.Index] {
+			return nil, fmt.Errorf("duplicate share index %d", share.Index)
+		}
+		if len(share.Value) != length {
+			return nil, fmt.Errorf("shares have different lengths")
+		}
+		seen[share.Index] = true
+	}
+
+	result := make([]byte, length)
+	for _, share := range shares {
+		if share.Index == x {
+			copy(result, share.Value)
+			return result, nil
+		}
+	}
+
+	for i, share := range shares {
+		basis := byte(1)
+		for j, other := range shares {
+			if i == j {
+				continue
+			}
+			basis = mul(basis, div(x^other.Index, other.Index^share.Index))
+		}
+
+		for pos := range result {
+			result[pos] ^= mul(share.Value[pos], basis)
+		}
+	}
+
+	return result, nil
+}
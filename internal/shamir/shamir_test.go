@@ -0,0 +1,84 @@
+package shamir
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFieldArithmetic(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			product := mul(byte(a), byte(b))
+			if product != mulNoTable(byte(a), byte(b)) {
+				t.Fatalf("Неверное умножение %d*%d", a, b)
+			}
+			if div(product, byte(b)) != byte(a) {
+				t.Fatalf("Неверное деление %d/%d", product, b)
+			}
+		}
+	}
+}
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	shares, err := Split(secret, 3, 5)
+	if err != nil {
+		t.Fatalf("Ошибка разделения секрета: %v", err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("Ожидалось 5 долей, получено %d", len(shares))
+	}
+
+	// Любые 3 доли восстанавливают секрет
+	subsets := [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}}
+	for _, subset := range subsets {
+		var selected []Share
+		for _, i := range subset {
+			selected = append(selected, shares[i])
+		}
+
+		recovered, err := Combine(selected)
+		if err != nil {
+			t.Fatalf("Ошибка восстановления секрета: %v", err)
+		}
+		if !bytes.Equal(recovered, secret) {
+			t.Errorf("Секрет не совпадает для долей %v", subset)
+		}
+	}
+
+	// Двух долей недостаточно
+	recovered, err := Combine(shares[:2])
+	if err != nil {
+		t.Fatalf("Ошибка восстановления секрета: %v", err)
+	}
+	if bytes.Equal(recovered, secret) {
+		t.Error("Две доли не должны восстанавливать секрет")
+	}
+}
+
+func TestSplitCombineInvalid(t *testing.T) {
+	secret := []byte{1, 2, 3}
+
+	if _, err := Split(secret, 1, 3); err == nil {
+		t.Error("Должна быть ошибка для порога 1")
+	}
+	if _, err := Split(secret, 4, 3); err == nil {
+		t.Error("Должна быть ошибка, если порог больше числа долей")
+	}
+	if _, err := Split(nil, 2, 3); err == nil {
+		t.Error("Должна быть ошибка для пустого секрета")
+	}
+
+	shares, err := Split(secret, 2, 3)
+	if err != nil {
+		t.Fatalf("Ошибка разделения секрета: %v", err)
+	}
+
+	if _, err := Combine([]Share{shares[0], shares[0]}); err == nil {
+		t.Error("Должна быть ошибка для повторяющихся долей")
+	}
+	if _, err := Combine([]Share{shares[0], {Index: 2, Value: []byte{1}}}); err == nil {
+		t.Error("Должна быть ошибка для долей разной длины")
+	}
+}
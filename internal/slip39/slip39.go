@@ -0,0 +1,376 @@
+package slip39
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"crypto-wallet/internal/shamir"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	radixBits       = 10
+	radix           = 1 << radixBits
+	headerWords     = 4
+	checksumWords   = 3
+	minValueWords   = 13
+	minSecretLength = 16
+	maxShareCount   = 16
+
+	baseIterations = 10000
+	roundCount     = 4
+
+	digestLength = 4
+	digestIndex  = 254
+	secretIndex  = 255
+
+	DefaultIterationExponent = 1
+)
+
+var (
+	wordIndex = make(map[string]int, radix)
+
+	checksumGenerator = [10]uint32{
+		0xE0E040, 0x1C1C080, 0x3838100, 0x7070200, 0xE0E0009,
+		0x1C0C2412, 0x38086C24, 0x3090FC48, 0x21B1F890, 0x3F3F120,
+	}
+)
+
+func init() {
+	for i, word := range wordlist {
+		wordIndex[word] = i
+	}
+}
+
+type Share struct {
+	ID                uint16
+	Extendable        bool
+	IterationExponent byte
+	GroupIndex        byte
+	GroupThreshold    byte
+	GroupCount        byte
+	MemberIndex       byte
+	MemberThreshold   byte
+	Value             []byte
+}
+
+func Split(secret []byte, passphrase []byte, threshold, count int, iterationExponent byte) ([]Share, error) {
+	if len(secret) < minSecretLength || len(secret)%2 != 0 {
+		return nil, fmt.Errorf("secret must be an even number of bytes, at least %d", minSecretLength)
+	}
+	if threshold < 1 || threshold > count {
+		return nil, fmt.Errorf("threshold must be between 1 and the number of shares")
+	}
+	if count > maxShareCount {
+		return nil, fmt.Errorf("at most %d shares are supported", maxShareCount)
+	}
+	if threshold == 1 && count > 1 {
+		return nil, fmt.Errorf("a threshold of 1 allows only a single share")
+	}
+	if iterationExponent > 15 {
+		return nil, fmt.Errorf("iteration exponent must be at most 15")
+	}
+
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, fmt.Errorf("error generating share set ID: %w", err)
+	}
+
+	template := Share{
+		ID:                (uint16(id[0])<<8 | uint16(id[1])) & 0x7fff,
+		IterationExponent: iterationExponent,
+		GroupThreshold:    1,
+		GroupCount:        1,
+		MemberThreshold:   byte(threshold),
+	}
+
+	encrypted := template.crypt(secret, passphrase, false)
+	parts, err := splitSecret(threshold, count, encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]Share, len(parts))
+	for i, part := range parts {
+		shares[i] = template
+		shares[i].MemberIndex = part.Index
+		shares[i].Value = part.Value
+	}
+	return shares, nil
+}
+
+func Combine(shares []Share, passphrase []byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no shares given")
+	}
+
+	first := shares[0]
+	groups := make(map[byte][]Share)
+	for _, share := range shares {
+		if share.ID != first.ID || share.Extendable != first.Extendable || share.IterationExponent != first.IterationExponent ||
+			share.GroupThreshold != first.GroupThreshold || share.GroupCount != first.GroupCount || len(share.Value) != len(first.Value) {
+			return nil, fmt.Errorf("share %d of group %d belongs to a different backup", share.MemberIndex+1, share.GroupIndex+1)
+		}
+		groups[share.GroupIndex] = append(groups[share.GroupIndex], share)
+	}
+
+	var groupSecrets []shamir.Share
+	for index, members := range groups {
+		threshold := members[0].MemberThreshold
+		parts := make([]shamir.Share, len(members))
+		for i, member := range members {
+			if member.MemberThreshold != threshold {
+				return nil, fmt.Errorf("shares of group %d have different thresholds", index+1)
+			}
+			parts[i] = shamir.Share{Index: member.MemberIndex, Value: member.Value}
+		}
+		if len(parts) < int(threshold) {
+			continue
+		}
+
+		secret, err := recoverSecret(int(threshold), parts[:threshold])
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %w", index+1, err)
+		}
+		groupSecrets = append(groupSecrets, shamir.Share{Index: index, Value: secret})
+	}
+
+	if len(groupSecrets) < int(first.GroupThreshold) {
+		return nil, fmt.Errorf("%d complete groups given, %d required", len(groupSecrets), first.GroupThreshold)
+	}
+
+	encrypted, err := recoverSecret(int(first.GroupThreshold), groupSecrets[:first.GroupThreshold])
+	if err != nil {
+		return nil, err
+	}
+
+	return first.crypt(encrypted, passphrase, true), nil
+}
+
+func splitSecret(threshold, count int, secret []byte) ([]shamir.Share, error) {
+	shares := make([]shamir.Share, 0, count)
+	if threshold == 1 {
+		for i := 0; i < count; i++ {
+			shares = append(shares, shamir.Share{Index: byte(i), Value: append([]byte(nil), secret...)})
+		}
+		return shares, nil
+	}
+
+	for i := 0; i < threshold-2; i++ {
+		value := make([]byte, len(secret))
+		if _, err := rand.Read(value); err != nil {
+			return nil, fmt.Errorf("error generating random share: %w", err)
+		}
+		shares = append(shares, shamir.Share{Index: byte(i), Value: value})
+	}
+
+	random := make([]byte, len(secret)-digestLength)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("error generating random share: %w", err)
+	}
+	digest := append(secretDigest(random, secret), random...)
+
+	base := append(shares,
+		shamir.Share{Index: digestIndex, Value: digest},
+		shamir.Share{Index: secretIndex, Value: secret},
+	)
+	for i := threshold - 2; i < count; i++ {
+		value, err := shamir.Interpolate(base, byte(i))
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, shamir.Share{Index: byte(i), Value: value})
+	}
+
+	return shares, nil
+}
+
+func recoverSecret(threshold int, shares []shamir.Share) ([]byte, error) {
+	if threshold == 1 {
+		return shares[0].Value, nil
+	}
+
+	secret, err := shamir.Interpolate(shares, secretIndex)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := shamir.Interpolate(shares, digestIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare(digest[:digestLength], secretDigest(digest[digestLength:], secret)) != 1 {
+		return nil, fmt.Errorf("share digest mismatch, some shares are wrong")
+	}
+	return secret, nil
+}
+
+func secretDigest(key, secret []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(secret)
+	return mac.Sum(nil)[:digestLength]
+}
+
+func (s Share) crypt(secret []byte, passphrase []byte, decrypt bool) []byte {
+	half := len(secret) / 2
+	left := append([]byte(nil), secret[:half]...)
+	right := append([]byte(nil), secret[half:]...)
+
+	salt := []byte{}
+	if !s.Extendable {
+		salt = append([]byte("shamir"), byte(s.ID>>8), byte(s.ID))
+	}
+	iterations := (baseIterations << s.IterationExponent) / roundCount
+
+	for step := 0; step < roundCount; step++ {
+		round := step
+		if decrypt {
+			round = roundCount - 1 - step
+		}
+
+		password := append([]byte{byte(round)}, passphrase...)
+		key := pbkdf2.Key(password, append(salt, right...), iterations, half, sha256.New)
+		for i := range key {
+			key[i] ^= left[i]
+		}
+		left, right = right, key
+	}
+
+	return append(right, left...)
+}
+
+func (s Share) Mnemonic() string {
+	valueWords := (len(s.Value)*8 + radixBits - 1) / radixBits
+	indices := make([]int, 0, headerWords+valueWords+checksumWords)
+
+	ext := 0
+	if s.Extendable {
+		ext = 1
+	}
+	header := int(s.ID)<<5 | ext<<4 | int(s.IterationExponent)
+	params := int(s.GroupIndex)<<16 | int(s.GroupThreshold-1)<<12 | int(s.GroupCount-1)<<8 |
+		int(s.MemberIndex)<<4 | int(s.MemberThreshold-1)
+	indices = append(indices, header>>radixBits, header&(radix-1), params>>radixBits, params&(radix-1))
+
+	value := new(big.Int).SetBytes(s.Value)
+	words := make([]int, valueWords)
+	for i := valueWords - 1; i >= 0; i-- {
+		words[i] = int(new(big.Int).And(value, big.NewInt(radix-1)).Int64())
+		value.Rsh(value, radixBits)
+	}
+	indices = append(indices, words...)
+	indices = append(indices, createChecksum(customization(s.Extendable), indices)...)
+
+	mnemonic := make([]string, len(indices))
+	for i, index := range indices {
+		mnemonic[i] = wordlist[index]
+	}
+	return strings.Join(mnemonic, " ")
+}
+
+func ParseMnemonic(mnemonic string) (Share, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) < headerWords+minValueWords+checksumWords {
+		return Share{}, fmt.Errorf("a SLIP-39 share has at least %d words, got %d", headerWords+minValueWords+checksumWords, len(words))
+	}
+
+	indices := make([]int, len(words))
+	for i, word := range words {
+		index, ok := wordIndex[word]
+		if !ok {
+			return Share{}, fmt.Errorf("word %d (%q) is not in the SLIP-39 wordlist", i+1, word)
+		}
+		indices[i] = index
+	}
+
+	header := indices[0]<<radixBits | indices[1]
+	share := Share{
+		ID:                uint16(header >> 5),
+		Extendable:        header>>4&1 == 1,
+		IterationExponent: byte(header & 0xf),
+	}
+
+	if !verifyChecksum(customization(share.Extendable), indices) {
+		return Share{}, fmt.Errorf("share checksum mismatch, check for typos")
+	}
+
+	params := indices[2]<<radixBits | indices[3]
+	share.GroupIndex = byte(params >> 16)
+	share.GroupThreshold = byte(params>>12&0xf) + 1
+	share.GroupCount = byte(params>>8&0xf) + 1
+	share.MemberIndex = byte(params >> 4 & 0xf)
+	share.MemberThreshold = byte(params&0xf) + 1
+	if share.GroupThreshold > share.GroupCount {
+		return Share{}, fmt.Errorf("invalid share: group threshold %d exceeds group count %d", share.GroupThreshold, share.GroupCount)
+	}
+
+	valueWords := indices[headerWords : len(indices)-checksumWords]
+	padding := radixBits * len(valueWords) % 16
+	if padding > 8 {
+		return Share{}, fmt.Errorf("invalid share length of %d words", len(words))
+	}
+
+	value := new(big.Int)
+	for _, index := range valueWords {
+		value.Lsh(value, radixBits)
+		value.Or(value, big.NewInt(int64(index)))
+	}
+
+	length := (radixBits*len(valueWords) - padding) / 8
+	if value.BitLen() > length*8 {
+		return Share{}, fmt.Errorf("invalid share padding")
+	}
+	share.Value = value.FillBytes(make([]byte, length))
+
+	return share, nil
+}
+
+func customization(extendable bool) []byte {
+	if extendable {
+		return []byte("shamir_extendable")
+	}
+	return []byte("shamir")
+}
+
+func polymod(values []int) uint32 {
+	checksum := uint32(1)
+	for _, value := range values {
+		top := checksum >> 20
+		checksum = (checksum&0xfffff)<<radixBits ^ uint32(value)
+		for i, generator := range checksumGenerator {
+			if top>>i&1 == 1 {
+				checksum ^= generator
+			}
+		}
+	}
+	return checksum
+}
+
+func withCustomization(custom []byte, data []int) []int {
+	values := make([]int, 0, len(custom)+len(data)+checksumWords)
+	for _, c := range custom {
+		values = append(values, int(c))
+	}
+	return append(values, data...)
+}
+
+func createChecksum(custom []byte, data []int) []int {
+	values := append(withCustomization(custom, data), make([]int, checksumWords)...)
+	checksum := polymod(values) ^ 1
+
+	result := make([]int, checksumWords)
+	for i := range result {
+		result[i] = int(checksum >> (radixBits * (checksumWords - 1 - i)) & (radix - 1))
+	}
+	return result
+}
+
+func verifyChecksum(custom []byte, data []int) bool {
+	return polymod(withCustomization(custom, data)) == 1
+}
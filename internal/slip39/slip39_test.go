@@ -0,0 +1,152 @@
+package slip39
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func parseAll(t *testing.T, mnemonics ...string) []Share {
+	t.Helper()
+
+	shares := make([]Share, len(mnemonics))
+	for i, mnemonic := range mnemonics {
+		share, err := ParseMnemonic(mnemonic)
+		if err != nil {
+			t.Fatalf("Ошибка разбора доли %d: %v", i+1, err)
+		}
+		shares[i] = share
+	}
+	return shares
+}
+
+func TestWordlist(t *testing.T) {
+	if !sort.StringsAreSorted(wordlist[:]) {
+		t.Error("Список слов должен быть отсортирован")
+	}
+
+	prefixes := make(map[string]bool)
+	for _, word := range wordlist {
+		if prefixes[word[:4]] {
+			t.Errorf("Первые 4 буквы слова %q не уникальны", word)
+		}
+		prefixes[word[:4]] = true
+	}
+}
+
+func TestOfficialVectors(t *testing.T) {
+	// Тестовые векторы SLIP-0039, парольная фраза "TREZOR"
+	tests := []struct {
+		name      string
+		mnemonics []string
+		secret    string
+	}{
+		{
+			"одна доля, 128 бит",
+			[]string{"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard"},
+			"bb54aac4b89dc868ba37d9cc21b2cece",
+		},
+		{
+			"2 из 3, 128 бит",
+			[]string{
+				"shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed",
+				"shadow pistol academic acid actress prayer class unknown daughter sweater depict flip twice unkind craft early superior advocate guest smoking",
+			},
+			"b43ceb7e57a0ea8766221624d01b0864",
+		},
+	}
+
+	for _, tt := range tests {
+		secret, err := Combine(parseAll(t, tt.mnemonics...), []byte("TREZOR"))
+		if err != nil {
+			t.Fatalf("%s: ошибка восстановления: %v", tt.name, err)
+		}
+		if hex.EncodeToString(secret) != tt.secret {
+			t.Errorf("%s: секрет %x, ожидался %s", tt.name, secret, tt.secret)
+		}
+	}
+}
+
+func TestSplitCombine(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x5a, 0xc3}, 16)
+
+	shares, err := Split(secret, nil, 3, 5, 0)
+	if err != nil {
+		t.Fatalf("Ошибка разделения: %v", err)
+	}
+
+	mnemonics := make([]string, len(shares))
+	for i, share := range shares {
+		mnemonics[i] = share.Mnemonic()
+		if words := len(strings.Fields(mnemonics[i])); words != 33 {
+			t.Fatalf("Доля 256-битного секрета должна содержать 33 слова, получено %d", words)
+		}
+	}
+
+	// Любые 3 доли восстанавливают секрет
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}} {
+		var selected []string
+		for _, i := range subset {
+			selected = append(selected, mnemonics[i])
+		}
+
+		recovered, err := Combine(parseAll(t, selected...), nil)
+		if err != nil {
+			t.Fatalf("Ошибка восстановления %v: %v", subset, err)
+		}
+		if !bytes.Equal(recovered, secret) {
+			t.Errorf("Секрет не совпадает для долей %v", subset)
+		}
+	}
+
+	if _, err := Combine(parseAll(t, mnemonics[:2]...), nil); err == nil {
+		t.Error("Двух долей должно быть недостаточно")
+	}
+
+	// Неверная парольная фраза даёт другой секрет
+	recovered, err := Combine(parseAll(t, mnemonics[:3]...), []byte("wrong"))
+	if err != nil || bytes.Equal(recovered, secret) {
+		t.Error("Другая парольная фраза должна давать другой секрет")
+	}
+}
+
+func TestCombineDetectsWrongShare(t *testing.T) {
+	shares, err := Split(bytes.Repeat([]byte{7}, 16), nil, 2, 3, 0)
+	if err != nil {
+		t.Fatalf("Ошибка разделения: %v", err)
+	}
+
+	shares[1].Value[0] ^= 1
+	if _, err := Combine(shares[:2], nil); err == nil {
+		t.Error("Искажённая доля должна обнаруживаться по дайджесту")
+	}
+}
+
+func TestParseMnemonicErrors(t *testing.T) {
+	valid := "duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard"
+
+	tests := map[string]string{
+		"опечатка в слове":     strings.Replace(valid, "fridge", "fridgee", 1),
+		"переставленные слова": strings.Replace(valid, "kidney coal", "coal kidney", 1),
+		"слишком короткая":     "duckling enlarge academic academic agency",
+	}
+	for name, mnemonic := range tests {
+		if _, err := ParseMnemonic(mnemonic); err == nil {
+			t.Errorf("%s: ожидалась ошибка", name)
+		}
+	}
+}
+
+func TestSplitInvalid(t *testing.T) {
+	if _, err := Split(make([]byte, 15), nil, 2, 3, 0); err == nil {
+		t.Error("Секрет короче 16 байт должен отклоняться")
+	}
+	if _, err := Split(make([]byte, 32), nil, 2, 17, 0); err == nil {
+		t.Error("Больше 16 долей должно отклоняться")
+	}
+	if _, err := Split(make([]byte, 32), nil, 4, 3, 0); err == nil {
+		t.Error("Порог больше числа долей должен отклоняться")
+	}
+}
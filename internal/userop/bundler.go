@@ -0,0 +1,165 @@
+package userop
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const DefaultPollInterval = 2 * time.Second
+
+type Bundler struct {
+	client       *rpc.Client
+	pollInterval time.Duration
+}
+
+type GasEstimate struct {
+	PreVerificationGas            *hexutil.Big `json:"preVerificationGas"`
+	VerificationGasLimit          *hexutil.Big `json:"verificationGasLimit"`
+	CallGasLimit                  *hexutil.Big `json:"callGasLimit"`
+	PaymasterVerificationGasLimit *hexutil.Big `json:"paymasterVerificationGasLimit"`
+	PaymasterPostOpGasLimit       *hexutil.Big `json:"paymasterPostOpGasLimit"`
+}
+
+type Receipt struct {
+	UserOpHash    common.Hash    `json:"userOpHash"`
+	EntryPoint    common.Address `json:"entryPoint"`
+	Sender        common.Address `json:"sender"`
+	Nonce         *hexutil.Big   `json:"nonce"`
+	Paymaster     common.Address `json:"paymaster"`
+	ActualGasCost *hexutil.Big   `json:"actualGasCost"`
+	ActualGasUsed *hexutil.Big   `json:"actualGasUsed"`
+	Success       bool           `json:"success"`
+	Reason        string         `json:"reason"`
+	Receipt       struct {
+		TransactionHash common.Hash  `json:"transactionHash"`
+		BlockNumber     *hexutil.Big `json:"blockNumber"`
+	} `json:"receipt"`
+}
+
+func NewBundler(url string) (*Bundler, error) {
+	client, err := rpc.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to bundler: %w", err)
+	}
+	return &Bundler{client: client}, nil
+}
+
+func (b *Bundler) SetPollInterval(interval time.Duration) {
+	b.pollInterval = interval
+}
+
+func (b *Bundler) Close() {
+	b.client.Close()
+}
+
+func (b *Bundler) call(result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := b.client.CallContext(ctx, result, method, args...); err != nil {
+		return fmt.Errorf("bundler %s failed: %w", method, err)
+	}
+	return nil
+}
+
+func (b *Bundler) SupportedEntryPoints() ([]common.Address, error) {
+	var entryPoints []common.Address
+	if err := b.call(&entryPoints, "eth_supportedEntryPoints"); err != nil {
+		return nil, err
+	}
+	return entryPoints, nil
+}
+
+func (b *Bundler) CheckEntryPoint(entryPoint EntryPoint) error {
+	supported, err := b.SupportedEntryPoints()
+	if err != nil {
+		return err
+	}
+
+	for _, address := range supported {
+		if address == entryPoint.Address {
+			return nil
+		}
+	}
+	return fmt.Errorf("bundler does not support EntryPoint v%s at %s", entryPoint.Version, entryPoint.Address.Hex())
+}
+
+func (b *Bundler) EstimateGas(op *UserOperation, entryPoint EntryPoint) (*GasEstimate, error) {
+	fields, err := op.RPC(entryPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var estimate GasEstimate
+	if err := b.call(&estimate, "eth_estimateUserOperationGas", fields, entryPoint.Address); err != nil {
+		return nil, err
+	}
+	if estimate.PreVerificationGas == nil || estimate.VerificationGasLimit == nil || estimate.CallGasLimit == nil {
+		return nil, fmt.Errorf("bundler returned an incomplete gas estimate")
+	}
+	return &estimate, nil
+}
+
+func (e *GasEstimate) Apply(op *UserOperation) {
+	op.PreVerificationGas = new(big.Int).Set(e.PreVerificationGas.ToInt())
+	op.VerificationGasLimit = new(big.Int).Set(e.VerificationGasLimit.ToInt())
+	op.CallGasLimit = new(big.Int).Set(e.CallGasLimit.ToInt())
+
+	if op.Paymaster != nil && e.PaymasterVerificationGasLimit != nil {
+		op.PaymasterVerificationGasLimit = new(big.Int).Set(e.PaymasterVerificationGasLimit.ToInt())
+	}
+	if op.Paymaster != nil && e.PaymasterPostOpGasLimit != nil {
+		op.PaymasterPostOpGasLimit = new(big.Int).Set(e.PaymasterPostOpGasLimit.ToInt())
+	}
+}
+
+func (b *Bundler) Send(op *UserOperation, entryPoint EntryPoint) (common.Hash, error) {
+	fields, err := op.RPC(entryPoint)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	var hash common.Hash
+	if err := b.call(&hash, "eth_sendUserOperation", fields, entryPoint.Address); err != nil {
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
+
+func (b *Bundler) GetReceipt(hash common.Hash) (*Receipt, error) {
+	var receipt *Receipt
+	if err := b.call(&receipt, "eth_getUserOperationReceipt", hash); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+func (b *Bundler) WaitForReceipt(hash common.Hash, timeout time.Duration) (*Receipt, error) {
+	interval := b.pollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		receipt, err := b.GetReceipt(hash)
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil {
+			return receipt, nil
+		}
+
+		if !time.Now().Add(interval).Before(deadline) {
+			return nil, fmt.Errorf("UserOperation %s was not included within %s", hash.Hex(), timeout)
+		}
+
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,125 @@
+package userop
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func newTestBundler(t *testing.T, server *rpctest.Server) *Bundler {
+	bundler, err := NewBundler(server.URL)
+	if err != nil {
+		t.Fatalf("Ошибка подключения к бандлеру: %v", err)
+	}
+	t.Cleanup(bundler.Close)
+	bundler.SetPollInterval(10 * time.Millisecond)
+	return bundler
+}
+
+func TestBundlerFlow(t *testing.T) {
+	opHash := common.HexToHash("0xabc0000000000000000000000000000000000000000000000000000000000001")
+	txHash := common.HexToHash("0xdef0000000000000000000000000000000000000000000000000000000000002")
+
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	var estimated, sent map[string]interface{}
+	polls := 0
+
+	server.Handle("eth_supportedEntryPoints", func(params []json.RawMessage) (interface{}, error) {
+		return []common.Address{EntryPointV07.Address}, nil
+	})
+	server.Handle("eth_estimateUserOperationGas", func(params []json.RawMessage) (interface{}, error) {
+		json.Unmarshal(params[0], &estimated)
+		return map[string]string{
+			"preVerificationGas":   "0xc350",
+			"verificationGasLimit": "0x30d40",
+			"callGasLimit":         "0x186a0",
+		}, nil
+	})
+	server.Handle("eth_sendUserOperation", func(params []json.RawMessage) (interface{}, error) {
+		json.Unmarshal(params[0], &sent)
+		var entryPoint common.Address
+		json.Unmarshal(params[1], &entryPoint)
+		if entryPoint != EntryPointV07.Address {
+			return nil, &rpctest.Error{Code: -32602, Message: "unsupported entry point"}
+		}
+		return opHash, nil
+	})
+	// Первые опросы возвращают null, пока операция не включена в блок
+	server.Handle("eth_getUserOperationReceipt", func(params []json.RawMessage) (interface{}, error) {
+		polls++
+		if polls < 3 {
+			return nil, nil
+		}
+		return map[string]interface{}{
+			"userOpHash":    opHash,
+			"sender":        testSender,
+			"success":       true,
+			"actualGasCost": "0x5af3107a4000",
+			"receipt":       map[string]interface{}{"transactionHash": txHash, "blockNumber": "0x10"},
+		}, nil
+	})
+
+	bundler := newTestBundler(t, server)
+
+	if err := bundler.CheckEntryPoint(EntryPointV07); err != nil {
+		t.Fatalf("EntryPoint v0.7 должна поддерживаться: %v", err)
+	}
+	if err := bundler.CheckEntryPoint(EntryPointV06); err == nil {
+		t.Error("EntryPoint v0.6 не должна поддерживаться")
+	}
+
+	op := testOperation()
+	op.Signature = DummySignature
+
+	estimate, err := bundler.EstimateGas(op, EntryPointV07)
+	if err != nil {
+		t.Fatalf("Ошибка оценки газа: %v", err)
+	}
+	if estimated["signature"] != "0x"+common.Bytes2Hex(DummySignature) {
+		t.Error("Оценка газа должна выполняться с фиктивной подписью")
+	}
+
+	estimate.Apply(op)
+	if op.CallGasLimit.Int64() != 100000 || op.VerificationGasLimit.Int64() != 200000 || op.PreVerificationGas.Int64() != 50000 {
+		t.Errorf("Оценка газа применена неверно: %s %s %s", op.CallGasLimit, op.VerificationGasLimit, op.PreVerificationGas)
+	}
+	if op.PaymasterVerificationGasLimit.Int64() != 30000 {
+		t.Error("Отсутствующие в оценке лимиты paymaster не должны сбрасываться")
+	}
+
+	hash, err := bundler.Send(op, EntryPointV07)
+	if err != nil {
+		t.Fatalf("Ошибка отправки: %v", err)
+	}
+	if hash != opHash || sent["callGasLimit"] != "0x186a0" {
+		t.Errorf("Неверная отправка: %s, %v", hash.Hex(), sent)
+	}
+
+	receipt, err := bundler.WaitForReceipt(hash, time.Second)
+	if err != nil {
+		t.Fatalf("Ошибка ожидания квитанции: %v", err)
+	}
+	if !receipt.Success || receipt.Receipt.TransactionHash != txHash || receipt.ActualGasCost.ToInt().Int64() != 1e14 {
+		t.Errorf("Неверная квитанция: %+v", receipt)
+	}
+}
+
+func TestWaitForReceiptTimeout(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_getUserOperationReceipt", func(params []json.RawMessage) (interface{}, error) {
+		return nil, nil
+	})
+
+	bundler := newTestBundler(t, server)
+	if _, err := bundler.WaitForReceipt(common.Hash{1}, 50*time.Millisecond); err == nil {
+		t.Error("Ожидание должно завершаться ошибкой по таймауту")
+	}
+}
@@ -0,0 +1,249 @@
+package userop
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"crypto-wallet/internal/crypto"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethereumCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	Version06 = "0.6"
+	Version07 = "0.7"
+)
+
+type EntryPoint struct {
+	Address common.Address
+	Version string
+}
+
+var (
+	EntryPointV06 = EntryPoint{Address: common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789"), Version: Version06}
+	EntryPointV07 = EntryPoint{Address: common.HexToAddress("0x0000000071727De22E5E9d8BAf0edAc6f37da032"), Version: Version07}
+)
+
+var DummySignature = common.FromHex("0xfffffffffffffffffffffffffffffff0000000000000000000000000000000007aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa1c")
+
+func ParseEntryPoint(input string) (EntryPoint, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(input)), "v") {
+	case Version06, strings.ToLower(EntryPointV06.Address.Hex()):
+		return EntryPointV06, nil
+	case Version07, strings.ToLower(EntryPointV07.Address.Hex()):
+		return EntryPointV07, nil
+	}
+	return EntryPoint{}, fmt.Errorf("unknown EntryPoint %q: use 0.6, 0.7 or a canonical EntryPoint address", input)
+}
+
+type UserOperation struct {
+	Sender                        common.Address
+	Nonce                         *big.Int
+	Factory                       *common.Address
+	FactoryData                   []byte
+	CallData                      []byte
+	CallGasLimit                  *big.Int
+	VerificationGasLimit          *big.Int
+	PreVerificationGas            *big.Int
+	MaxFeePerGas                  *big.Int
+	MaxPriorityFeePerGas          *big.Int
+	Paymaster                     *common.Address
+	PaymasterVerificationGasLimit *big.Int
+	PaymasterPostOpGasLimit       *big.Int
+	PaymasterData                 []byte
+	Signature                     []byte
+}
+
+func bigOrZero(value *big.Int) *big.Int {
+	if value == nil {
+		return new(big.Int)
+	}
+	return value
+}
+
+func (op *UserOperation) InitCode() []byte {
+	if op.Factory == nil {
+		return nil
+	}
+	return append(op.Factory.Bytes(), op.FactoryData...)
+}
+
+func (op *UserOperation) PaymasterAndData(version string) ([]byte, error) {
+	if op.Paymaster == nil {
+		return nil, nil
+	}
+
+	data := op.Paymaster.Bytes()
+	if version == Version07 {
+		verification, err := uint128(op.PaymasterVerificationGasLimit, "paymasterVerificationGasLimit")
+		if err != nil {
+			return nil, err
+		}
+		postOp, err := uint128(op.PaymasterPostOpGasLimit, "paymasterPostOpGasLimit")
+		if err != nil {
+			return nil, err
+		}
+		data = append(append(data, verification...), postOp...)
+	}
+	return append(data, op.PaymasterData...), nil
+}
+
+func uint128(value *big.Int, name string) ([]byte, error) {
+	value = bigOrZero(value)
+	if value.Sign() < 0 || value.BitLen() > 128 {
+		return nil, fmt.Errorf("%s does not fit in 128 bits", name)
+	}
+	return value.FillBytes(make([]byte, 16)), nil
+}
+
+func packUint128s(high, low *big.Int, highName, lowName string) ([32]byte, error) {
+	var packed [32]byte
+
+	highBytes, err := uint128(high, highName)
+	if err != nil {
+		return packed, err
+	}
+	lowBytes, err := uint128(low, lowName)
+	if err != nil {
+		return packed, err
+	}
+
+	copy(packed[:16], highBytes)
+	copy(packed[16:], lowBytes)
+	return packed, nil
+}
+
+func mustType(name string) abi.Type {
+	typ, err := abi.NewType(name, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
+
+var (
+	addressType = mustType("address")
+	uint256Type = mustType("uint256")
+	bytes32Type = mustType("bytes32")
+
+	packV06 = abi.Arguments{
+		{Type: addressType}, {Type: uint256Type}, {Type: bytes32Type}, {Type: bytes32Type},
+		{Type: uint256Type}, {Type: uint256Type}, {Type: uint256Type}, {Type: uint256Type}, {Type: uint256Type},
+		{Type: bytes32Type},
+	}
+	packV07 = abi.Arguments{
+		{Type: addressType}, {Type: uint256Type}, {Type: bytes32Type}, {Type: bytes32Type},
+		{Type: bytes32Type}, {Type: uint256Type}, {Type: bytes32Type},
+		{Type: bytes32Type},
+	}
+	packHash = abi.Arguments{{Type: bytes32Type}, {Type: addressType}, {Type: uint256Type}}
+)
+
+func keccak(data []byte) [32]byte {
+	return ethereumCrypto.Keccak256Hash(data)
+}
+
+func (op *UserOperation) pack(version string) ([]byte, error) {
+	paymasterAndData, err := op.PaymasterAndData(version)
+	if err != nil {
+		return nil, err
+	}
+
+	switch version {
+	case Version06:
+		return packV06.Pack(
+			op.Sender, bigOrZero(op.Nonce), keccak(op.InitCode()), keccak(op.CallData),
+			bigOrZero(op.CallGasLimit), bigOrZero(op.VerificationGasLimit), bigOrZero(op.PreVerificationGas),
+			bigOrZero(op.MaxFeePerGas), bigOrZero(op.MaxPriorityFeePerGas),
+			keccak(paymasterAndData),
+		)
+	case Version07:
+		accountGasLimits, err := packUint128s(op.VerificationGasLimit, op.CallGasLimit, "verificationGasLimit", "callGasLimit")
+		if err != nil {
+			return nil, err
+		}
+		gasFees, err := packUint128s(op.MaxPriorityFeePerGas, op.MaxFeePerGas, "maxPriorityFeePerGas", "maxFeePerGas")
+		if err != nil {
+			return nil, err
+		}
+
+		return packV07.Pack(
+			op.Sender, bigOrZero(op.Nonce), keccak(op.InitCode()), keccak(op.CallData),
+			accountGasLimits, bigOrZero(op.PreVerificationGas), gasFees,
+			keccak(paymasterAndData),
+		)
+	}
+
+	return nil, fmt.Errorf("unsupported EntryPoint version %q", version)
+}
+
+func (op *UserOperation) Hash(entryPoint EntryPoint, chainID *big.Int) (common.Hash, error) {
+	packed, err := op.pack(entryPoint.Version)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error packing UserOperation: %w", err)
+	}
+
+	encoded, err := packHash.Pack(keccak(packed), entryPoint.Address, chainID)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error packing UserOperation hash: %w", err)
+	}
+
+	return ethereumCrypto.Keccak256Hash(encoded), nil
+}
+
+func (op *UserOperation) Sign(keyPair *crypto.KeyPair, entryPoint EntryPoint, chainID *big.Int) (common.Hash, error) {
+	hash, err := op.Hash(entryPoint, chainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	signature, err := keyPair.SignHash(accounts.TextHash(hash[:]))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error signing UserOperation: %w", err)
+	}
+	signature[64] += 27
+
+	op.Signature = signature
+	return hash, nil
+}
+
+func (op *UserOperation) RPC(entryPoint EntryPoint) (map[string]interface{}, error) {
+	fields := map[string]interface{}{
+		"sender":               op.Sender,
+		"nonce":                (*hexutil.Big)(bigOrZero(op.Nonce)),
+		"callData":             hexutil.Bytes(op.CallData),
+		"callGasLimit":         (*hexutil.Big)(bigOrZero(op.CallGasLimit)),
+		"verificationGasLimit": (*hexutil.Big)(bigOrZero(op.VerificationGasLimit)),
+		"preVerificationGas":   (*hexutil.Big)(bigOrZero(op.PreVerificationGas)),
+		"maxFeePerGas":         (*hexutil.Big)(bigOrZero(op.MaxFeePerGas)),
+		"maxPriorityFeePerGas": (*hexutil.Big)(bigOrZero(op.MaxPriorityFeePerGas)),
+		"signature":            hexutil.Bytes(op.Signature),
+	}
+
+	switch entryPoint.Version {
+	case Version06:
+		paymasterAndData, _ := op.PaymasterAndData(Version06)
+		fields["initCode"] = hexutil.Bytes(op.InitCode())
+		fields["paymasterAndData"] = hexutil.Bytes(paymasterAndData)
+	case Version07:
+		if op.Factory != nil {
+			fields["factory"] = op.Factory
+			fields["factoryData"] = hexutil.Bytes(op.FactoryData)
+		}
+		if op.Paymaster != nil {
+			fields["paymaster"] = op.Paymaster
+			fields["paymasterVerificationGasLimit"] = (*hexutil.Big)(bigOrZero(op.PaymasterVerificationGasLimit))
+			fields["paymasterPostOpGasLimit"] = (*hexutil.Big)(bigOrZero(op.PaymasterPostOpGasLimit))
+			fields["paymasterData"] = hexutil.Bytes(op.PaymasterData)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported EntryPoint version %q", entryPoint.Version)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,188 @@
+package userop
+
+import (
+	"math/big"
+	"testing"
+
+	"crypto-wallet/internal/crypto"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethereumCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	testSender    = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testFactory   = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	testPaymaster = common.HexToAddress("0x3333333333333333333333333333333333333333")
+	testChainID   = big.NewInt(11155111)
+)
+
+func testOperation() *UserOperation {
+	return &UserOperation{
+		Sender:                        testSender,
+		Nonce:                         big.NewInt(5),
+		Factory:                       &testFactory,
+		FactoryData:                   []byte{0xaa, 0xbb},
+		CallData:                      hexutil.MustDecode("0xb61d27f6"),
+		CallGasLimit:                  big.NewInt(100000),
+		VerificationGasLimit:          big.NewInt(200000),
+		PreVerificationGas:            big.NewInt(50000),
+		MaxFeePerGas:                  big.NewInt(3e9),
+		MaxPriorityFeePerGas:          big.NewInt(1e9),
+		Paymaster:                     &testPaymaster,
+		PaymasterVerificationGasLimit: big.NewInt(30000),
+		PaymasterPostOpGasLimit:       big.NewInt(10000),
+		PaymasterData:                 []byte{0xcc},
+	}
+}
+
+func word(value *big.Int) []byte {
+	return common.LeftPadBytes(value.Bytes(), 32)
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
+
+// Статические аргументы abi.encode — это просто 32-байтовые слова подряд
+func manualHash(packed []byte, entryPoint EntryPoint) common.Hash {
+	return ethereumCrypto.Keccak256Hash(concat(
+		ethereumCrypto.Keccak256(packed),
+		common.LeftPadBytes(entryPoint.Address.Bytes(), 32),
+		word(testChainID),
+	))
+}
+
+func TestHashV06(t *testing.T) {
+	op := testOperation()
+
+	initCode := concat(testFactory.Bytes(), op.FactoryData)
+	paymasterAndData := concat(testPaymaster.Bytes(), op.PaymasterData)
+	packed := concat(
+		common.LeftPadBytes(testSender.Bytes(), 32), word(op.Nonce),
+		ethereumCrypto.Keccak256(initCode), ethereumCrypto.Keccak256(op.CallData),
+		word(op.CallGasLimit), word(op.VerificationGasLimit), word(op.PreVerificationGas),
+		word(op.MaxFeePerGas), word(op.MaxPriorityFeePerGas),
+		ethereumCrypto.Keccak256(paymasterAndData),
+	)
+
+	hash, err := op.Hash(EntryPointV06, testChainID)
+	if err != nil {
+		t.Fatalf("Ошибка вычисления хеша: %v", err)
+	}
+	if expected := manualHash(packed, EntryPointV06); hash != expected {
+		t.Errorf("userOpHash v0.6: %s, ожидалось %s", hash.Hex(), expected.Hex())
+	}
+}
+
+func TestHashV07(t *testing.T) {
+	op := testOperation()
+
+	uint128 := func(value *big.Int) []byte {
+		return common.LeftPadBytes(value.Bytes(), 16)
+	}
+
+	initCode := concat(testFactory.Bytes(), op.FactoryData)
+	paymasterAndData := concat(testPaymaster.Bytes(), uint128(op.PaymasterVerificationGasLimit),
+		uint128(op.PaymasterPostOpGasLimit), op.PaymasterData)
+	packed := concat(
+		common.LeftPadBytes(testSender.Bytes(), 32), word(op.Nonce),
+		ethereumCrypto.Keccak256(initCode), ethereumCrypto.Keccak256(op.CallData),
+		uint128(op.VerificationGasLimit), uint128(op.CallGasLimit),
+		word(op.PreVerificationGas),
+		uint128(op.MaxPriorityFeePerGas), uint128(op.MaxFeePerGas),
+		ethereumCrypto.Keccak256(paymasterAndData),
+	)
+
+	hash, err := op.Hash(EntryPointV07, testChainID)
+	if err != nil {
+		t.Fatalf("Ошибка вычисления хеша: %v", err)
+	}
+	if expected := manualHash(packed, EntryPointV07); hash != expected {
+		t.Errorf("userOpHash v0.7: %s, ожидалось %s", hash.Hex(), expected.Hex())
+	}
+
+	// Газ v0.7 упаковывается в uint128
+	op.CallGasLimit = new(big.Int).Lsh(big.NewInt(1), 128)
+	if _, err := op.Hash(EntryPointV07, testChainID); err == nil {
+		t.Error("Лимит газа больше 128 бит должен отклоняться")
+	}
+}
+
+func TestSign(t *testing.T) {
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа: %v", err)
+	}
+	defer keyPair.Destroy()
+
+	op := testOperation()
+	hash, err := op.Sign(keyPair, EntryPointV07, testChainID)
+	if err != nil {
+		t.Fatalf("Ошибка подписи: %v", err)
+	}
+
+	if op.Signature[64] != 27 && op.Signature[64] != 28 {
+		t.Fatalf("v должен быть 27 или 28, получено %d", op.Signature[64])
+	}
+	if !crypto.VerifyHashSignature(common.BytesToHash(accounts.TextHash(hash[:])), op.Signature, keyPair.Address) {
+		t.Error("Подпись должна восстанавливать адрес владельца по EIP-191 хешу userOpHash")
+	}
+}
+
+func TestParseEntryPoint(t *testing.T) {
+	tests := map[string]EntryPoint{
+		"0.6":  EntryPointV06,
+		"v0.7": EntryPointV07,
+		"0x5ff137d4b0fdcd49dca30c7cf57e578a026d2789": EntryPointV06,
+		EntryPointV07.Address.Hex():                  EntryPointV07,
+	}
+	for input, expected := range tests {
+		entryPoint, err := ParseEntryPoint(input)
+		if err != nil || entryPoint != expected {
+			t.Errorf("ParseEntryPoint(%q) = %+v, %v", input, entryPoint, err)
+		}
+	}
+
+	if _, err := ParseEntryPoint("0.8"); err == nil {
+		t.Error("Неизвестная версия должна отклоняться")
+	}
+}
+
+func TestRPCFields(t *testing.T) {
+	op := testOperation()
+
+	v06, err := op.RPC(EntryPointV06)
+	if err != nil {
+		t.Fatalf("Ошибка кодирования v0.6: %v", err)
+	}
+	if v06["initCode"].(hexutil.Bytes).String() != "0x2222222222222222222222222222222222222222aabb" {
+		t.Errorf("Неверный initCode: %s", v06["initCode"])
+	}
+	if _, ok := v06["factory"]; ok {
+		t.Error("v0.6 не должна содержать поле factory")
+	}
+
+	v07, err := op.RPC(EntryPointV07)
+	if err != nil {
+		t.Fatalf("Ошибка кодирования v0.7: %v", err)
+	}
+	if _, ok := v07["initCode"]; ok {
+		t.Error("v0.7 не должна содержать поле initCode")
+	}
+	if *v07["paymaster"].(*common.Address) != testPaymaster {
+		t.Error("v0.7 должна содержать paymaster")
+	}
+
+	op.Factory, op.Paymaster = nil, nil
+	v07, _ = op.RPC(EntryPointV07)
+	if _, ok := v07["factory"]; ok {
+		t.Error("Развёрнутый аккаунт не должен передавать factory")
+	}
+}
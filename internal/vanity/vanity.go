@@ -0,0 +1,196 @@
+package vanity
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crypto-wallet/internal/crypto"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type Pattern struct {
+	Prefix        string
+	Suffix        string
+	Regex         *regexp.Regexp
+	CaseSensitive bool
+}
+
+type Progress struct {
+	Attempts uint64
+	Elapsed  time.Duration
+	Rate     float64
+	Expected time.Duration
+}
+
+func NewPattern(prefix, suffix, expr string, caseSensitive bool) (*Pattern, error) {
+	p := &Pattern{
+		Prefix:        strings.TrimPrefix(prefix, "0x"),
+		Suffix:        suffix,
+		CaseSensitive: caseSensitive,
+	}
+
+	if p.Prefix == "" && p.Suffix == "" && expr == "" {
+		return nil, fmt.Errorf("a prefix, suffix or regular expression is required")
+	}
+	if len(p.Prefix)+len(p.Suffix) > common.AddressLength*2 {
+		return nil, fmt.Errorf("prefix and suffix are longer than an address")
+	}
+
+	for _, part := range []string{p.Prefix, p.Suffix} {
+		for _, r := range part {
+			if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return nil, fmt.Errorf("%q contains a non-hex character", part)
+			}
+		}
+	}
+
+	if !caseSensitive {
+		p.Prefix = strings.ToLower(p.Prefix)
+		p.Suffix = strings.ToLower(p.Suffix)
+	}
+
+	if expr != "" {
+		if !caseSensitive {
+			expr = "(?i)" + expr
+		}
+		regex, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		p.Regex = regex
+	}
+
+	return p, nil
+}
+
+func (p *Pattern) Match(address common.Address) bool {
+	var text string
+	if p.CaseSensitive {
+		text = address.Hex()[2:]
+	} else {
+		text = strings.ToLower(address.Hex()[2:])
+	}
+
+	if !strings.HasPrefix(text, p.Prefix) || !strings.HasSuffix(text, p.Suffix) {
+		return false
+	}
+
+	return p.Regex == nil || p.Regex.MatchString(text)
+}
+
+func (p *Pattern) Difficulty() float64 {
+	if p.Regex != nil {
+		return 0
+	}
+
+	difficulty := math.Pow(16, float64(len(p.Prefix)+len(p.Suffix)))
+	if p.CaseSensitive {
+		for _, r := range p.Prefix + p.Suffix {
+			if (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F') {
+				difficulty *= 2
+			}
+		}
+	}
+
+	return difficulty
+}
+
+func (p *Pattern) ExpectedAttempts(probability float64) float64 {
+	difficulty := p.Difficulty()
+	if difficulty == 0 {
+		return 0
+	}
+	return math.Log(1-probability) / math.Log(1-1/difficulty)
+}
+
+func Search(ctx context.Context, pattern *Pattern, workers int, interval time.Duration, progress func(Progress)) (*crypto.KeyPair, uint64, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		attempts atomic.Uint64
+		once     sync.Once
+		found    *crypto.KeyPair
+		failure  error
+		wg       sync.WaitGroup
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for ctx.Err() == nil {
+				keyPair, err := crypto.GenerateKeyPair()
+				if err != nil {
+					once.Do(func() { failure = err })
+					cancel()
+					return
+				}
+				attempts.Add(1)
+
+				if !pattern.Match(keyPair.Address) {
+					keyPair.Destroy()
+					continue
+				}
+
+				once.Do(func() { found = keyPair })
+				if found != keyPair {
+					keyPair.Destroy()
+				}
+				cancel()
+				return
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	start := time.Now()
+	expected := pattern.ExpectedAttempts(0.5)
+
+	var ticker <-chan time.Time
+	if progress != nil && interval > 0 {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		ticker = t.C
+	}
+
+	for {
+		select {
+		case <-done:
+			if failure != nil {
+				return nil, attempts.Load(), failure
+			}
+			if found == nil {
+				return nil, attempts.Load(), ctx.Err()
+			}
+			return found, attempts.Load(), nil
+
+		case <-ticker:
+			elapsed := time.Since(start)
+			report := Progress{Attempts: attempts.Load(), Elapsed: elapsed}
+			report.Rate = float64(report.Attempts) / elapsed.Seconds()
+			if expected > 0 && report.Rate > 0 {
+				report.Expected = time.Duration(expected / report.Rate * float64(time.Second))
+			}
+			progress(report)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package vanity
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestPatternMatch(t *testing.T) {
+	address := common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+
+	tests := []struct {
+		prefix, suffix, regex string
+		caseSensitive         bool
+		match                 bool
+	}{
+		{"742d", "", "", false, true},
+		{"0x742D", "", "", false, true},
+		{"", "d8b6", "", false, true},
+		{"742d35cc", "", "", true, false},
+		{"742d35Cc", "", "", true, true},
+		{"", "d8B6", "", true, true},
+		{"", "", "^7[0-9]{2}d", false, true},
+		{"", "", "c0532925a3", true, false},
+		{"", "", "^742D35CC", false, true},
+		{"", "", "^742D35CC", true, false},
+		{"1", "", "", false, false},
+	}
+
+	for _, tt := range tests {
+		pattern, err := NewPattern(tt.prefix, tt.suffix, tt.regex, tt.caseSensitive)
+		if err != nil {
+			t.Fatalf("Ошибка создания шаблона: %v", err)
+		}
+		if pattern.Match(address) != tt.match {
+			t.Errorf("Шаблон %+v: ожидалось совпадение %v", tt, tt.match)
+		}
+	}
+}
+
+func TestNewPatternInvalid(t *testing.T) {
+	if _, err := NewPattern("", "", "", false); err == nil {
+		t.Error("Должна быть ошибка для пустого шаблона")
+	}
+	if _, err := NewPattern("cafeg", "", "", false); err == nil {
+		t.Error("Должна быть ошибка для не-hex символа")
+	}
+	if _, err := NewPattern(strings.Repeat("a", 41), "", "", false); err == nil {
+		t.Error("Должна быть ошибка для слишком длинного префикса")
+	}
+	if _, err := NewPattern("", "", "([", false); err == nil {
+		t.Error("Должна быть ошибка для неверного выражения")
+	}
+}
+
+func TestDifficulty(t *testing.T) {
+	pattern, _ := NewPattern("dead", "", "", false)
+	if pattern.Difficulty() != 65536 {
+		t.Errorf("Неверная сложность: %v", pattern.Difficulty())
+	}
+
+	// В режиме EIP-55 каждая буква дополнительно удваивает сложность
+	pattern, _ = NewPattern("De01", "", "", true)
+	if pattern.Difficulty() != 65536*4 {
+		t.Errorf("Неверная сложность с учетом регистра: %v", pattern.Difficulty())
+	}
+
+	pattern, _ = NewPattern("a", "", "", false)
+	expected := math.Log(0.5) / math.Log(1-1.0/16)
+	if math.Abs(pattern.ExpectedAttempts(0.5)-expected) > 1e-9 {
+		t.Errorf("Неверное ожидаемое число попыток: %v", pattern.ExpectedAttempts(0.5))
+	}
+
+	pattern, _ = NewPattern("", "", "abc", false)
+	if pattern.Difficulty() != 0 {
+		t.Error("Для регулярного выражения сложность неизвестна")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	pattern, err := NewPattern("a", "", "", false)
+	if err != nil {
+		t.Fatalf("Ошибка создания шаблона: %v", err)
+	}
+
+	keyPair, attempts, err := Search(context.Background(), pattern, 4, time.Millisecond, func(Progress) {})
+	if err != nil {
+		t.Fatalf("Ошибка поиска: %v", err)
+	}
+	if !pattern.Match(keyPair.Address) {
+		t.Errorf("Найденный адрес не подходит: %s", keyPair.Address.Hex())
+	}
+	if attempts == 0 {
+		t.Error("Число попыток должно быть больше нуля")
+	}
+}
+
+func TestSearchCancel(t *testing.T) {
+	pattern, _ := NewPattern(strings.Repeat("0", 40), "", "", false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, _, err := Search(ctx, pattern, 2, 0, nil); err == nil {
+		t.Fatal("Поиск должен завершиться после отмены контекста")
+	}
+}
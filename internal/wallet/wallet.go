@@ -4,32 +4,79 @@ import (
 	"crypto/ecdsa"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"crypto-wallet/internal/blockchain"
 	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/gasoracle"
+	"crypto-wallet/internal/policy"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	ethereumCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
 type Wallet struct {
 	KeyPair    *crypto.KeyPair
 	Blockchain *blockchain.Client
 	WalletFile string
+	PolicyFile string
+	Policy     *policy.Engine
+	FeeOracle  *gasoracle.Oracle
+	FeeTier    gasoracle.Tier
+
+	SessionTimeout time.Duration
+	lockTimer      *time.Timer
 }
 
+var ErrWalletLocked = errors.New("wallet is locked, the unlock session has expired")
+
 type WalletData struct {
 	PrivateKey string `json:"private_key"`
 	PublicKey  string `json:"public_key"`
 	Address    string `json:"address"`
 }
 
+type storedWallet struct {
+	PrivateKey secretHex `json:"private_key"`
+	PublicKey  string    `json:"public_key"`
+	Address    string    `json:"address"`
+}
+
+type secretHex []byte
+
+func (s *secretHex) UnmarshalJSON(data []byte) error {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("private key must be a hex string")
+	}
+
+	raw := data[1 : len(data)-1]
+	decoded := make([]byte, hex.DecodedLen(len(raw)))
+	if _, err := hex.Decode(decoded, raw); err != nil {
+		crypto.Wipe(decoded)
+		return fmt.Errorf("error decoding private key: %w", err)
+	}
+
+	*s = decoded
+	return nil
+}
+
+func (s secretHex) MarshalJSON() ([]byte, error) {
+	encoded := make([]byte, hex.EncodedLen(len(s))+2)
+	encoded[0] = '"'
+	hex.Encode(encoded[1:], s)
+	encoded[len(encoded)-1] = '"'
+	return encoded, nil
+}
+
 func NewWallet(blockchainURL string, walletFile string) (*Wallet, error) {
 	client, err := blockchain.NewClient(blockchainURL)
 	if err != nil {
@@ -48,6 +95,7 @@ func (w *Wallet) GenerateNewWallet() error {
 		return fmt.Errorf("error generating keys: %w", err)
 	}
 
+	w.Lock()
 	w.KeyPair = keyPair
 
 	err = w.SaveWallet()
@@ -67,19 +115,54 @@ func (w *Wallet) LoadWallet() error {
 	if err != nil {
 		return fmt.Errorf("error reading wallet file: %w", err)
 	}
+	defer crypto.Wipe(data)
 
-	var walletData WalletData
-	err = json.Unmarshal(data, &walletData)
+	var stored storedWallet
+	err = json.Unmarshal(data, &stored)
+	defer crypto.Wipe(stored.PrivateKey)
 	if err != nil {
 		return fmt.Errorf("error parsing wallet data: %w", err)
 	}
 
-	keyPair, err := w.restoreKeyPair(walletData)
+	keyPair, err := restoreKeyPair(stored)
 	if err != nil {
 		return fmt.Errorf("error restoring keys: %w", err)
 	}
 
+	w.Lock()
 	w.KeyPair = keyPair
+
+	if w.SessionTimeout > 0 {
+		w.lockTimer = time.AfterFunc(w.SessionTimeout, keyPair.Destroy)
+	}
+
+	if err := w.loadPolicy(); err != nil {
+		return fmt.Errorf("error loading spending policy: %w", err)
+	}
+
+	return nil
+}
+
+func (w *Wallet) loadPolicy() error {
+	path := w.PolicyFile
+	if path == "" {
+		path = w.SidecarPath("policy.json")
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil
+		}
+	}
+
+	p, err := policy.LoadPolicy(path)
+	if err != nil {
+		return err
+	}
+
+	engine, err := policy.NewEngine(p, w.SidecarPath("spending.json"))
+	if err != nil {
+		return err
+	}
+
+	w.Policy = engine
 	return nil
 }
 
@@ -88,16 +171,21 @@ func (w *Wallet) SaveWallet() error {
 		return fmt.Errorf("wallet not initialized")
 	}
 
-	walletData := WalletData{
-		PrivateKey: w.KeyPair.GetPrivateKeyHex(),
-		PublicKey:  w.KeyPair.GetPublicKeyHex(),
-		Address:    w.KeyPair.GetAddressHex(),
+	privateKey, err := w.KeyPair.PrivateKeyBytes()
+	if err != nil {
+		return ErrWalletLocked
 	}
+	defer crypto.Wipe(privateKey)
 
-	data, err := json.MarshalIndent(walletData, "", "  ")
+	data, err := json.MarshalIndent(storedWallet{
+		PrivateKey: privateKey,
+		PublicKey:  w.KeyPair.GetPublicKeyHex(),
+		Address:    w.KeyPair.GetAddressHex(),
+	}, "", "  ")
 	if err != nil {
 		return fmt.Errorf("error serializing wallet data: %w", err)
 	}
+	defer crypto.Wipe(data)
 
 	dir := filepath.Dir(w.WalletFile)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -112,6 +200,11 @@ func (w *Wallet) SaveWallet() error {
 	return nil
 }
 
+func (w *Wallet) SidecarPath(name string) string {
+	base := strings.TrimSuffix(w.WalletFile, filepath.Ext(w.WalletFile))
+	return base + "." + name
+}
+
 func (w *Wallet) GetAddress() (string, error) {
 	if w.KeyPair == nil {
 		return "", fmt.Errorf("wallet not initialized")
@@ -133,58 +226,271 @@ func (w *Wallet) GetBalance() (*big.Float, error) {
 	return balance, nil
 }
 
+type TxRequest struct {
+	To       common.Address
+	Value    *big.Int
+	Data     []byte
+	Nonce    *uint64
+	GasLimit uint64
+	GasPrice *big.Int
+	ABI      *abi.ABI
+	Force    bool
+
+	AccessList types.AccessList
+}
+
 func (w *Wallet) SendTransaction(toAddress string, amount *big.Float) (string, error) {
 	if w.KeyPair == nil {
 		return "", fmt.Errorf("wallet not initialized")
 	}
 
-	if !crypto.IsValidAddress(toAddress) {
-		return "", fmt.Errorf("invalid recipient address: %s", toAddress)
+	to, err := w.ResolveAddress(toAddress)
+	if err != nil {
+		return "", fmt.Errorf("invalid recipient address: %w", err)
 	}
 
-	toAddr := common.HexToAddress(toAddress)
+	return w.Send(TxRequest{
+		To:    to,
+		Value: crypto.EtherToWei(amount),
+	})
+}
 
-	amountWei := crypto.EtherToWei(amount)
+func (w *Wallet) ResolveAddress(input string) (common.Address, error) {
+	if blockchain.IsENSName(input) {
+		return w.Blockchain.ResolveENS(input)
+	}
+	return crypto.HexToAddress(input)
+}
+
+func (w *Wallet) Simulate(req TxRequest) error {
+	if w.KeyPair == nil {
+		return fmt.Errorf("wallet not initialized")
+	}
 
-	nonce, err := w.Blockchain.GetNonce(w.KeyPair.Address)
+	return w.Blockchain.SimulateTransaction(w.KeyPair.Address, req.To, req.Value, req.Data, req.ABI)
+}
+
+func (w *Wallet) Send(req TxRequest) (string, error) {
+	signedTx, err := w.SignRequest(req)
 	if err != nil {
-		return "", fmt.Errorf("error getting nonce: %w", err)
+		return "", err
 	}
 
-	gasPrice, err := w.Blockchain.GetGasPrice()
+	if err := w.Broadcast(signedTx); err != nil {
+		return "", err
+	}
+
+	return signedTx.Hash().Hex(), nil
+}
+
+func (w *Wallet) Broadcast(signedTx *types.Transaction) error {
+	err := w.Blockchain.SendTransaction(signedTx)
 	if err != nil {
-		return "", fmt.Errorf("error getting gas price: %w", err)
+		return fmt.Errorf("error sending transaction: %w", err)
+	}
+
+	if w.Policy != nil {
+		if err := w.Policy.Record(policyTransaction(signedTx)); err != nil {
+			return fmt.Errorf("error recording spending: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (w *Wallet) CheckPolicy(tx policy.Transaction) error {
+	if w.Policy == nil {
+		return nil
 	}
+	return w.Policy.Check(tx)
+}
 
-	gasLimit, err := w.Blockchain.EstimateGas(w.KeyPair.Address, &toAddr, amountWei, nil)
+func (w *Wallet) RecordPolicy(tx policy.Transaction) error {
+	if w.Policy == nil {
+		return nil
+	}
+	if err := w.Policy.Record(tx); err != nil {
+		return fmt.Errorf("error recording spending: %w", err)
+	}
+	return nil
+}
+
+func policyTransaction(tx *types.Transaction) policy.Transaction {
+	var to common.Address
+	if tx.To() != nil {
+		to = *tx.To()
+	}
+
+	return policy.Transaction{
+		Hash:     tx.Hash(),
+		To:       to,
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+		GasPrice: tx.GasPrice(),
+	}
+}
+
+func (w *Wallet) GasPrice() (*big.Int, error) {
+	if w.FeeOracle == nil {
+		gasPrice, err := w.Blockchain.GetGasPrice()
+		if err != nil {
+			return nil, fmt.Errorf("error getting gas price: %w", err)
+		}
+		return gasPrice, nil
+	}
+
+	tier := w.FeeTier
+	if tier == "" {
+		tier = gasoracle.Standard
+	}
+
+	gasPrice, err := w.FeeOracle.GasPrice(tier)
+	if err != nil {
+		return nil, fmt.Errorf("error getting gas price: %w", err)
+	}
+	return gasPrice, nil
+}
+
+func (w *Wallet) SignRequest(req TxRequest) (*types.Transaction, error) {
+	if w.KeyPair == nil {
+		return nil, fmt.Errorf("wallet not initialized")
+	}
+	if w.KeyPair.Destroyed() {
+		return nil, ErrWalletLocked
+	}
+
+	value := req.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	req.Value = value
+
+	if !req.Force {
+		if err := w.Simulate(req); err != nil {
+			return nil, fmt.Errorf("transaction simulation failed: %w", err)
+		}
+	}
+
+	var nonce uint64
+	if req.Nonce != nil {
+		nonce = *req.Nonce
+	} else {
+		pendingNonce, err := w.Blockchain.GetNonce(w.KeyPair.Address)
+		if err != nil {
+			return nil, fmt.Errorf("error getting nonce: %w", err)
+		}
+		nonce = pendingNonce
+	}
+
+	gasPrice := req.GasPrice
+	if gasPrice == nil {
+		suggested, err := w.GasPrice()
+		if err != nil {
+			return nil, err
+		}
+		gasPrice = suggested
+	} else if w.FeeOracle != nil {
+		if err := w.FeeOracle.CheckCeiling(gasPrice); err != nil {
+			return nil, err
+		}
+	}
+
+	gasLimit := req.GasLimit
+	if gasLimit == 0 {
+		var estimated uint64
+		var err error
+		if req.AccessList != nil {
+			estimated, err = w.Blockchain.EstimateGasWithAccessList(w.KeyPair.Address, req.To, value, req.Data, req.AccessList)
+		} else {
+			estimated, err = w.Blockchain.EstimateGas(w.KeyPair.Address, &req.To, value, req.Data)
+		}
+		if err != nil {
+			if !req.Force {
+				return nil, fmt.Errorf("error estimating gas: %w", err)
+			}
+			estimated = 21000
+		}
+		gasLimit = estimated
+	}
+
+	if w.Policy != nil {
+		err := w.Policy.Check(policy.Transaction{
+			To:       req.To,
+			Value:    value,
+			Data:     req.Data,
+			GasPrice: gasPrice,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tx *types.Transaction
+	if req.AccessList != nil {
+		chainID, err := w.Blockchain.GetChainID()
+		if err != nil {
+			return nil, err
+		}
+		tx = w.Blockchain.CreateAccessListTransaction(chainID, req.To, value, gasLimit, gasPrice, nonce, req.Data, req.AccessList)
+	} else {
+		tx = w.Blockchain.CreateTransaction(
+			w.KeyPair.Address,
+			req.To,
+			value,
+			gasLimit,
+			gasPrice,
+			nonce,
+			req.Data,
+		)
+	}
+
+	var signedTx *types.Transaction
+	err := w.KeyPair.WithPrivateKey(func(privateKey *ecdsa.PrivateKey) error {
+		var err error
+		signedTx, err = w.Blockchain.SignTransaction(tx, privateKey)
+		return err
+	})
+	if errors.Is(err, crypto.ErrKeyDestroyed) {
+		return nil, ErrWalletLocked
+	}
 	if err != nil {
-		gasLimit = 21000
+		return nil, fmt.Errorf("error signing transaction: %w", err)
 	}
 
-	tx := w.Blockchain.CreateTransaction(
-		w.KeyPair.Address,
-		toAddr,
-		amountWei,
-		gasLimit,
-		gasPrice,
-		nonce,
-		nil,
-	)
+	return signedTx, nil
+}
+
+type AccessListEstimate struct {
+	AccessList types.AccessList
+	GasWithout uint64
+	GasWith    uint64
+}
 
-	privateKeyInt := new(big.Int)
-	privateKeyInt.SetString(w.KeyPair.GetPrivateKeyHex(), 16)
+func (e *AccessListEstimate) Cheaper() bool {
+	return e.GasWith < e.GasWithout
+}
 
-	signedTx, err := w.Blockchain.SignTransaction(tx, privateKeyInt)
+func (w *Wallet) EstimateAccessList(req TxRequest) (*AccessListEstimate, error) {
+	if w.KeyPair == nil {
+		return nil, fmt.Errorf("wallet not initialized")
+	}
+
+	without, err := w.Blockchain.EstimateGas(w.KeyPair.Address, &req.To, req.Value, req.Data)
 	if err != nil {
-		return "", fmt.Errorf("error signing transaction: %w", err)
+		return nil, err
 	}
 
-	err = w.Blockchain.SendTransaction(signedTx)
+	accessList, _, err := w.Blockchain.CreateAccessList(w.KeyPair.Address, req.To, req.Value, req.Data)
 	if err != nil {
-		return "", fmt.Errorf("error sending transaction: %w", err)
+		return nil, err
 	}
 
-	return signedTx.Hash().Hex(), nil
+	with, err := w.Blockchain.EstimateGasWithAccessList(w.KeyPair.Address, req.To, req.Value, req.Data, accessList)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccessListEstimate{AccessList: accessList, GasWithout: without, GasWith: with}, nil
 }
 
 func (w *Wallet) GetTransactionStatus(txHash string) (*types.Receipt, error) {
@@ -197,6 +503,10 @@ func (w *Wallet) GetTransactionStatus(txHash string) (*types.Receipt, error) {
 	return receipt, nil
 }
 
+func (w *Wallet) ReplayTransaction(txHash string, contractABI *abi.ABI) error {
+	return w.Blockchain.ReplayTransaction(common.HexToHash(txHash), contractABI)
+}
+
 func (w *Wallet) WaitForTransaction(txHash string, maxAttempts int) (*types.Receipt, error) {
 	hash := common.HexToHash(txHash)
 	receipt, err := w.Blockchain.WaitForTransaction(hash, maxAttempts)
@@ -207,12 +517,34 @@ func (w *Wallet) WaitForTransaction(txHash string, maxAttempts int) (*types.Rece
 	return receipt, nil
 }
 
+func (w *Wallet) WaitForConfirmations(txHash string, confirmations uint64, timeout time.Duration) (*blockchain.WaitResult, error) {
+	hash := common.HexToHash(txHash)
+	result, err := w.Blockchain.WaitForConfirmations(hash, confirmations, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for transaction confirmations: %w", err)
+	}
+
+	return result, nil
+}
+
+func (w *Wallet) GetConfirmations(receipt *types.Receipt) (uint64, bool, error) {
+	confirmations, canonical, err := w.Blockchain.GetConfirmations(receipt)
+	if err != nil {
+		return 0, false, fmt.Errorf("error getting confirmations: %w", err)
+	}
+
+	return confirmations, canonical, nil
+}
+
 func (w *Wallet) SignMessage(message []byte) ([]byte, error) {
 	if w.KeyPair == nil {
 		return nil, fmt.Errorf("wallet not initialized")
 	}
 
 	signature, err := w.KeyPair.SignMessage(message)
+	if errors.Is(err, crypto.ErrKeyDestroyed) {
+		return nil, ErrWalletLocked
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error signing message: %w", err)
 	}
@@ -220,47 +552,143 @@ func (w *Wallet) SignMessage(message []byte) ([]byte, error) {
 	return signature, nil
 }
 
-func (w *Wallet) VerifyMessage(message []byte, signature []byte, address string) bool {
+func (w *Wallet) SignHash(hash common.Hash) ([]byte, error) {
+	if w.KeyPair == nil {
+		return nil, fmt.Errorf("wallet not initialized")
+	}
+
+	signature, err := w.KeyPair.SignHash(hash[:])
+	if errors.Is(err, crypto.ErrKeyDestroyed) {
+		return nil, ErrWalletLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signature[64] += 27
+	return signature, nil
+}
+
+func (w *Wallet) SignTypedData(typedData apitypes.TypedData) ([]byte, common.Hash, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, common.Hash{}, fmt.Errorf("error hashing typed data: %w", err)
+	}
+
+	signature, err := w.SignHash(common.BytesToHash(hash))
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+
+	return signature, common.BytesToHash(hash), nil
+}
+
+type SignatureMethod string
+
+const (
+	SignatureInvalid SignatureMethod = ""
+	SignatureECDSA   SignatureMethod = "ECDSA"
+	SignatureERC1271 SignatureMethod = "ERC-1271"
+	SignatureERC6492 SignatureMethod = "ERC-6492"
+)
+
+func (w *Wallet) VerifyMessage(message []byte, signature []byte, address string) (bool, error) {
 	if !crypto.IsValidAddress(address) {
-		return false
+		return false, fmt.Errorf("invalid address: %s", address)
 	}
 
-	addr := common.HexToAddress(address)
-	return crypto.VerifySignature(message, signature, addr)
+	method, err := w.VerifyHash(ethereumCrypto.Keccak256Hash(message), signature, common.HexToAddress(address))
+	if err != nil {
+		return false, err
+	}
+	return method != SignatureInvalid, nil
 }
 
-func (w *Wallet) Close() {
-	if w.Blockchain != nil {
-		w.Blockchain.Close()
+func (w *Wallet) VerifyHash(hash common.Hash, signature []byte, signer common.Address) (SignatureMethod, error) {
+	if blockchain.IsERC6492Signature(signature) {
+		return w.verifyERC6492(hash, signature, signer)
+	}
+
+	if crypto.VerifyHashSignature(hash, signature, signer) {
+		return SignatureECDSA, nil
+	}
+
+	code, err := w.Blockchain.GetCode(signer)
+	if err != nil || len(code) == 0 {
+		return SignatureInvalid, err
+	}
+
+	valid, err := w.Blockchain.IsValidSignature(signer, hash, signature)
+	if err != nil || !valid {
+		return SignatureInvalid, err
 	}
+	return SignatureERC1271, nil
 }
 
-func (w *Wallet) restoreKeyPair(walletData WalletData) (*crypto.KeyPair, error) {
-	privateKeyBytes, err := hex.DecodeString(walletData.PrivateKey)
+func (w *Wallet) verifyERC6492(hash common.Hash, signature []byte, signer common.Address) (SignatureMethod, error) {
+	wrapped, err := blockchain.UnwrapERC6492(signature)
 	if err != nil {
-		return nil, fmt.Errorf("error decoding private key: %w", err)
+		return SignatureInvalid, err
 	}
 
-	privateKey, err := ethereumCrypto.ToECDSA(privateKeyBytes)
+	code, err := w.Blockchain.GetCode(signer)
 	if err != nil {
-		return nil, fmt.Errorf("error restoring private key: %w", err)
+		return SignatureInvalid, err
 	}
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("error casting public key to ECDSA")
+	if len(code) > 0 {
+		valid, err := w.Blockchain.IsValidSignature(signer, hash, wrapped.Signature)
+		if err != nil {
+			return SignatureInvalid, err
+		}
+		if valid {
+			return SignatureERC1271, nil
+		}
 	}
 
-	address := ethereumCrypto.PubkeyToAddress(*publicKeyECDSA)
+	valid, err := w.Blockchain.IsValidSignatureCounterfactual(signer, hash, wrapped)
+	if err != nil || !valid {
+		return SignatureInvalid, err
+	}
+	return SignatureERC6492, nil
+}
 
-	if strings.ToLower(address.Hex()) != strings.ToLower(walletData.Address) {
-		return nil, fmt.Errorf("address mismatch: expected %s, got %s", walletData.Address, address.Hex())
+func (w *Wallet) Lock() {
+	if w.lockTimer != nil {
+		w.lockTimer.Stop()
+		w.lockTimer = nil
 	}
 
-	return &crypto.KeyPair{
-		PrivateKey: privateKey,
-		PublicKey:  publicKeyECDSA,
-		Address:    address,
-	}, nil
+	if w.KeyPair != nil {
+		w.KeyPair.Destroy()
+	}
+}
+
+func (w *Wallet) Locked() bool {
+	return w.KeyPair == nil || w.KeyPair.Destroyed()
+}
+
+func (w *Wallet) Close() {
+	w.Lock()
+
+	if w.Blockchain != nil {
+		w.Blockchain.Close()
+	}
+}
+
+func restoreKeyPair(stored storedWallet) (*crypto.KeyPair, error) {
+	privateKey := make([]byte, len(stored.PrivateKey))
+	copy(privateKey, stored.PrivateKey)
+
+	keyPair, err := crypto.NewKeyPair(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(keyPair.Address.Hex(), stored.Address) {
+		keyPair.Destroy()
+		return nil, fmt.Errorf("address mismatch: expected %s, got %s", stored.Address, keyPair.Address.Hex())
+	}
+
+	return keyPair, nil
 }
@@ -1,11 +1,26 @@
 package wallet
 
 import (
+	"encoding/json"
+	"errors"
 	"math/big"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"crypto-wallet/internal/blockchain"
 	"crypto-wallet/internal/crypto"
+	"crypto-wallet/internal/gasoracle"
+	"crypto-wallet/internal/policy"
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethereumCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
 func TestNewWallet(t *testing.T) {
@@ -151,7 +166,7 @@ func TestSendTransactionValidation(t *testing.T) {
 
 	// Пытаемся отправить транзакцию без инициализации кошелька
 	amount := big.NewFloat(0.001)
-	_, err = w.SendTransaction("0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6", amount)
+	_, err = w.SendTransaction("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6", amount)
 	if err == nil {
 		t.Fatal("Должна быть ошибка при отправке транзакции неинициализированным кошельком")
 	}
@@ -170,7 +185,7 @@ func TestSendTransactionValidation(t *testing.T) {
 
 	// Пытаемся отправить отрицательную сумму
 	negativeAmount := big.NewFloat(-0.001)
-	_, err = w.SendTransaction("0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6", negativeAmount)
+	_, err = w.SendTransaction("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6", negativeAmount)
 	if err == nil {
 		t.Fatal("Должна быть ошибка для отрицательной суммы")
 	}
@@ -180,8 +195,19 @@ func TestSendTransactionValidation(t *testing.T) {
 }
 
 func TestSignAndVerifyMessage(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	codeErr := false
+	server.Handle("eth_getCode", func(params []json.RawMessage) (interface{}, error) {
+		if codeErr {
+			return nil, &rpctest.Error{Code: -32000, Message: "node unavailable"}
+		}
+		return "0x", nil
+	})
+
 	// Создаем временный кошелек
-	w, err := NewWallet("https://sepolia.infura.io/v3/test", "test-wallet.json")
+	w, err := NewWallet(server.URL, "test-wallet.json")
 	if err != nil {
 		t.Fatalf("Ошибка создания кошелька: %v", err)
 	}
@@ -212,25 +238,36 @@ func TestSignAndVerifyMessage(t *testing.T) {
 	}
 
 	// Проверяем подпись
-	isValid := w.VerifyMessage(message, signature, address)
-	if !isValid {
+	isValid, err := w.VerifyMessage(message, signature, address)
+	if err != nil || !isValid {
 		t.Fatal("Подпись должна быть валидной")
 	}
 
 	// Проверяем с неправильным сообщением
 	wrongMessage := []byte("Wrong message")
-	isValid = w.VerifyMessage(wrongMessage, signature, address)
-	if isValid {
+	isValid, err = w.VerifyMessage(wrongMessage, signature, address)
+	if err != nil || isValid {
 		t.Fatal("Подпись не должна быть валидной для неправильного сообщения")
 	}
 
 	// Проверяем с неправильным адресом
-	wrongAddress := "0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6"
-	isValid = w.VerifyMessage(message, signature, wrongAddress)
-	if isValid {
+	wrongAddress := "0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6"
+	isValid, err = w.VerifyMessage(message, signature, wrongAddress)
+	if err != nil || isValid {
 		t.Fatal("Подпись не должна быть валидной для неправильного адреса")
 	}
 
+	// Ошибка узла не должна превращаться в «подпись невалидна»
+	codeErr = true
+	if _, err := w.VerifyMessage(message, signature, wrongAddress); err == nil {
+		t.Fatal("Ошибка получения кода должна возвращаться")
+	}
+
+	// Подпись EOA проверяется без обращения к узлу
+	if isValid, err := w.VerifyMessage(message, signature, address); err != nil || !isValid {
+		t.Fatalf("Подпись EOA должна проверяться без узла: %v", err)
+	}
+
 	// Очищаем тестовый файл
 	os.Remove("test-wallet.json")
 }
@@ -309,3 +346,468 @@ func TestBigFloatOperations(t *testing.T) {
 		t.Fatalf("Умножение неверно: ожидалось %s, получено %s", expectedProduct.Text('f', 18), product.Text('f', 18))
 	}
 }
+
+func TestSidecarPath(t *testing.T) {
+	w := &Wallet{WalletFile: "keys/wallet.json"}
+
+	if path := w.SidecarPath("watch.json"); path != "keys/wallet.watch.json" {
+		t.Fatalf("Неверный путь файла метаданных: %s", path)
+	}
+
+	w.WalletFile = "wallet"
+	if path := w.SidecarPath("watch.json"); path != "wallet.watch.json" {
+		t.Fatalf("Неверный путь файла метаданных без расширения: %s", path)
+	}
+}
+
+func TestSendAbortsOnSimulatedRevert(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	var sent int
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		return nil, &rpctest.Error{Code: 3, Message: "execution reverted", Data: "0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000006" +
+			"7061757365640000000000000000000000000000000000000000000000000000"}
+	})
+	server.Handle("eth_estimateGas", func(params []json.RawMessage) (interface{}, error) {
+		return nil, &rpctest.Error{Code: 3, Message: "execution reverted"}
+	})
+	server.Handle("eth_getTransactionCount", func(params []json.RawMessage) (interface{}, error) {
+		return "0x0", nil
+	})
+	server.Handle("eth_gasPrice", func(params []json.RawMessage) (interface{}, error) {
+		return "0x3b9aca00", nil
+	})
+	server.Handle("net_version", func(params []json.RawMessage) (interface{}, error) {
+		return "11155111", nil
+	})
+	server.Handle("eth_sendRawTransaction", func(params []json.RawMessage) (interface{}, error) {
+		sent++
+		return common.Hash{}, nil
+	})
+
+	w, err := NewWallet(server.URL, filepath.Join(t.TempDir(), "wallet.json"))
+	if err != nil {
+		t.Fatalf("Ошибка создания кошелька: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.GenerateNewWallet(); err != nil {
+		t.Fatalf("Ошибка генерации кошелька: %v", err)
+	}
+
+	req := TxRequest{To: common.HexToAddress("0x01"), Value: big.NewInt(1)}
+
+	_, err = w.Send(req)
+
+	var revert *blockchain.RevertError
+	if !errors.As(err, &revert) || revert.Reason != "paused" {
+		t.Fatalf("Ожидалась ошибка отката с причиной paused, получено %v", err)
+	}
+	if sent != 0 {
+		t.Fatal("Транзакция не должна отправляться после неудачной симуляции")
+	}
+
+	// С флагом Force транзакция отправляется со стандартным лимитом газа
+	req.Force = true
+	if _, err := w.Send(req); err != nil {
+		t.Fatalf("Ошибка принудительной отправки: %v", err)
+	}
+	if sent != 1 {
+		t.Fatal("Принудительная транзакция должна быть отправлена")
+	}
+}
+
+func TestSendEnforcesPolicy(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	var sent int
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		return "0x", nil
+	})
+	server.Handle("eth_estimateGas", func(params []json.RawMessage) (interface{}, error) {
+		return "0x5208", nil
+	})
+	server.Handle("eth_getTransactionCount", func(params []json.RawMessage) (interface{}, error) {
+		return "0x0", nil
+	})
+	server.Handle("eth_gasPrice", func(params []json.RawMessage) (interface{}, error) {
+		return "0x3b9aca00", nil
+	})
+	server.Handle("net_version", func(params []json.RawMessage) (interface{}, error) {
+		return "11155111", nil
+	})
+	server.Handle("eth_sendRawTransaction", func(params []json.RawMessage) (interface{}, error) {
+		sent++
+		return common.Hash{}, nil
+	})
+
+	walletFile := filepath.Join(t.TempDir(), "wallet.json")
+	w, err := NewWallet(server.URL, walletFile)
+	if err != nil {
+		t.Fatalf("Ошибка создания кошелька: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.GenerateNewWallet(); err != nil {
+		t.Fatalf("Ошибка генерации кошелька: %v", err)
+	}
+
+	policyData := `{"assets": {"ETH": {"max_per_tx": "1", "daily_limit": "1.5"}}}`
+	if err := os.WriteFile(w.SidecarPath("policy.json"), []byte(policyData), 0600); err != nil {
+		t.Fatalf("Ошибка записи политики: %v", err)
+	}
+
+	if err := w.LoadWallet(); err != nil {
+		t.Fatalf("Ошибка загрузки кошелька: %v", err)
+	}
+	if w.Policy == nil {
+		t.Fatal("Политика из файла рядом с кошельком не загружена")
+	}
+
+	to := common.HexToAddress("0x01")
+	oneEther := big.NewInt(1e18)
+
+	// Политика проверяется и при принудительной отправке
+	_, err = w.Send(TxRequest{To: to, Value: new(big.Int).Mul(oneEther, big.NewInt(2)), Force: true})
+	var violation *policy.Violation
+	if !errors.As(err, &violation) || violation.Rule != policy.RuleMaxPerTx {
+		t.Fatalf("Ожидалось нарушение max_per_tx, получено %v", err)
+	}
+
+	if _, err := w.Send(TxRequest{To: to, Value: oneEther}); err != nil {
+		t.Fatalf("Ошибка отправки в пределах лимита: %v", err)
+	}
+
+	_, err = w.Send(TxRequest{To: to, Value: oneEther, Nonce: new(uint64)})
+	if !errors.As(err, &violation) || violation.Rule != policy.RuleDailyLimit {
+		t.Fatalf("Ожидалось нарушение daily_limit, получено %v", err)
+	}
+
+	if sent != 1 {
+		t.Fatalf("Ожидалась 1 отправленная транзакция, отправлено %d", sent)
+	}
+}
+
+func TestSessionLocksWallet(t *testing.T) {
+	walletFile := filepath.Join(t.TempDir(), "wallet.json")
+	w, err := NewWallet("https://sepolia.infura.io/v3/test", walletFile)
+	if err != nil {
+		t.Fatalf("Ошибка создания кошелька: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.GenerateNewWallet(); err != nil {
+		t.Fatalf("Ошибка генерации кошелька: %v", err)
+	}
+	address := w.KeyPair.Address
+
+	w.SessionTimeout = 20 * time.Millisecond
+	if err := w.LoadWallet(); err != nil {
+		t.Fatalf("Ошибка загрузки кошелька: %v", err)
+	}
+	if _, err := w.SignMessage([]byte("test")); err != nil {
+		t.Fatalf("Подпись в пределах сессии должна работать: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !w.Locked() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !w.Locked() {
+		t.Fatal("Кошелёк должен заблокироваться после окончания сессии")
+	}
+
+	if _, err := w.SignMessage([]byte("test")); !errors.Is(err, ErrWalletLocked) {
+		t.Fatalf("Ожидалась ошибка ErrWalletLocked, получено: %v", err)
+	}
+	if _, err := w.SignRequest(TxRequest{To: common.HexToAddress("0x01")}); !errors.Is(err, ErrWalletLocked) {
+		t.Fatalf("Транзакция не должна подписываться после блокировки, получено: %v", err)
+	}
+	if w.KeyPair.Address != address {
+		t.Fatal("Адрес должен оставаться доступным после блокировки")
+	}
+
+	// Повторная загрузка снова открывает сессию
+	if err := w.LoadWallet(); err != nil {
+		t.Fatalf("Ошибка повторной загрузки кошелька: %v", err)
+	}
+	if w.Locked() {
+		t.Fatal("Кошелёк должен быть разблокирован после загрузки")
+	}
+}
+
+func TestCloseWipesKey(t *testing.T) {
+	w, err := NewWallet("https://sepolia.infura.io/v3/test", filepath.Join(t.TempDir(), "wallet.json"))
+	if err != nil {
+		t.Fatalf("Ошибка создания кошелька: %v", err)
+	}
+
+	if err := w.GenerateNewWallet(); err != nil {
+		t.Fatalf("Ошибка генерации кошелька: %v", err)
+	}
+	keyPair := w.KeyPair
+
+	w.Close()
+
+	if _, err := keyPair.PrivateKeyBytes(); !errors.Is(err, crypto.ErrKeyDestroyed) {
+		t.Fatalf("Ключ должен быть обнулён после Close, получено: %v", err)
+	}
+	if err := w.SaveWallet(); !errors.Is(err, ErrWalletLocked) {
+		t.Fatalf("Сохранение заблокированного кошелька должно завершаться ошибкой, получено: %v", err)
+	}
+}
+
+func TestSignTypedData(t *testing.T) {
+	w, err := NewWallet("https://sepolia.infura.io/v3/test", filepath.Join(t.TempDir(), "wallet.json"))
+	if err != nil {
+		t.Fatalf("Ошибка создания кошелька: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.GenerateNewWallet(); err != nil {
+		t.Fatalf("Ошибка генерации кошелька: %v", err)
+	}
+
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {{Name: "name", Type: "string"}, {Name: "chainId", Type: "uint256"}},
+			"Mail":         {{Name: "contents", Type: "string"}},
+		},
+		PrimaryType: "Mail",
+		Domain:      apitypes.TypedDataDomain{Name: "Test", ChainId: math.NewHexOrDecimal256(11155111)},
+		Message:     apitypes.TypedDataMessage{"contents": "hello"},
+	}
+
+	signature, hash, err := w.SignTypedData(typedData)
+	if err != nil {
+		t.Fatalf("Ошибка подписи типизированных данных: %v", err)
+	}
+	if signature[64] != 27 && signature[64] != 28 {
+		t.Fatalf("v должен быть 27 или 28, получено %d", signature[64])
+	}
+
+	signature[64] -= 27
+	public, err := ethereumCrypto.SigToPub(hash[:], signature)
+	if err != nil || ethereumCrypto.PubkeyToAddress(*public) != w.KeyPair.Address {
+		t.Fatal("Подпись должна восстанавливать адрес кошелька")
+	}
+}
+
+func TestVerifyHashMethods(t *testing.T) {
+	account := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	var w *Wallet
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_getCode", func(params []json.RawMessage) (interface{}, error) {
+		var address common.Address
+		json.Unmarshal(params[0], &address)
+		if address == account {
+			return "0x6080", nil
+		}
+		return "0x", nil
+	})
+	// Смарт-аккаунт принимает ECDSA-подпись ключа кошелька
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		var args struct {
+			Data  hexutil.Bytes `json:"data"`
+			Input hexutil.Bytes `json:"input"`
+		}
+		json.Unmarshal(params[0], &args)
+		data := args.Data
+		if len(data) == 0 {
+			data = args.Input
+		}
+
+		hash := common.BytesToHash(data[4:36])
+		length := new(big.Int).SetBytes(data[68:100]).Int64()
+		if !crypto.VerifyHashSignature(hash, data[100:100+length], w.KeyPair.Address) {
+			return nil, &rpctest.Error{Code: 3, Message: "execution reverted"}
+		}
+		return "0x1626ba7e00000000000000000000000000000000000000000000000000000000", nil
+	})
+
+	w, err := NewWallet(server.URL, filepath.Join(t.TempDir(), "wallet.json"))
+	if err != nil {
+		t.Fatalf("Ошибка создания кошелька: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.GenerateNewWallet(); err != nil {
+		t.Fatalf("Ошибка генерации кошелька: %v", err)
+	}
+
+	hash := ethereumCrypto.Keccak256Hash([]byte("Hello, Ethereum!"))
+	signature, err := w.SignHash(hash)
+	if err != nil {
+		t.Fatalf("Ошибка подписи: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		hash   common.Hash
+		signer common.Address
+		method SignatureMethod
+	}{
+		{"EOA", hash, w.KeyPair.Address, SignatureECDSA},
+		{"контракт", hash, account, SignatureERC1271},
+		{"контракт, чужой хеш", common.Hash{1}, account, SignatureInvalid},
+		{"адрес без кода", hash, common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6"), SignatureInvalid},
+	}
+
+	for _, tt := range tests {
+		method, err := w.VerifyHash(tt.hash, signature, tt.signer)
+		if err != nil {
+			t.Errorf("%s: ошибка проверки: %v", tt.name, err)
+		}
+		if method != tt.method {
+			t.Errorf("%s: метод %q, ожидался %q", tt.name, method, tt.method)
+		}
+	}
+}
+
+func TestSendWithAccessList(t *testing.T) {
+	contract := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	accessList := types.AccessList{{Address: contract, StorageKeys: []common.Hash{{1}, {2}}}}
+
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	var raw hexutil.Bytes
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		return "0x", nil
+	})
+	server.Handle("eth_createAccessList", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{"accessList": accessList, "gasUsed": "0xb798"}, nil
+	})
+	// Со списком доступа вызов дешевле
+	server.Handle("eth_estimateGas", func(params []json.RawMessage) (interface{}, error) {
+		var args struct {
+			AccessList types.AccessList `json:"accessList"`
+		}
+		json.Unmarshal(params[0], &args)
+		if len(args.AccessList) > 0 {
+			return "0xb798", nil
+		}
+		return "0xc350", nil
+	})
+	server.Handle("eth_getTransactionCount", func(params []json.RawMessage) (interface{}, error) {
+		return "0x0", nil
+	})
+	server.Handle("eth_gasPrice", func(params []json.RawMessage) (interface{}, error) {
+		return "0x3b9aca00", nil
+	})
+	server.Handle("eth_chainId", func(params []json.RawMessage) (interface{}, error) {
+		return "0xaa36a7", nil
+	})
+	server.Handle("eth_sendRawTransaction", func(params []json.RawMessage) (interface{}, error) {
+		json.Unmarshal(params[0], &raw)
+		return common.Hash{}, nil
+	})
+
+	w, err := NewWallet(server.URL, filepath.Join(t.TempDir(), "wallet.json"))
+	if err != nil {
+		t.Fatalf("Ошибка создания кошелька: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.GenerateNewWallet(); err != nil {
+		t.Fatalf("Ошибка генерации кошелька: %v", err)
+	}
+
+	req := TxRequest{To: contract, Data: []byte{0xa9, 0x05, 0x9c, 0xbb}}
+	estimate, err := w.EstimateAccessList(req)
+	if err != nil {
+		t.Fatalf("Ошибка оценки списка доступа: %v", err)
+	}
+	if estimate.GasWithout != 50000 || estimate.GasWith != 47000 || !estimate.Cheaper() {
+		t.Fatalf("Неверная оценка: %+v", estimate)
+	}
+
+	req.AccessList = estimate.AccessList
+	if _, err := w.Send(req); err != nil {
+		t.Fatalf("Ошибка отправки: %v", err)
+	}
+
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		t.Fatalf("Ошибка декодирования транзакции: %v", err)
+	}
+	if tx.Type() != types.AccessListTxType || tx.Gas() != 47000 || len(tx.AccessList()) != 1 {
+		t.Errorf("Ожидалась транзакция EIP-2930 с газом 47000, получен тип %d, газ %d", tx.Type(), tx.Gas())
+	}
+	if tx.ChainId().Int64() != 11155111 {
+		t.Errorf("Неверный chain ID: %s", tx.ChainId())
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), &tx)
+	if err != nil || sender != w.KeyPair.Address {
+		t.Errorf("Транзакция должна быть подписана ключом кошелька: %s, %v", sender.Hex(), err)
+	}
+}
+
+func TestSignRequestGasCeiling(t *testing.T) {
+	server := rpctest.NewServer()
+	defer server.Close()
+
+	server.Handle("eth_feeHistory", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{
+			"oldestBlock":   "0x100",
+			"baseFeePerGas": []string{"0x3b9aca00", "0x3b9aca00"},
+			"gasUsedRatio":  []float64{0.5},
+			"reward":        [][]string{{"0x3b9aca00", "0x77359400", "0xb2d05e00"}},
+		}, nil
+	})
+	server.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		return "0x", nil
+	})
+	server.Handle("eth_estimateGas", func(params []json.RawMessage) (interface{}, error) {
+		return "0x5208", nil
+	})
+	server.Handle("eth_getTransactionCount", func(params []json.RawMessage) (interface{}, error) {
+		return "0x0", nil
+	})
+	server.Handle("net_version", func(params []json.RawMessage) (interface{}, error) {
+		return "11155111", nil
+	})
+
+	w, err := NewWallet(server.URL, filepath.Join(t.TempDir(), "wallet.json"))
+	if err != nil {
+		t.Fatalf("Ошибка создания кошелька: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.GenerateNewWallet(); err != nil {
+		t.Fatalf("Ошибка генерации кошелька: %v", err)
+	}
+
+	w.FeeOracle = gasoracle.New(w.Blockchain)
+	w.FeeOracle.Ceiling = big.NewInt(4e9)
+	to := common.HexToAddress("0x01")
+
+	// standard: 1 gwei * 9/8 + 2 gwei
+	tx, err := w.SignRequest(TxRequest{To: to, Value: big.NewInt(1)})
+	if err != nil {
+		t.Fatalf("Ошибка подписи ниже потолка: %v", err)
+	}
+	if tx.GasPrice().Int64() != 3125000000 {
+		t.Errorf("Цена газа %s, ожидалось 3125000000", tx.GasPrice())
+	}
+
+	var ceiling *gasoracle.CeilingError
+	w.FeeTier = gasoracle.Fast
+	if _, err := w.SignRequest(TxRequest{To: to, Value: big.NewInt(1)}); !errors.As(err, &ceiling) {
+		t.Errorf("Уровень fast выше потолка должен отклоняться, получено %v", err)
+	}
+
+	// Явно заданная цена тоже проверяется
+	w.FeeTier = gasoracle.Slow
+	if _, err := w.SignRequest(TxRequest{To: to, Value: big.NewInt(1), GasPrice: big.NewInt(5e9)}); !errors.As(err, &ceiling) {
+		t.Errorf("Явная цена выше потолка должна отклоняться, получено %v", err)
+	}
+}
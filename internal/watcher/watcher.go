@@ -0,0 +1,308 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"crypto-wallet/internal/blockchain"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type Payment struct {
+	BlockNumber uint64
+	BlockHash   common.Hash
+	TxHash      common.Hash
+	From        common.Address
+	To          common.Address
+	Token       *common.Address
+	Value       *big.Int
+}
+
+const maxReorgDepth = 64
+
+type Checkpoint struct {
+	Block  uint64        `json:"block"`
+	Hash   common.Hash   `json:"hash"`
+	Recent []common.Hash `json:"recent,omitempty"`
+}
+
+type Watcher struct {
+	Client         *blockchain.Client
+	Addresses      []common.Address
+	CheckpointPath string
+	StartBlock     *uint64
+	PollInterval   time.Duration
+
+	signer     types.Signer
+	checkpoint *Checkpoint
+}
+
+func (w *Watcher) Run(ctx context.Context, handle func(Payment)) error {
+	next, err := w.startBlock()
+	if err != nil {
+		return err
+	}
+
+	if w.Client.IsWebSocket() {
+		return w.runSubscription(ctx, next, handle)
+	}
+
+	return w.runPolling(ctx, next, handle)
+}
+
+func (w *Watcher) runSubscription(ctx context.Context, next uint64, handle func(Payment)) error {
+	heads := make(chan *types.Header, 16)
+	sub, err := w.Client.SubscribeNewHeads(ctx, heads)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	head, err := w.Client.GetBlockNumber()
+	if err != nil {
+		return err
+	}
+
+	if next, err = w.ProcessRange(next, head, handle); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-sub.Err():
+			return fmt.Errorf("subscription dropped: %w", err)
+		case header := <-heads:
+			if next, err = w.ProcessRange(next, header.Number.Uint64(), handle); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *Watcher) runPolling(ctx context.Context, next uint64, handle func(Payment)) error {
+	interval := w.PollInterval
+	if interval <= 0 {
+		interval = blockchain.DefaultPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		head, err := w.Client.GetBlockNumber()
+		if err != nil {
+			return err
+		}
+
+		if next, err = w.ProcessRange(next, head, handle); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watcher) ProcessRange(from uint64, to uint64, handle func(Payment)) (uint64, error) {
+	next := from
+
+	for ; next <= to; next++ {
+		block, err := w.Client.GetBlock(new(big.Int).SetUint64(next))
+		if err != nil {
+			return next, err
+		}
+
+		payments, err := w.scanBlock(block)
+		if err != nil {
+			return next, err
+		}
+
+		for _, payment := range payments {
+			handle(payment)
+		}
+
+		checkpoint := Checkpoint{Block: next, Hash: block.Hash()}
+		if previous := w.checkpoint; previous != nil && previous.Block+1 == next {
+			checkpoint.Recent = append([]common.Hash{previous.Hash}, previous.Recent...)
+			if len(checkpoint.Recent) > maxReorgDepth-1 {
+				checkpoint.Recent = checkpoint.Recent[:maxReorgDepth-1]
+			}
+		}
+
+		if err := w.saveCheckpoint(checkpoint); err != nil {
+			return next, err
+		}
+		w.checkpoint = &checkpoint
+	}
+
+	return next, nil
+}
+
+func (w *Watcher) scanBlock(block *types.Block) ([]Payment, error) {
+	if w.signer == nil {
+		chainID, err := w.Client.GetChainID()
+		if err != nil {
+			return nil, err
+		}
+		w.signer = types.LatestSignerForChainID(chainID)
+	}
+
+	watched := make(map[common.Address]bool, len(w.Addresses))
+	for _, address := range w.Addresses {
+		watched[address] = true
+	}
+
+	var payments []Payment
+
+	for _, tx := range block.Transactions() {
+		if tx.To() == nil || !watched[*tx.To()] || tx.Value().Sign() == 0 {
+			continue
+		}
+
+		from, err := types.Sender(w.signer, tx)
+		if err != nil {
+			return nil, fmt.Errorf("error recovering sender of %s: %w", tx.Hash().Hex(), err)
+		}
+
+		payments = append(payments, Payment{
+			BlockNumber: block.NumberU64(),
+			BlockHash:   block.Hash(),
+			TxHash:      tx.Hash(),
+			From:        from,
+			To:          *tx.To(),
+			Value:       tx.Value(),
+		})
+	}
+
+	blockHash := block.Hash()
+	logs, err := w.Client.FilterLogs(ethereum.FilterQuery{
+		BlockHash: &blockHash,
+		Topics: [][]common.Hash{
+			{blockchain.TransferEventTopic},
+			nil,
+			blockchain.AddressTopics(w.Addresses),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, log := range logs {
+		transfer, ok := blockchain.DecodeTokenTransfer(log)
+		if !ok || !watched[transfer.To] || log.Removed {
+			continue
+		}
+
+		token := transfer.Token
+		payments = append(payments, Payment{
+			BlockNumber: block.NumberU64(),
+			BlockHash:   block.Hash(),
+			TxHash:      log.TxHash,
+			From:        transfer.From,
+			To:          transfer.To,
+			Token:       &token,
+			Value:       transfer.Value,
+		})
+	}
+
+	return payments, nil
+}
+
+func (w *Watcher) startBlock() (uint64, error) {
+	checkpoint, err := LoadCheckpoint(w.CheckpointPath)
+	if err != nil {
+		return 0, err
+	}
+
+	if checkpoint != nil {
+		return w.resumeBlock(checkpoint)
+	}
+
+	if w.StartBlock != nil {
+		return *w.StartBlock, nil
+	}
+
+	return w.Client.GetBlockNumber()
+}
+
+func (w *Watcher) resumeBlock(checkpoint *Checkpoint) (uint64, error) {
+	hashes := append([]common.Hash{checkpoint.Hash}, checkpoint.Recent...)
+
+	for depth, hash := range hashes {
+		if uint64(depth) > checkpoint.Block {
+			break
+		}
+		number := checkpoint.Block - uint64(depth)
+
+		header, err := w.Client.GetHeader(new(big.Int).SetUint64(number))
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if header.Hash() == hash {
+			w.checkpoint = &Checkpoint{Block: number, Hash: hash, Recent: hashes[depth+1:]}
+			return number + 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("checkpoint block %d is no longer on the chain and no earlier saved block matches", checkpoint.Block)
+}
+
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading checkpoint: %w", err)
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("error parsing checkpoint: %w", err)
+	}
+
+	return &checkpoint, nil
+}
+
+func (w *Watcher) saveCheckpoint(checkpoint Checkpoint) error {
+	if w.CheckpointPath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing checkpoint: %w", err)
+	}
+
+	tmp := w.CheckpointPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("error writing checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmp, w.CheckpointPath); err != nil {
+		return fmt.Errorf("error writing checkpoint: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"crypto-wallet/internal/blockchain"
+	"crypto-wallet/internal/rpctest"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	chainID = big.NewInt(11155111)
+	ours    = common.HexToAddress("0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6")
+	other   = common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	token   = common.HexToAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359")
+)
+
+type testChain struct {
+	mu     sync.Mutex
+	head   uint64
+	blocks map[uint64]map[string]interface{}
+	logs   map[common.Hash][]types.Log
+}
+
+func signedTransfer(t *testing.T, nonce uint64, to common.Address, value int64) *types.Transaction {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа: %v", err)
+	}
+
+	tx := types.NewTransaction(nonce, to, big.NewInt(value), 21000, big.NewInt(1e9), nil)
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+	if err != nil {
+		t.Fatalf("Ошибка подписи: %v", err)
+	}
+	return signed
+}
+
+func (c *testChain) addBlock(t *testing.T, number uint64, txs []*types.Transaction, logs []types.Log) common.Hash {
+	header := &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		Difficulty: big.NewInt(0),
+		GasLimit:   30000000,
+		Time:       1700000000 + number*12,
+	}
+
+	block, err := rpctest.Block(header, txs)
+	if err != nil {
+		t.Fatalf("Ошибка создания блока: %v", err)
+	}
+
+	hash := block["hash"].(common.Hash)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.blocks[number] = block
+	for i := range logs {
+		logs[i].BlockHash = hash
+		logs[i].BlockNumber = number
+	}
+	c.logs[hash] = logs
+	if number > c.head {
+		c.head = number
+	}
+
+	return hash
+}
+
+func newTestWatcher(t *testing.T, chain *testChain) *Watcher {
+	server := rpctest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.Handle("eth_chainId", func(params []json.RawMessage) (interface{}, error) {
+		return hexutil.Big(*chainID), nil
+	})
+	server.Handle("eth_blockNumber", func(params []json.RawMessage) (interface{}, error) {
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		return hexutil.Uint64(chain.head), nil
+	})
+	server.Handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		var number hexutil.Uint64
+		json.Unmarshal(params[0], &number)
+
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		return chain.blocks[uint64(number)], nil
+	})
+	server.Handle("eth_getLogs", func(params []json.RawMessage) (interface{}, error) {
+		var query struct {
+			BlockHash common.Hash `json:"blockHash"`
+		}
+		json.Unmarshal(params[0], &query)
+
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		if logs := chain.logs[query.BlockHash]; logs != nil {
+			return logs, nil
+		}
+		return []types.Log{}, nil
+	})
+
+	client, err := blockchain.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Ошибка создания клиента: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	return &Watcher{
+		Client:         client,
+		Addresses:      []common.Address{ours},
+		CheckpointPath: filepath.Join(t.TempDir(), "watch.json"),
+		PollInterval:   time.Millisecond,
+	}
+}
+
+func tokenTransferLog(from common.Address, to common.Address, value int64) types.Log {
+	return types.Log{
+		Address: token,
+		Topics: []common.Hash{
+			blockchain.TransferEventTopic,
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data:   common.LeftPadBytes(big.NewInt(value).Bytes(), 32),
+		TxHash: common.HexToHash("0xabc"),
+	}
+}
+
+func TestProcessRangeFindsIncomingPayments(t *testing.T) {
+	chain := &testChain{blocks: map[uint64]map[string]interface{}{}, logs: map[common.Hash][]types.Log{}}
+
+	incoming := signedTransfer(t, 0, ours, 500)
+	chain.addBlock(t, 1, []*types.Transaction{incoming, signedTransfer(t, 0, other, 700)}, nil)
+	chain.addBlock(t, 2, nil, []types.Log{tokenTransferLog(other, ours, 42)})
+
+	w := newTestWatcher(t, chain)
+
+	var payments []Payment
+	next, err := w.ProcessRange(1, 2, func(payment Payment) {
+		payments = append(payments, payment)
+	})
+	if err != nil {
+		t.Fatalf("Ошибка обработки блоков: %v", err)
+	}
+
+	if next != 3 {
+		t.Fatalf("Следующий блок должен быть 3, получено %d", next)
+	}
+
+	if len(payments) != 2 {
+		t.Fatalf("Ожидалось 2 платежа, получено %d", len(payments))
+	}
+
+	native := payments[0]
+	if native.Token != nil || native.TxHash != incoming.Hash() || native.Value.Int64() != 500 || native.BlockNumber != 1 {
+		t.Fatalf("Неверный платеж в ETH: %+v", native)
+	}
+	if native.From == (common.Address{}) {
+		t.Fatal("Отправитель должен быть восстановлен из подписи")
+	}
+
+	tokenPayment := payments[1]
+	if tokenPayment.Token == nil || *tokenPayment.Token != token || tokenPayment.From != other || tokenPayment.Value.Int64() != 42 {
+		t.Fatalf("Неверный платеж в токенах: %+v", tokenPayment)
+	}
+
+	checkpoint, err := LoadCheckpoint(w.CheckpointPath)
+	if err != nil || checkpoint == nil || checkpoint.Block != 2 {
+		t.Fatalf("Контрольная точка должна указывать на блок 2: %+v, %v", checkpoint, err)
+	}
+}
+
+func TestRunResumesFromCheckpoint(t *testing.T) {
+	chain := &testChain{blocks: map[uint64]map[string]interface{}{}, logs: map[common.Hash][]types.Log{}}
+	chain.addBlock(t, 5, []*types.Transaction{signedTransfer(t, 0, ours, 1)}, nil)
+	chain.addBlock(t, 6, []*types.Transaction{signedTransfer(t, 0, ours, 2)}, nil)
+
+	w := newTestWatcher(t, chain)
+	start := uint64(5)
+	w.StartBlock = &start
+
+	var mu sync.Mutex
+	var seen []int64
+	handle := func(payment Payment) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, payment.Value.Int64())
+	}
+
+	runUntil := func(count int) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		go func() {
+			for ctx.Err() == nil {
+				mu.Lock()
+				done := len(seen) >= count
+				mu.Unlock()
+				if done {
+					cancel()
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}()
+
+		if err := w.Run(ctx, handle); err != nil {
+			t.Fatalf("Ошибка наблюдения: %v", err)
+		}
+	}
+
+	runUntil(2)
+
+	// После перезапуска уже обработанные блоки не повторяются
+	chain.addBlock(t, 7, []*types.Transaction{signedTransfer(t, 0, ours, 3)}, nil)
+	runUntil(3)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 3 || seen[0] != 1 || seen[1] != 2 || seen[2] != 3 {
+		t.Fatalf("Неверная последовательность платежей: %v", seen)
+	}
+}
+
+func TestRestartRewindsAfterReorg(t *testing.T) {
+	chain := &testChain{blocks: map[uint64]map[string]interface{}{}, logs: map[common.Hash][]types.Log{}}
+	chain.addBlock(t, 5, []*types.Transaction{signedTransfer(t, 0, ours, 1)}, nil)
+	chain.addBlock(t, 6, []*types.Transaction{signedTransfer(t, 0, ours, 2)}, nil)
+	chain.addBlock(t, 7, []*types.Transaction{signedTransfer(t, 0, ours, 3)}, nil)
+
+	w := newTestWatcher(t, chain)
+	if _, err := w.ProcessRange(5, 7, func(Payment) {}); err != nil {
+		t.Fatalf("Ошибка обработки блоков: %v", err)
+	}
+
+	restart := func() (uint64, error) {
+		restarted := &Watcher{Client: w.Client, Addresses: w.Addresses, CheckpointPath: w.CheckpointPath}
+		return restarted.startBlock()
+	}
+
+	next, err := restart()
+	if err != nil || next != 8 {
+		t.Fatalf("Без реорганизации продолжение с блока 8, получено %d, %v", next, err)
+	}
+
+	// Блоки 6 и 7 заменены другими, пока наблюдатель был остановлен
+	chain.addBlock(t, 6, []*types.Transaction{signedTransfer(t, 0, ours, 20)}, nil)
+	chain.addBlock(t, 7, []*types.Transaction{signedTransfer(t, 0, ours, 30)}, nil)
+
+	next, err = restart()
+	if err != nil || next != 6 {
+		t.Fatalf("После реорганизации продолжение с блока 6, получено %d, %v", next, err)
+	}
+
+	chain.addBlock(t, 5, []*types.Transaction{signedTransfer(t, 0, ours, 10)}, nil)
+	if _, err := restart(); err == nil {
+		t.Fatal("Реорганизация глубже сохранённых блоков должна возвращать ошибку")
+	}
+}